@@ -0,0 +1,152 @@
+package gyr
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Route metadata key set by [Route.NoAccessLog].
+const metaNoAccessLog = "gyr.noaccesslog"
+
+// Optional fields of the access log, combined with |. The method and path are always logged.
+type AccessLogField int
+
+const (
+	LogStatus AccessLogField = 1 << iota
+	// Length of the response body in bytes.
+	LogLength
+	// Time from the request coming in until the response has been sent.
+	LogLatency
+	// Address of the connection, not taken from X-Forwarded-For since any client can set it.
+	LogRemoteIP
+	LogRequestID
+	// The query string with sensitive parameters redacted, see [Router.Redaction].
+	LogQuery
+	LogUserAgent
+)
+
+type AccessLogSettings struct {
+	// Logger to write the access log to. Defaults to the logger of the router.
+	Logger *slog.Logger
+	Fields AccessLogField
+	// Also log each request as it comes in, before it is handled.
+	Incoming bool
+}
+
+func DefaultAccessLogSettings() AccessLogSettings {
+	return AccessLogSettings{
+		Fields:   LogStatus | LogLength | LogRequestID | LogQuery,
+		Incoming: true,
+	}
+}
+
+// Write the access log with logger, such as one with a custom [slog.Handler].
+func AccessLogger(logger *slog.Logger) func(*AccessLogSettings) {
+	return func(as *AccessLogSettings) {
+		as.Logger = logger
+	}
+}
+
+// Write the access log with a logger of its own, configured like [ConfigureLogging].
+//
+//	router.AccessLog(gyr.AccessLogTo(gyr.LogJSON(), gyr.LogOutput(file)))
+func AccessLogTo(settings ...SettingsFunc[LogSettings]) func(*AccessLogSettings) {
+	logSettings := DefaultLogSettings()
+	for _, setting := range settings {
+		setting(&logSettings)
+	}
+	logger := newLogger(logSettings)
+	return func(as *AccessLogSettings) {
+		as.Logger = logger
+	}
+}
+
+func AccessLogFields(fields AccessLogField) func(*AccessLogSettings) {
+	return func(as *AccessLogSettings) {
+		as.Fields = fields
+	}
+}
+
+func AccessLogIncoming(incoming bool) func(*AccessLogSettings) {
+	return func(as *AccessLogSettings) {
+		as.Incoming = incoming
+	}
+}
+
+// Configure the access log of the router. [DefaultAccessLogSettings] are used when this isn't called.
+//
+//	router.AccessLog(gyr.AccessLogFields(gyr.LogStatus|gyr.LogLatency|gyr.LogRemoteIP), gyr.AccessLogIncoming(false))
+func (router *Router) AccessLog(settings ...SettingsFunc[AccessLogSettings]) {
+	accessLogSettings := DefaultAccessLogSettings()
+	for _, setting := range settings {
+		setting(&accessLogSettings)
+	}
+	router.accessLog = accessLogSettings
+}
+
+// Leave requests to the route out of the access log, such as health checks polled by a load balancer.
+func (route *Route) NoAccessLog() *Route {
+	return route.Set(metaNoAccessLog, true)
+}
+
+func (router *Router) accessLogger(route *Route) *slog.Logger {
+	if route != nil {
+		if skip, _ := route.meta[metaNoAccessLog].(bool); skip {
+			return nil
+		}
+	}
+	if router.accessLog.Logger != nil {
+		return router.accessLog.Logger
+	}
+	return router.logger
+}
+
+func (router *Router) logIncoming(logger *slog.Logger, req *http.Request) {
+	if !router.accessLog.Incoming {
+		return
+	}
+	logger.Info("Incoming request", router.accessLogAttrs(req, nil, time.Time{})...)
+}
+
+func (router *Router) logSent(logger *slog.Logger, req *http.Request, response *Response, start time.Time) {
+	logger.Info("Response sent", router.accessLogAttrs(req, response, start)...)
+}
+
+// The attributes of an access log entry. response is nil for the entry of an incoming request.
+func (router *Router) accessLogAttrs(req *http.Request, response *Response, start time.Time) []any {
+	fields := router.accessLog.Fields
+	attrs := []any{"method", req.Method, "path", req.URL.Path}
+	if fields&LogRequestID != 0 {
+		if id := RequestID(req.Context()); id != "" {
+			attrs = append(attrs, "request_id", id)
+		}
+	}
+	if fields&LogQuery != 0 && req.URL.RawQuery != "" {
+		attrs = append(attrs, "query", router.redactor.query(req.URL.RawQuery))
+	}
+	if fields&LogRemoteIP != 0 {
+		ip, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			ip = req.RemoteAddr
+		}
+		attrs = append(attrs, "remote_ip", ip)
+	}
+	if fields&LogUserAgent != 0 {
+		attrs = append(attrs, "user_agent", req.UserAgent())
+	}
+	if response == nil {
+		return attrs
+	}
+	if fields&LogStatus != 0 {
+		attrs = append(attrs, "status", response.status)
+	}
+	if fields&LogLength != 0 {
+		attrs = append(attrs, "length", len(response.toWrite))
+	}
+	if fields&LogLatency != 0 {
+		attrs = append(attrs, "latency", CurrentClock().Since(start))
+	}
+	return attrs
+}
@@ -0,0 +1,55 @@
+package gyr_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestAccessLog(t *testing.T) {
+	var output bytes.Buffer
+	router := defaultTestRouter()
+	router.AccessLog(
+		gyr.AccessLogTo(gyr.LogJSON(), gyr.LogOutput(&output)),
+		gyr.AccessLogFields(gyr.LogStatus|gyr.LogLatency|gyr.LogRemoteIP|gyr.LogRequestID),
+		gyr.AccessLogIncoming(false),
+	)
+	router.Path("/health").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().NoContent()
+	}).NoAccessLog()
+	router.Path("/users").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text("users")
+	})
+
+	gyrtest.Get("/health").Send(router)
+	gyrtest.Get("/users").Query("token", "secret").Header(gyr.RequestIDHeader, "abc-123").Send(router)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 1 {
+		t.Logf("Expected one access log entry. Received %q", output.String())
+		t.FailNow()
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Logf("Expected a JSON entry. Received %q", lines[0])
+		t.FailNow()
+	}
+	if entry["msg"] != "Response sent" || entry["path"] != "/users" || entry["status"] != float64(http.StatusOK) ||
+		entry["request_id"] != "abc-123" || entry["remote_ip"] != "192.0.2.1" {
+		t.Logf("Unexpected entry %v", entry)
+		t.FailNow()
+	}
+	if _, hasLatency := entry["latency"]; !hasLatency {
+		t.Logf("Expected latency in %v", entry)
+		t.FailNow()
+	}
+	if _, hasQuery := entry["query"]; hasQuery {
+		t.Logf("Expected no query when it isn't one of the fields. Received %v", entry)
+		t.FailNow()
+	}
+}
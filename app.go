@@ -0,0 +1,211 @@
+package gyr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+var ErrServiceNotFound = errors.New("no provider registered for service")
+var ErrServiceCycle = errors.New("service depends on itself")
+
+// Container for the services of an application. Services are registered with [Provide], constructed once when
+// first resolved and started and stopped in dependency order by [App.Start] and [App.Stop].
+//
+// A service takes part in the lifecycle if it has one of the methods Start(), Start(context.Context) error,
+// Stop(), Stop(context.Context) error or Shutdown(context.Context) error, so a [*JobQueue] or [*EventBus] can be
+// provided as is.
+type App struct {
+	mu        sync.Mutex
+	providers map[reflect.Type]*provider
+	order     []reflect.Type
+	// Services in the order they finished constructing, which puts dependencies before their dependents.
+	constructed []any
+	started     []any
+}
+
+type provider struct {
+	construct    func(*App) (any, error)
+	value        any
+	err          error
+	done         bool
+	constructing bool
+}
+
+func NewApp() *App {
+	return &App{
+		providers:   make(map[reflect.Type]*provider),
+		order:       make([]reflect.Type, 0),
+		constructed: make([]any, 0),
+		started:     make([]any, 0),
+	}
+}
+
+// Register constructor as the way to create T. constructor can resolve the services it depends on from app.
+// Registering the same type again replaces the previous provider.
+func Provide[T any](app *App, constructor func(app *App) (T, error)) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	serviceType := reflect.TypeFor[T]()
+	if _, exists := app.providers[serviceType]; !exists {
+		app.order = append(app.order, serviceType)
+	}
+	app.providers[serviceType] = &provider{construct: func(app *App) (any, error) {
+		return constructor(app)
+	}}
+}
+
+// Register an already constructed value as T.
+func ProvideValue[T any](app *App, value T) {
+	Provide(app, func(*App) (T, error) {
+		return value, nil
+	})
+}
+
+// Get the T provided to app, constructing it and its dependencies if needed. Safe for concurrent use once
+// [App.Start] has returned.
+func Resolve[T any](app *App) (T, error) {
+	var service T
+	value, err := app.resolve(reflect.TypeFor[T]())
+	if err != nil {
+		return service, err
+	}
+	service, isService := value.(T)
+	if !isService {
+		return service, fmt.Errorf("provider of %s returned %T", reflect.TypeFor[T](), value)
+	}
+	return service, nil
+}
+
+// Like [Resolve] but panics if T can't be resolved. Meant for constructors and startup code.
+func MustResolve[T any](app *App) T {
+	service, err := Resolve[T](app)
+	if err != nil {
+		panic(err)
+	}
+	return service
+}
+
+func (app *App) resolve(serviceType reflect.Type) (any, error) {
+	app.mu.Lock()
+	p, exists := app.providers[serviceType]
+	if !exists {
+		app.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceType)
+	}
+	if p.done {
+		app.mu.Unlock()
+		return p.value, p.err
+	}
+	if p.constructing {
+		app.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrServiceCycle, serviceType)
+	}
+	p.constructing = true
+	app.mu.Unlock()
+	// Cleared even if construct panics, so that later calls don't report a cycle.
+	defer func() {
+		app.mu.Lock()
+		p.constructing = false
+		app.mu.Unlock()
+	}()
+
+	value, err := p.construct(app)
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	p.done = true
+	p.value, p.err = value, err
+	if err != nil {
+		p.err = fmt.Errorf("constructing %s: %w", serviceType, err)
+	} else {
+		app.constructed = append(app.constructed, value)
+	}
+	return p.value, p.err
+}
+
+// Construct every provided service and start those with a Start method, dependencies first. If a service fails
+// to start, the services already started are stopped again.
+func (app *App) Start(ctx context.Context) error {
+	app.mu.Lock()
+	order := slices.Clone(app.order)
+	app.mu.Unlock()
+	for _, serviceType := range order {
+		if _, err := app.resolve(serviceType); err != nil {
+			return err
+		}
+	}
+
+	app.mu.Lock()
+	constructed := slices.Clone(app.constructed)
+	app.mu.Unlock()
+	for _, service := range constructed {
+		if err := startService(ctx, service); err != nil {
+			return errors.Join(fmt.Errorf("starting %T: %w", service, err), app.Stop(ctx))
+		}
+		app.mu.Lock()
+		app.started = append(app.started, service)
+		app.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop the started services in the reverse order they were started. Every service is stopped even if an
+// earlier one fails, and the errors are joined.
+func (app *App) Stop(ctx context.Context) error {
+	app.mu.Lock()
+	started := app.started
+	app.started = make([]any, 0)
+	app.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		service := started[i]
+		if err := stopService(ctx, service); err != nil {
+			errs = append(errs, fmt.Errorf("stopping %T: %w", service, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func startService(ctx context.Context, service any) error {
+	switch s := service.(type) {
+	case interface{ Start(context.Context) error }:
+		return s.Start(ctx)
+	case interface{ Start() }:
+		s.Start()
+	}
+	return nil
+}
+
+func stopService(ctx context.Context, service any) error {
+	switch s := service.(type) {
+	case interface{ Shutdown(context.Context) error }:
+		return s.Shutdown(ctx)
+	case interface{ Stop(context.Context) error }:
+		return s.Stop(ctx)
+	case interface{ Stop() }:
+		s.Stop()
+	}
+	return nil
+}
+
+// Middleware making app available to handlers through [Service].
+func (app *App) Middleware() Handler {
+	return func(ctx *Context) *Response {
+		ctx.app = app
+		return nil
+	}
+}
+
+// Resolve T from the [App] whose middleware ran for the request.
+func Service[T any](ctx *Context) (T, error) {
+	if ctx.app == nil {
+		var service T
+		return service, errors.New("no app attached to context, register App.Middleware() on the router")
+	}
+	return Resolve[T](ctx.app)
+}
@@ -0,0 +1,152 @@
+package gyr_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+type lifecycleLog struct {
+	events []string
+}
+
+type testDatabase struct {
+	log *lifecycleLog
+}
+
+func (db *testDatabase) Start(ctx context.Context) error {
+	db.log.events = append(db.log.events, "start db")
+	return nil
+}
+
+func (db *testDatabase) Stop() {
+	db.log.events = append(db.log.events, "stop db")
+}
+
+type testUserService struct {
+	db  *testDatabase
+	log *lifecycleLog
+}
+
+func (service *testUserService) Start() {
+	service.log.events = append(service.log.events, "start users")
+}
+
+func (service *testUserService) Shutdown(ctx context.Context) error {
+	service.log.events = append(service.log.events, "stop users")
+	return nil
+}
+
+func newTestApp() (*gyr.App, *lifecycleLog) {
+	log := &lifecycleLog{}
+	app := gyr.NewApp()
+	gyr.ProvideValue(app, log)
+	// Registered before its dependency to check that start order follows dependencies.
+	gyr.Provide(app, func(app *gyr.App) (*testUserService, error) {
+		return &testUserService{db: gyr.MustResolve[*testDatabase](app), log: gyr.MustResolve[*lifecycleLog](app)}, nil
+	})
+	gyr.Provide(app, func(app *gyr.App) (*testDatabase, error) {
+		return &testDatabase{log: gyr.MustResolve[*lifecycleLog](app)}, nil
+	})
+	return app, log
+}
+
+func TestAppLifecycle(t *testing.T) {
+	app, log := newTestApp()
+	if err := app.Start(context.Background()); err != nil {
+		t.Logf("Start failed: %v\n", err)
+		t.FailNow()
+	}
+	if err := app.Stop(context.Background()); err != nil {
+		t.Logf("Stop failed: %v\n", err)
+		t.FailNow()
+	}
+
+	expected := []string{"start db", "start users", "stop users", "stop db"}
+	if len(log.events) != len(expected) {
+		t.Logf("Expected %v. Received %v\n", expected, log.events)
+		t.FailNow()
+	}
+	for i := range expected {
+		if log.events[i] != expected[i] {
+			t.Logf("Expected %v. Received %v\n", expected, log.events)
+			t.FailNow()
+		}
+	}
+}
+
+func TestAppResolveErrors(t *testing.T) {
+	app := gyr.NewApp()
+	if _, err := gyr.Resolve[*testDatabase](app); !errors.Is(err, gyr.ErrServiceNotFound) {
+		t.Logf("Expected ErrServiceNotFound. Received %v\n", err)
+		t.FailNow()
+	}
+
+	gyr.Provide(app, func(app *gyr.App) (*testDatabase, error) {
+		_, err := gyr.Resolve[*testDatabase](app)
+		return nil, err
+	})
+	if _, err := gyr.Resolve[*testDatabase](app); !errors.Is(err, gyr.ErrServiceCycle) {
+		t.Logf("Expected ErrServiceCycle. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+type testMailer interface {
+	Send(to string) error
+}
+
+func TestAppResolveInvalidProviders(t *testing.T) {
+	app := gyr.NewApp()
+	gyr.Provide(app, func(app *gyr.App) (testMailer, error) {
+		return nil, nil
+	})
+	if _, err := gyr.Resolve[testMailer](app); err == nil {
+		t.Log("Expected an error for a nil service")
+		t.FailNow()
+	}
+
+	attempts := 0
+	gyr.Provide(app, func(app *gyr.App) (*testDatabase, error) {
+		if attempts++; attempts == 1 {
+			panic("connection refused")
+		}
+		return &testDatabase{}, nil
+	})
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != "connection refused" {
+				t.Logf("Expected the constructor to panic. Received %v\n", recovered)
+				t.FailNow()
+			}
+		}()
+		gyr.Resolve[*testDatabase](app)
+	}()
+	if _, err := gyr.Resolve[*testDatabase](app); err != nil {
+		t.Logf("Expected the service to be constructed again after the panic. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestServiceFromContext(t *testing.T) {
+	app, _ := newTestApp()
+	router := gyr.DefaultRouter()
+	router.Middleware(app.Middleware())
+	router.Path("/users").Get(func(ctx *gyr.Context) *gyr.Response {
+		users, err := gyr.Service[*testUserService](ctx)
+		if err != nil || users.db == nil {
+			return ctx.Response().InternalError()
+		}
+		return ctx.Response().Text("ok")
+	})
+
+	request, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	response := sendRequest(router, request)
+	if response.Body.String() != "ok" {
+		t.Logf("Expected ok. Received %d %s\n", response.Code, response.Body.String())
+		t.FailNow()
+	}
+}
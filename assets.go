@@ -0,0 +1,154 @@
+package gyr
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// Returned when an asset hasn't been registered by [Router.StaticDir].
+var ErrAssetNotFound = errors.New("asset not found")
+
+type AssetSettings struct {
+	// Assets up to this size in bytes are inlined by the inline template function.
+	InlineMaxSize int64
+}
+
+func DefaultAssetSettings() AssetSettings {
+	return AssetSettings{InlineMaxSize: 4 << 10}
+}
+
+func AssetInlineMaxSize(size int64) func(*AssetSettings) {
+	return func(as *AssetSettings) {
+		as.InlineMaxSize = size
+	}
+}
+
+type asset struct {
+	url  string
+	file string
+}
+
+// Register a fingerprinted route for a file added by StaticDir. Fingerprinted URLs change with the content of
+// the file and can therefore be cached forever.
+func (router *Router) addAsset(group *RouteGroup, directory string, name string, file string) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		router.logger.Error("failed fingerprinting static file", "err", err, "file", file)
+		return
+	}
+	sum := sha256.Sum256(content)
+	extension := path.Ext(name)
+	fingerprinted := fmt.Sprintf("%s.%s%s", strings.TrimSuffix(name, extension), hex.EncodeToString(sum[:4]), extension)
+
+	serve := staticFileHandler(router, directory, file)
+	group.Path(fingerprinted).Get(func(ctx *Context) *Response {
+		return serve(ctx).Header("Cache-Control", "public, max-age=31536000, immutable")
+	})
+	if router.assets == nil {
+		router.assets = make(map[string]asset)
+	}
+	router.assets[name] = asset{url: group.Prefix + "/" + fingerprinted, file: file}
+}
+
+// Fingerprinted URL of a file added by [Router.StaticDir]. name is relative to the static directory.
+func (router *Router) AssetURL(name string) (string, error) {
+	asset, found := router.assets[strings.TrimPrefix(name, "/")]
+	if !found {
+		return "", fmt.Errorf("%w: %s", ErrAssetNotFound, name)
+	}
+	return asset.url, nil
+}
+
+// Functions for html/template resolving files added by [Router.StaticDir]:
+//
+//	{{ asset "app.css" }}   fingerprinted URL
+//	{{ preload "app.js" }}  fingerprinted URL, also sent in a preload Link header
+//	{{ inline "app.css" }}  the content in a style or script tag, or a data URL for other files.
+//	                        Assets larger than InlineMaxSize are linked instead.
+func (router *Router) AssetFuncs(ctx *Context, settings ...SettingsFunc[AssetSettings]) template.FuncMap {
+	assetSettings := DefaultAssetSettings()
+	for _, setting := range settings {
+		setting(&assetSettings)
+	}
+	return template.FuncMap{
+		"asset": router.AssetURL,
+		"preload": func(name string) (string, error) {
+			url, err := router.AssetURL(name)
+			if err != nil {
+				return "", err
+			}
+			link := fmt.Sprintf("<%s>; rel=preload", url)
+			if as := preloadDestination(name); as != "" {
+				link += "; as=" + as
+			}
+			ctx.writer.Header().Add("Link", link)
+			return url, nil
+		},
+		"inline": func(name string) (any, error) {
+			return router.inlineAsset(name, assetSettings.InlineMaxSize)
+		},
+	}
+}
+
+// Style and script tags are returned as template.HTML and other assets as template.URL.
+func (router *Router) inlineAsset(name string, maxSize int64) (any, error) {
+	asset, found := router.assets[strings.TrimPrefix(name, "/")]
+	if !found {
+		return "", fmt.Errorf("%w: %s", ErrAssetNotFound, name)
+	}
+	var content []byte
+	if info, err := os.Stat(asset.file); err != nil {
+		return "", err
+	} else if info.Size() <= maxSize {
+		if content, err = os.ReadFile(asset.file); err != nil {
+			return "", err
+		}
+	}
+
+	url := template.HTMLEscapeString(asset.url)
+	switch path.Ext(name) {
+	case ".css":
+		if content == nil {
+			return template.HTML(`<link rel="stylesheet" href="` + url + `">`), nil
+		}
+		return template.HTML("<style>" + string(content) + "</style>"), nil
+	case ".js":
+		if content == nil {
+			return template.HTML(`<script src="` + url + `"></script>`), nil
+		}
+		return template.HTML("<script>" + string(content) + "</script>"), nil
+	default:
+		if content == nil {
+			return template.URL(asset.url), nil
+		}
+		contentType := mime.TypeByExtension(path.Ext(name))
+		if contentType == "" {
+			contentType = http.DetectContentType(content)
+		}
+		return template.URL("data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(content)), nil
+	}
+}
+
+// Value of the as attribute of a preload link.
+func preloadDestination(name string) string {
+	switch path.Ext(name) {
+	case ".css":
+		return "style"
+	case ".js", ".mjs":
+		return "script"
+	case ".woff", ".woff2", ".ttf", ".otf":
+		return "font"
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".avif":
+		return "image"
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package gyr_test
+
+import (
+	"html/template"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestAssets(t *testing.T) {
+	router := defaultTestRouter()
+	router.StaticDir("test_files/assets")
+	router.Path("/page").Get(func(ctx *gyr.Context) *gyr.Response {
+		page := template.Must(template.New("page").Funcs(router.AssetFuncs(ctx, gyr.AssetInlineMaxSize(10))).Parse(
+			`<link href="{{ preload "app.css" }}">{{ inline "app.js" }}{{ inline "app.css" }}`,
+		))
+		var html strings.Builder
+		if err := page.Execute(&html, nil); err != nil {
+			return ctx.Response().InternalError().Text(err.Error())
+		}
+		return ctx.Response().Html(html.String())
+	})
+
+	url, err := router.AssetURL("app.css")
+	if err != nil || !regexp.MustCompile(`^/test_files/assets/app\.[0-9a-f]{8}\.css$`).MatchString(url) {
+		t.Logf("Expected fingerprinted URL. Received %s, %v\n", url, err)
+		t.FailNow()
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, url, nil)
+	response := sendRequest(router, request)
+	if response.Body.String() != "body{color:red}" || !strings.Contains(response.Header().Get("Cache-Control"), "immutable") {
+		t.Logf("Expected fingerprinted file to be served as immutable. Received %q %v\n", response.Body.String(), response.Header())
+		t.FailNow()
+	}
+
+	request, _ = http.NewRequest(http.MethodGet, "/page", nil)
+	response = sendRequest(router, request)
+	body := response.Body.String()
+	if response.Header().Get("Link") != "<"+url+">; rel=preload; as=style" {
+		t.Logf("Expected preload Link header. Received %v\n", response.Header())
+		t.FailNow()
+	}
+	if !strings.Contains(body, `<link href="`+url+`">`) || !strings.Contains(body, "<script src=") || !strings.Contains(body, `<link rel="stylesheet"`) {
+		t.Logf("Expected assets over the inline limit to be linked. Received %s\n", body)
+		t.FailNow()
+	}
+
+	if _, err := router.AssetURL("missing.css"); err == nil {
+		t.Log("Expected error for unknown asset")
+		t.FailNow()
+	}
+}
+
+func TestInlineAssets(t *testing.T) {
+	router := defaultTestRouter()
+	router.StaticDir("test_files/assets")
+	router.Path("/page").Get(func(ctx *gyr.Context) *gyr.Response {
+		page := template.Must(template.New("page").Funcs(router.AssetFuncs(ctx)).Parse(`{{ inline "app.css" }}`))
+		var html strings.Builder
+		page.Execute(&html, nil)
+		return ctx.Response().Html(html.String())
+	})
+
+	request, _ := http.NewRequest(http.MethodGet, "/page", nil)
+	if body := sendRequest(router, request).Body.String(); body != "<style>body{color:red}</style>" {
+		t.Logf("Expected small asset to be inlined. Received %s\n", body)
+		t.FailNow()
+	}
+}
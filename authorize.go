@@ -0,0 +1,117 @@
+package gyr
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Route metadata keys used by [Authorize], followed by a space and the method the requirement applies to.
+const (
+	metaScopes   = "gyr.scopes"
+	metaRoles    = "gyr.roles"
+	metaPolicies = "gyr.policies"
+)
+
+// The authenticated caller of a request, set by authentication middleware with [Context.SetPrincipal].
+type Principal struct {
+	Subject string
+	Scopes  []string
+	Roles   []string
+	// Any other claims or attributes of the caller.
+	Claims map[string]any
+}
+
+// Custom authorization check, reporting whether principal may access the route of ctx.
+type PolicyFunc func(ctx *Context, principal *Principal) bool
+
+func (ctx *Context) SetPrincipal(principal *Principal) {
+	ctx.principal = principal
+}
+
+// The caller set by authentication middleware, or nil if the request isn't authenticated.
+func (ctx *Context) Principal() *Principal {
+	return ctx.principal
+}
+
+// Require the caller to have every one of scopes. Like the other requirements it applies to the method of the
+// handler added last, or to every method when no handler has been added yet. Checked by [Authorize].
+//
+//	router.Path("/orders").Get(ListOrders).RequireScopes("orders:read").Post(CreateOrder).RequireScopes("orders:write")
+func (route *Route) RequireScopes(scopes ...string) *Route {
+	return addRequirement(route, metaScopes, scopes...)
+}
+
+// Require the caller to have at least one of roles. Checked by [Authorize].
+func (route *Route) RequireRole(roles ...string) *Route {
+	return addRequirement(route, metaRoles, roles...)
+}
+
+// Require policy to allow the caller. Checked by [Authorize].
+func (route *Route) RequirePolicy(policy PolicyFunc) *Route {
+	return addRequirement(route, metaPolicies, policy)
+}
+
+// Requirements are stored per method under key followed by the method, with an empty method for every method.
+func addRequirement[T any](route *Route, key string, values ...T) *Route {
+	key += " " + route.lastMethod
+	existing, _ := route.meta[key].([]T)
+	return route.Set(key, append(slices.Clone(existing), values...))
+}
+
+// The requirements under key for the method of the request.
+func requirements[T any](ctx *Context, key string) []T {
+	method := ctx.Request.Method
+	if _, hasHead := ctx.route.handlers[http.MethodHead]; method == http.MethodHead && !hasHead {
+		method = http.MethodGet
+	}
+	forAll, _ := RouteMeta[[]T](ctx, key+" ")
+	forMethod, _ := RouteMeta[[]T](ctx, key+" "+method)
+	return append(slices.Clone(forAll), forMethod...)
+}
+
+// Middleware enforcing the requirements added with [Route.RequireScopes], [Route.RequireRole] and
+// [Route.RequirePolicy] for the method of the request. Requests to routes with requirements get 401
+// Unauthorized when no principal has been set and 403 Forbidden when the principal doesn't satisfy them. It must
+// run after the middleware that authenticates the request.
+//
+//	router.Middleware(authenticate, gyr.Authorize())
+//	router.Path("/orders").Post(CreateOrder).RequireScopes("orders:write")
+func Authorize() Handler {
+	return func(ctx *Context) *Response {
+		if ctx.route == nil {
+			return nil
+		}
+		scopes := requirements[string](ctx, metaScopes)
+		roles := requirements[string](ctx, metaRoles)
+		policies := requirements[PolicyFunc](ctx, metaPolicies)
+		if len(scopes) == 0 && len(roles) == 0 && len(policies) == 0 {
+			return nil
+		}
+
+		principal := ctx.Principal()
+		if principal == nil {
+			return ctx.Response().Status(http.StatusUnauthorized).Text("401 - Unauthorized")
+		}
+		if !principal.authorized(ctx, scopes, roles, policies) {
+			return ctx.Response().Status(http.StatusForbidden).Text("403 - Forbidden")
+		}
+		return nil
+	}
+}
+
+func (principal *Principal) authorized(ctx *Context, scopes []string, roles []string, policies []PolicyFunc) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(principal.Scopes, scope) {
+			return false
+		}
+	}
+	if len(roles) > 0 && !slices.ContainsFunc(roles, func(role string) bool { return slices.Contains(principal.Roles, role) }) {
+		return false
+	}
+	for _, policy := range policies {
+		if !policy(ctx, principal) {
+			return false
+		}
+	}
+	return true
+}
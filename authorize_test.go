@@ -0,0 +1,78 @@
+package gyr_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestAuthorize(t *testing.T) {
+	router := defaultTestRouter()
+	router.Middleware(func(ctx *gyr.Context) *gyr.Response {
+		switch ctx.Request.Header.Get("Authorization") {
+		case "writer":
+			ctx.SetPrincipal(&gyr.Principal{Subject: "1", Scopes: []string{"orders:read", "orders:write"}})
+		case "reader":
+			ctx.SetPrincipal(&gyr.Principal{Subject: "2", Scopes: []string{"orders:read"}, Roles: []string{"admin"}})
+		}
+		return nil
+	}, gyr.Authorize())
+	ok := func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Text("ok") }
+	router.Path("/public").Get(ok)
+	router.Path("/orders").Post(ok).RequireScopes("orders:write")
+	router.Path("/admin").Get(ok).RequireRole("admin", "owner")
+	router.Path("/orders/:id").Get(ok).RequirePolicy(func(ctx *gyr.Context, principal *gyr.Principal) bool {
+		return ctx.StringVariable("id") == "own"
+	})
+
+	cases := []struct {
+		request  *gyrtest.Request
+		expected int
+	}{
+		{gyrtest.Get("/public"), http.StatusOK},
+		{gyrtest.Post("/orders"), http.StatusUnauthorized},
+		{gyrtest.Post("/orders").Header("Authorization", "reader"), http.StatusForbidden},
+		{gyrtest.Post("/orders").Header("Authorization", "writer"), http.StatusOK},
+		{gyrtest.Get("/admin").Header("Authorization", "writer"), http.StatusForbidden},
+		{gyrtest.Get("/admin").Header("Authorization", "reader"), http.StatusOK},
+		{gyrtest.Get("/orders/other").Header("Authorization", "reader"), http.StatusForbidden},
+		{gyrtest.Get("/orders/own").Header("Authorization", "reader"), http.StatusOK},
+	}
+	for _, c := range cases {
+		c.request.Send(router).AssertStatus(t, c.expected)
+	}
+}
+
+func TestAuthorizePerMethod(t *testing.T) {
+	router := defaultTestRouter()
+	router.Middleware(func(ctx *gyr.Context) *gyr.Response {
+		switch ctx.Request.Header.Get("Authorization") {
+		case "writer":
+			ctx.SetPrincipal(&gyr.Principal{Subject: "1", Scopes: []string{"orders:read", "orders:write"}})
+		case "reader":
+			ctx.SetPrincipal(&gyr.Principal{Subject: "2", Scopes: []string{"orders:read"}, Roles: []string{"admin"}})
+		}
+		return nil
+	}, gyr.Authorize())
+	ok := func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Text("ok") }
+	router.Path("/orders").Get(ok).RequireScopes("orders:read").Post(ok).RequireScopes("orders:write")
+	router.Path("/reports").RequireRole("admin").Get(ok).Post(ok)
+
+	cases := []struct {
+		request  *gyrtest.Request
+		expected int
+	}{
+		{gyrtest.Get("/orders").Header("Authorization", "reader"), http.StatusOK},
+		{gyrtest.NewRequest(http.MethodHead, "/orders"), http.StatusUnauthorized},
+		{gyrtest.Post("/orders").Header("Authorization", "reader"), http.StatusForbidden},
+		{gyrtest.Post("/orders").Header("Authorization", "writer"), http.StatusOK},
+		{gyrtest.Get("/reports").Header("Authorization", "writer"), http.StatusForbidden},
+		{gyrtest.Post("/reports").Header("Authorization", "writer"), http.StatusForbidden},
+		{gyrtest.Post("/reports").Header("Authorization", "reader"), http.StatusOK},
+	}
+	for _, c := range cases {
+		c.request.Send(router).AssertStatus(t, c.expected)
+	}
+}
@@ -0,0 +1,155 @@
+package gyr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+var ErrBackgroundPoolClosed = errors.New("background pool is shut down")
+var ErrNoBackgroundWorkers = errors.New("background pool has no workers")
+
+type BackgroundSettings struct {
+	// Number of tasks run concurrently. Must be at least 1.
+	Workers int
+	// Tasks waiting for a worker before Go blocks.
+	QueueSize int
+}
+
+func DefaultBackgroundSettings() BackgroundSettings {
+	return BackgroundSettings{
+		Workers:   4,
+		QueueSize: 100,
+	}
+}
+
+func BackgroundWorkers(workers int) func(*BackgroundSettings) {
+	return func(bs *BackgroundSettings) {
+		bs.Workers = workers
+	}
+}
+
+func BackgroundQueueSize(size int) func(*BackgroundSettings) {
+	return func(bs *BackgroundSettings) {
+		bs.QueueSize = size
+	}
+}
+
+// Runs short tasks, such as sending an email after a response, on a pool of workers. Errors and panics in
+// tasks are logged. Workers are started when the first task is submitted.
+type BackgroundPool struct {
+	Settings BackgroundSettings
+	logger   *slog.Logger
+	tasks    chan backgroundTask
+	start    sync.Once
+	workers  sync.WaitGroup
+	mx       sync.RWMutex
+	closed   bool
+}
+
+type backgroundTask struct {
+	ctx context.Context
+	fn  func(context.Context) error
+}
+
+func NewBackgroundPool(settings ...SettingsFunc[BackgroundSettings]) *BackgroundPool {
+	poolSettings := DefaultBackgroundSettings()
+	for _, setting := range settings {
+		setting(&poolSettings)
+	}
+	return &BackgroundPool{
+		Settings: poolSettings,
+		logger:   Logger().With("component", "background"),
+		tasks:    make(chan backgroundTask, poolSettings.QueueSize),
+	}
+}
+
+// Run fn on the pool with ctx. Blocks while the queue is full.
+func (pool *BackgroundPool) Go(ctx context.Context, fn func(context.Context) error) error {
+	if pool.Settings.Workers < 1 {
+		// Nothing would ever take the task from the queue.
+		return ErrNoBackgroundWorkers
+	}
+	pool.mx.RLock()
+	defer pool.mx.RUnlock()
+	if pool.closed {
+		return ErrBackgroundPoolClosed
+	}
+	pool.start.Do(func() {
+		for i := 0; i < pool.Settings.Workers; i++ {
+			pool.workers.Add(1)
+			go pool.work()
+		}
+	})
+	pool.tasks <- backgroundTask{ctx: ctx, fn: fn}
+	return nil
+}
+
+// Stop taking new tasks and wait for queued tasks to finish, or until ctx is done.
+func (pool *BackgroundPool) Shutdown(ctx context.Context) error {
+	pool.mx.Lock()
+	if pool.closed {
+		pool.mx.Unlock()
+		return nil
+	}
+	pool.closed = true
+	close(pool.tasks)
+	pool.mx.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		pool.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (pool *BackgroundPool) work() {
+	defer pool.workers.Done()
+	for task := range pool.tasks {
+		if err := runBackgroundTask(task); err != nil {
+			LoggerFrom(task.ctx).Error("Background task failed", "component", "background", "error", err)
+		}
+	}
+}
+
+// Panics in tasks are returned as errors instead of crashing the worker.
+func runBackgroundTask(task backgroundTask) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("background task panicked: %v", recovered)
+		}
+	}()
+	return task.fn(task.ctx)
+}
+
+// Use pool for the tasks scheduled with [Context.Defer] instead of the router's own pool.
+func (router *Router) BackgroundPool(pool *BackgroundPool) {
+	router.background = pool
+}
+
+// Run fn on the router's [BackgroundPool] after the response has been sent. fn gets a context with the values
+// of the request context, such as the request ID, that isn't canceled when the request ends. Tasks still queued
+// when the server stops are finished by [ServerComponent] and [Router.Run] before they return.
+func (ctx *Context) Defer(fn func(context.Context) error) {
+	taskCtx := context.WithoutCancel(ctx.Request.Context())
+	ctx.onDone(func() {
+		task := backgroundTask{ctx: taskCtx, fn: fn}
+		if ctx.background == nil {
+			if err := runBackgroundTask(task); err != nil {
+				ctx.Logger().Error("Background task failed", "error", err)
+			}
+			return
+		}
+		if err := ctx.background.Go(taskCtx, fn); err != nil {
+			ctx.Logger().Error("Failed to schedule background task", "error", err)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package gyr_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestContextDefer(t *testing.T) {
+	var ran atomic.Int32
+	var requestID atomic.Value
+	pool := gyr.NewBackgroundPool(gyr.BackgroundWorkers(2))
+	router := defaultTestRouter()
+	router.BackgroundPool(pool)
+	router.Path("/signup").Post(func(ctx *gyr.Context) *gyr.Response {
+		ctx.Defer(func(taskCtx context.Context) error {
+			if taskCtx.Err() != nil {
+				return taskCtx.Err()
+			}
+			requestID.Store(gyr.RequestID(taskCtx))
+			ran.Add(1)
+			return nil
+		})
+		ctx.Defer(func(context.Context) error { panic("mail server down") })
+		ctx.Defer(func(context.Context) error { return errors.New("cache unavailable") })
+		return ctx.Response().Status(http.StatusCreated)
+	})
+
+	gyrtest.Post("/signup").Header(gyr.RequestIDHeader, "abc").Send(router).AssertStatus(t, http.StatusCreated)
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Logf("Shutdown failed: %v\n", err)
+		t.FailNow()
+	}
+	if ran.Load() != 1 || requestID.Load() != "abc" {
+		t.Logf("Expected task to run once with the request ID, ran %d times with %v\n", ran.Load(), requestID.Load())
+		t.FailNow()
+	}
+	if err := pool.Go(context.Background(), func(context.Context) error { return nil }); !errors.Is(err, gyr.ErrBackgroundPoolClosed) {
+		t.Logf("Expected ErrBackgroundPoolClosed, got %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestBackgroundPoolWithoutWorkers(t *testing.T) {
+	pool := gyr.NewBackgroundPool(gyr.BackgroundWorkers(0))
+	if err := pool.Go(context.Background(), func(context.Context) error { return nil }); !errors.Is(err, gyr.ErrNoBackgroundWorkers) {
+		t.Logf("Expected ErrNoBackgroundWorkers, got %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestServerComponentDrainsDeferredTasks(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("can't listen:", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	var finished atomic.Bool
+	router := defaultTestRouter()
+	router.Path("/signup").Post(func(ctx *gyr.Context) *gyr.Response {
+		ctx.Defer(func(context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			finished.Store(true)
+			return nil
+		})
+		return ctx.Response().Status(http.StatusCreated)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- gyr.Run(ctx, gyr.ServerComponent(&http.Server{Addr: addr, Handler: router}))
+	}()
+	var response *http.Response
+	for range 100 {
+		if response, err = http.Post("http://"+addr+"/signup", "", nil); err == nil {
+			response.Body.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Logf("Request failed: %v\n", err)
+		t.FailNow()
+	}
+	cancel()
+
+	if err := <-runErr; err != nil || !finished.Load() {
+		t.Logf("Expected the deferred task to finish before Run returned (%v)\n", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,29 @@
+package gyr
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Authenticate requests with HTTP Basic authentication, checking the credentials with validate. The username of
+// valid credentials becomes the Subject of the [Principal]. Other requests get 401 Unauthorized with a
+// WWW-Authenticate challenge for realm. Compare passwords in validate with [crypto/subtle.ConstantTimeCompare]
+// or a password hash to not leak them through timing.
+//
+//	router.Group("/admin").Middleware(gyr.BasicAuth(func(user, pass string) bool {
+//		return user == "admin" && subtle.ConstantTimeCompare([]byte(pass), adminPassword) == 1
+//	}, "admin"))
+func BasicAuth(validate func(user string, pass string) bool, realm string) Handler {
+	challenge := "Basic realm=" + strconv.Quote(realm) + `, charset="UTF-8"`
+	return func(ctx *Context) *Response {
+		user, pass, ok := ctx.Request.BasicAuth()
+		if !ok || !validate(user, pass) {
+			return ctx.Response().
+				Status(http.StatusUnauthorized).
+				Header("WWW-Authenticate", challenge).
+				Text("401 - Unauthorized")
+		}
+		ctx.SetPrincipal(&Principal{Subject: user})
+		return nil
+	}
+}
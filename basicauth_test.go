@@ -0,0 +1,38 @@
+package gyr_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestBasicAuth(t *testing.T) {
+	router := defaultTestRouter()
+	router.Middleware(gyr.BasicAuth(func(user, pass string) bool {
+		return user == "admin" && pass == "secret"
+	}, "admin area"))
+	router.Path("/whoami").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text(ctx.Principal().Subject)
+	})
+
+	gyrtest.Get("/whoami").Send(router).
+		AssertStatus(t, http.StatusUnauthorized).
+		AssertHeader(t, "WWW-Authenticate", `Basic realm="admin area", charset="UTF-8"`)
+
+	wrong, _ := http.NewRequest(http.MethodGet, "/whoami", nil)
+	wrong.SetBasicAuth("admin", "guess")
+	if response := sendRequest(router, wrong); response.Code != http.StatusUnauthorized {
+		t.Logf("Expected 401 for a wrong password. Received %d", response.Code)
+		t.FailNow()
+	}
+
+	valid, _ := http.NewRequest(http.MethodGet, "/whoami", nil)
+	valid.SetBasicAuth("admin", "secret")
+	response := sendRequest(router, valid)
+	if response.Code != http.StatusOK || response.Body.String() != "admin" {
+		t.Logf("Expected the username as principal. Received %d %q", response.Code, response.Body.String())
+		t.FailNow()
+	}
+}
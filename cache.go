@@ -0,0 +1,250 @@
+package gyr
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type CacheSettings struct {
+	// Maximum number of entries before the least recently used entry is evicted. 0 means no limit.
+	MaxEntries int
+	// TTL used by [Cache.Set]. 0 means entries don't expire.
+	DefaultTTL time.Duration
+	// Metrics hooks, called without the cache lock held.
+	OnHit   func()
+	OnMiss  func()
+	OnEvict func()
+}
+
+func CacheMaxEntries(max int) func(*CacheSettings) {
+	return func(cs *CacheSettings) {
+		cs.MaxEntries = max
+	}
+}
+
+func CacheTTL(ttl time.Duration) func(*CacheSettings) {
+	return func(cs *CacheSettings) {
+		cs.DefaultTTL = ttl
+	}
+}
+
+func CacheMetrics(onHit func(), onMiss func(), onEvict func()) func(*CacheSettings) {
+	return func(cs *CacheSettings) {
+		cs.OnHit = onHit
+		cs.OnMiss = onMiss
+		cs.OnEvict = onEvict
+	}
+}
+
+// In-memory cache with per-entry TTL and least recently used eviction. Safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	settings CacheSettings
+	mx       sync.Mutex
+	entries  map[K]*list.Element
+	lru      *list.List
+	inFlight map[K]*cacheCall[V]
+}
+
+type cacheEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+type cacheCall[V any] struct {
+	wg    sync.WaitGroup
+	value V
+	err   error
+	// Set when the key is changed while computing, so that the possibly stale value is not stored.
+	invalidated bool
+}
+
+func NewCache[K comparable, V any](settings ...SettingsFunc[CacheSettings]) *Cache[K, V] {
+	var cacheSettings CacheSettings
+	for _, setting := range settings {
+		setting(&cacheSettings)
+	}
+	return &Cache[K, V]{
+		settings: cacheSettings,
+		entries:  make(map[K]*list.Element),
+		lru:      list.New(),
+		inFlight: make(map[K]*cacheCall[V]),
+	}
+}
+
+func (cache *Cache[K, V]) Get(key K) (V, bool) {
+	cache.mx.Lock()
+	value, found, expired := cache.get(key)
+	cache.mx.Unlock()
+
+	if expired {
+		cache.notify(cache.settings.OnEvict)
+	}
+	if found {
+		cache.notify(cache.settings.OnHit)
+	} else {
+		cache.notify(cache.settings.OnMiss)
+	}
+	return value, found
+}
+
+// Must be called with the lock held. Removes the entry if it has expired.
+func (cache *Cache[K, V]) get(key K) (value V, found bool, expired bool) {
+	element, exists := cache.entries[key]
+	if !exists {
+		return value, false, false
+	}
+	entry := element.Value.(*cacheEntry[K, V])
+	if !entry.expiresAt.IsZero() && CurrentClock().Now().After(entry.expiresAt) {
+		cache.remove(element)
+		return value, false, true
+	}
+	cache.lru.MoveToFront(element)
+	return entry.value, true, false
+}
+
+// Store value using the default TTL.
+func (cache *Cache[K, V]) Set(key K, value V) {
+	cache.SetWithTTL(key, value, cache.settings.DefaultTTL)
+}
+
+// Store value for ttl. A ttl of 0 means the entry doesn't expire. A value being computed by [Cache.GetOrCompute]
+// for key is not stored.
+func (cache *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	cache.mx.Lock()
+	cache.invalidate(key)
+	evicted := cache.set(key, value, ttl)
+	cache.mx.Unlock()
+
+	for i := 0; i < evicted; i++ {
+		cache.notify(cache.settings.OnEvict)
+	}
+}
+
+// Must be called with the lock held. Returns the number of evicted entries.
+func (cache *Cache[K, V]) set(key K, value V, ttl time.Duration) int {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = CurrentClock().Now().Add(ttl)
+	}
+	if element, exists := cache.entries[key]; exists {
+		entry := element.Value.(*cacheEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		cache.lru.MoveToFront(element)
+		return 0
+	}
+
+	cache.entries[key] = cache.lru.PushFront(&cacheEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	evicted := 0
+	for cache.settings.MaxEntries > 0 && cache.lru.Len() > cache.settings.MaxEntries {
+		cache.remove(cache.lru.Back())
+		evicted++
+	}
+	return evicted
+}
+
+// Remove the entry for key. A value being computed by [Cache.GetOrCompute] is not stored.
+func (cache *Cache[K, V]) Delete(key K) {
+	cache.mx.Lock()
+	defer cache.mx.Unlock()
+	cache.invalidate(key)
+	if element, exists := cache.entries[key]; exists {
+		cache.remove(element)
+	}
+}
+
+// Remove all entries. Values being computed by [Cache.GetOrCompute] are not stored.
+func (cache *Cache[K, V]) Clear() {
+	cache.mx.Lock()
+	defer cache.mx.Unlock()
+	for key := range cache.inFlight {
+		cache.invalidate(key)
+	}
+	cache.entries = make(map[K]*list.Element)
+	cache.lru.Init()
+}
+
+// Must be called with the lock held. Keeps the value being computed for key from being stored.
+func (cache *Cache[K, V]) invalidate(key K) {
+	if call, computing := cache.inFlight[key]; computing {
+		call.invalidated = true
+		delete(cache.inFlight, key)
+	}
+}
+
+// Number of entries, including expired entries that haven't been removed yet.
+func (cache *Cache[K, V]) Len() int {
+	cache.mx.Lock()
+	defer cache.mx.Unlock()
+	return cache.lru.Len()
+}
+
+// Get the value for key, computing and storing it with the default TTL if it is missing. Concurrent calls
+// for the same missing key share a single call to compute. Errors are returned to every waiting caller and
+// are not cached. A panic in compute is returned as an error to the waiting callers and re-panics in the caller
+// that ran compute.
+func (cache *Cache[K, V]) GetOrCompute(key K, compute func() (V, error)) (V, error) {
+	cache.mx.Lock()
+	value, found, expired := cache.get(key)
+	if found {
+		cache.mx.Unlock()
+		cache.notify(cache.settings.OnHit)
+		return value, nil
+	}
+	if call, computing := cache.inFlight[key]; computing {
+		cache.mx.Unlock()
+		call.wg.Wait()
+		return call.value, call.err
+	}
+	call := &cacheCall[V]{}
+	call.wg.Add(1)
+	cache.inFlight[key] = call
+	cache.mx.Unlock()
+
+	if expired {
+		cache.notify(cache.settings.OnEvict)
+	}
+	cache.notify(cache.settings.OnMiss)
+
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			call.err = fmt.Errorf("cache compute panicked: %v", recovered)
+		}
+		cache.mx.Lock()
+		if cache.inFlight[key] == call {
+			delete(cache.inFlight, key)
+		}
+		evicted := 0
+		// A value computed before the key changed may be stale, so it is returned but not stored.
+		if call.err == nil && !call.invalidated {
+			evicted = cache.set(key, call.value, cache.settings.DefaultTTL)
+		}
+		cache.mx.Unlock()
+		call.wg.Done()
+		for i := 0; i < evicted; i++ {
+			cache.notify(cache.settings.OnEvict)
+		}
+		// Waiting callers got the error above, the caller that ran compute gets the panic.
+		if recovered != nil {
+			panic(recovered)
+		}
+	}()
+	call.value, call.err = compute()
+	return call.value, call.err
+}
+
+// Must be called with the lock held.
+func (cache *Cache[K, V]) remove(element *list.Element) {
+	cache.lru.Remove(element)
+	delete(cache.entries, element.Value.(*cacheEntry[K, V]).key)
+}
+
+func (cache *Cache[K, V]) notify(hook func()) {
+	if hook != nil {
+		hook()
+	}
+}
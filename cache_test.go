@@ -0,0 +1,199 @@
+package gyr_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestCacheGetSetDelete(t *testing.T) {
+	cache := gyr.NewCache[string, int]()
+	cache.Set("a", 1)
+	if v, found := cache.Get("a"); !found || v != 1 {
+		t.Logf("Expected 1. Received %v (%v)\n", v, found)
+		t.FailNow()
+	}
+	cache.Delete("a")
+	if _, found := cache.Get("a"); found {
+		t.FailNow()
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	clock := gyr.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	gyr.SetClock(clock)
+	defer gyr.SetClock(nil)
+
+	cache := gyr.NewCache[string, int]()
+	cache.SetWithTTL("short", 1, time.Millisecond)
+	cache.SetWithTTL("forever", 2, 0)
+	clock.Advance(time.Hour)
+	if _, found := cache.Get("short"); found {
+		t.Log("Expected expired entry to be gone")
+		t.FailNow()
+	}
+	if _, found := cache.Get("forever"); !found {
+		t.FailNow()
+	}
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+	var evictions atomic.Int32
+	cache := gyr.NewCache[string, int](gyr.CacheMaxEntries(2), gyr.CacheMetrics(nil, nil, func() { evictions.Add(1) }))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Set("c", 3)
+	if _, found := cache.Get("b"); found {
+		t.Log("Expected least recently used entry b to be evicted")
+		t.FailNow()
+	}
+	if _, found := cache.Get("a"); !found || cache.Len() != 2 || evictions.Load() != 1 {
+		t.Logf("Len %d, evictions %d\n", cache.Len(), evictions.Load())
+		t.FailNow()
+	}
+}
+
+func TestCacheGetOrComputeSingleflight(t *testing.T) {
+	cache := gyr.NewCache[string, int]()
+	var calls atomic.Int32
+	start := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			v, err := cache.GetOrCompute("key", func() (int, error) {
+				calls.Add(1)
+				time.Sleep(10 * time.Millisecond)
+				return 42, nil
+			})
+			if err != nil || v != 42 {
+				t.Errorf("Expected 42. Received %v (%v)\n", v, err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Logf("Expected compute to run once. Ran %d times\n", calls.Load())
+		t.FailNow()
+	}
+}
+
+func TestCacheGetOrComputePanic(t *testing.T) {
+	cache := gyr.NewCache[string, int]()
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() { panicked <- recover() }()
+		cache.GetOrCompute("key", func() (int, error) {
+			close(entered)
+			<-release
+			panic("compute failed")
+		})
+	}()
+	<-entered
+	waiterErr := make(chan error, 1)
+	go func() {
+		_, err := cache.GetOrCompute("key", func() (int, error) { return 1, nil })
+		waiterErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	if recovered := <-panicked; recovered != "compute failed" {
+		t.Logf("Expected the panic to reach the computing caller. Received %v\n", recovered)
+		t.FailNow()
+	}
+	if err := <-waiterErr; err == nil {
+		t.Log("Expected the waiting caller to get an error")
+		t.FailNow()
+	}
+	if _, found := cache.Get("key"); found {
+		t.Log("Expected nothing to be cached after a panic")
+		t.FailNow()
+	}
+}
+
+func TestCacheInvalidationDuringCompute(t *testing.T) {
+	for name, invalidate := range map[string]func(*gyr.Cache[string, int]){
+		"delete": func(cache *gyr.Cache[string, int]) { cache.Delete("key") },
+		"clear":  func(cache *gyr.Cache[string, int]) { cache.Clear() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache := gyr.NewCache[string, int]()
+			entered := make(chan struct{})
+			release := make(chan struct{})
+			done := make(chan int)
+			go func() {
+				value, _ := cache.GetOrCompute("key", func() (int, error) {
+					close(entered)
+					<-release
+					return 1, nil
+				})
+				done <- value
+			}()
+			<-entered
+			invalidate(cache)
+			close(release)
+
+			if value := <-done; value != 1 {
+				t.Logf("Expected the computed value to be returned. Received %d\n", value)
+				t.FailNow()
+			}
+			if value, found := cache.Get("key"); found {
+				t.Logf("Expected the value computed before the invalidation not to be stored. Received %d\n", value)
+				t.FailNow()
+			}
+			value, _ := cache.GetOrCompute("key", func() (int, error) { return 2, nil })
+			if value != 2 {
+				t.Logf("Expected a fresh value. Received %d\n", value)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestCacheChangesDuringCompute(t *testing.T) {
+	compute := func(cache *gyr.Cache[string, int], change func()) int {
+		entered := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan int)
+		go func() {
+			value, _ := cache.GetOrCompute("key", func() (int, error) {
+				close(entered)
+				<-release
+				return 1, nil
+			})
+			done <- value
+		}()
+		<-entered
+		change()
+		close(release)
+		return <-done
+	}
+
+	t.Run("set wins", func(t *testing.T) {
+		cache := gyr.NewCache[string, int]()
+		compute(cache, func() { cache.Set("key", 3) })
+		if value, _ := cache.Get("key"); value != 3 {
+			t.Logf("Expected the value from Set to be kept. Received %d\n", value)
+			t.FailNow()
+		}
+	})
+
+	t.Run("other keys", func(t *testing.T) {
+		cache := gyr.NewCache[string, int]()
+		compute(cache, func() { cache.Delete("other") })
+		if value, found := cache.Get("key"); !found || value != 1 {
+			t.Logf("Expected the computed value to be stored. Received %d (%v)\n", value, found)
+			t.FailNow()
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package gyr
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+type CLISettings struct {
+	// Connection used by the migrate, rollback and status commands.
+	Connection DBTX
+	// Router listed by the routes command.
+	Router *Router
+	// Settings passed on to [NewMigrator].
+	MigratorSettings []SettingsFunc[MigratorSettings]
+	Output           io.Writer
+}
+
+func DefaultCLISettings() CLISettings {
+	return CLISettings{
+		MigratorSettings: make([]SettingsFunc[MigratorSettings], 0),
+		Output:           os.Stdout,
+	}
+}
+
+func CLIConnection(connection DBTX) func(*CLISettings) {
+	return func(cs *CLISettings) {
+		cs.Connection = connection
+	}
+}
+
+func CLIRouter(router *Router) func(*CLISettings) {
+	return func(cs *CLISettings) {
+		cs.Router = router
+	}
+}
+
+func CLIMigratorSettings(settings ...SettingsFunc[MigratorSettings]) func(*CLISettings) {
+	return func(cs *CLISettings) {
+		cs.MigratorSettings = append(cs.MigratorSettings, settings...)
+	}
+}
+
+func CLIOutput(writer io.Writer) func(*CLISettings) {
+	return func(cs *CLISettings) {
+		cs.Output = writer
+	}
+}
+
+const cliUsage = `Usage: %s <command> [arguments]
+
+Commands:
+  migrate                      Run all pending migrations
+  rollback                     Revert the most recently applied migration
+  status                       List migrations and whether they have been applied
+  new-migration [-dir] <name>  Create a new migration file pair
+  routes                       List the registered routes
+`
+
+// Run a gyr command line. args are expected to be in the format of os.Args, with the program name first.
+//
+//	func main() {
+//		if err := gyr.RunCLI(os.Args, gyr.CLIConnection(db), gyr.CLIRouter(router)); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func RunCLI(args []string, settings ...SettingsFunc[CLISettings]) error {
+	cliSettings := DefaultCLISettings()
+	for _, setting := range settings {
+		setting(&cliSettings)
+	}
+
+	program := "gyr"
+	if len(args) > 0 {
+		program = args[0]
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		fmt.Fprintf(cliSettings.Output, cliUsage, program)
+		return errors.New("no command given")
+	}
+
+	switch args[0] {
+	case "migrate":
+		migrator, err := cliSettings.migrator()
+		if err != nil {
+			return err
+		}
+		return migrator.Migrate()
+	case "rollback":
+		migrator, err := cliSettings.migrator()
+		if err != nil {
+			return err
+		}
+		return migrator.Rollback()
+	case "status":
+		return cliSettings.status()
+	case "new-migration":
+		return cliSettings.newMigration(args[1:])
+	case "routes":
+		return cliSettings.routes()
+	case "help", "-h", "--help":
+		fmt.Fprintf(cliSettings.Output, cliUsage, program)
+		return nil
+	default:
+		fmt.Fprintf(cliSettings.Output, cliUsage, program)
+		return fmt.Errorf("unknown command %s", args[0])
+	}
+}
+
+func (cs CLISettings) migrator() (*Migrator, error) {
+	if cs.Connection == nil {
+		return nil, errors.New("no database connection configured")
+	}
+	return NewMigrator(cs.Connection, cs.MigratorSettings...), nil
+}
+
+func (cs CLISettings) migratorDirectory() string {
+	migratorSettings := DefaultMigratorSettings()
+	for _, setting := range cs.MigratorSettings {
+		setting(&migratorSettings)
+	}
+	return migratorSettings.Directory
+}
+
+func (cs CLISettings) status() error {
+	migrator, err := cs.migrator()
+	if err != nil {
+		return err
+	}
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(cs.Output, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tSTATUS\tAPPLIED AT\tPATH")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.Applied {
+			state = "applied"
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", status.Version, state, appliedAt, status.Path)
+	}
+	return writer.Flush()
+}
+
+func (cs CLISettings) newMigration(args []string) error {
+	flags := flag.NewFlagSet("new-migration", flag.ContinueOnError)
+	flags.SetOutput(cs.Output)
+	dir := flags.String("dir", cs.migratorDirectory(), "directory to create the migration in")
+	timestamp := flags.Bool("timestamp", false, "prefix the migration with a timestamp instead of the next version")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("new-migration requires a name")
+	}
+
+	fileSettings := make([]SettingsFunc[MigrationFileSettings], 0)
+	if *timestamp {
+		fileSettings = append(fileSettings, MigrationFileTimestamp())
+	}
+	upPath, downPath, err := NewMigrationFile(*dir, strings.Join(flags.Args(), " "), fileSettings...)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cs.Output, "Created %s\nCreated %s\n", upPath, downPath)
+	return nil
+}
+
+func (cs CLISettings) routes() error {
+	if cs.Router == nil {
+		return errors.New("no router configured")
+	}
+	writer := tabwriter.NewWriter(cs.Output, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "METHODS\tPATH")
+	for _, route := range cs.Router.Routes() {
+		fmt.Fprintf(writer, "%s\t%s\n", strings.Join(route.Methods, ","), route.Path)
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,46 @@
+package gyr
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCLINewMigration(t *testing.T) {
+	dir := t.TempDir()
+	output := &bytes.Buffer{}
+	err := RunCLI([]string{"gyr", "new-migration", "-dir", dir, "add", "users"}, CLIOutput(output))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if !strings.Contains(output.String(), filepath.Join(dir, "0.0.1_add_users.sql")) {
+		t.Logf("Received %s\n", output.String())
+		t.FailNow()
+	}
+}
+
+func TestCLIRoutes(t *testing.T) {
+	router := DefaultRouter()
+	router.Path("/test").Get(func(ctx *Context) *Response { return nil }).Post(func(ctx *Context) *Response { return nil })
+	router.Group("/api").Path("/users").Get(func(ctx *Context) *Response { return nil })
+
+	output := &bytes.Buffer{}
+	if err := RunCLI([]string{"gyr", "routes"}, CLIRouter(router), CLIOutput(output)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	for _, expected := range []string{"GET,POST  /test", "GET       /api/users"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Logf("Expected output to contain %q. Received\n%s\n", expected, output.String())
+			t.FailNow()
+		}
+	}
+}
+
+func TestCLIUnknownCommand(t *testing.T) {
+	if err := RunCLI([]string{"gyr", "dance"}, CLIOutput(&bytes.Buffer{})); err == nil {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,214 @@
+package gyr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+type ClientSettings struct {
+	BaseURL string
+	Headers http.Header
+	// Timeout for each attempt of a request. 0 means no timeout besides the one of the request context.
+	Timeout time.Duration
+	// Retries for idempotent requests. Requests with other methods are only attempted once.
+	Retry      RetryPolicy
+	HTTPClient *http.Client
+}
+
+func DefaultClientSettings() ClientSettings {
+	return ClientSettings{
+		Headers:    make(http.Header),
+		Timeout:    30 * time.Second,
+		Retry:      DefaultRetryPolicy(),
+		HTTPClient: &http.Client{},
+	}
+}
+
+func ClientBaseURL(url string) func(*ClientSettings) {
+	return func(cs *ClientSettings) {
+		cs.BaseURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+func ClientHeader(name string, value string) func(*ClientSettings) {
+	return func(cs *ClientSettings) {
+		cs.Headers.Set(name, value)
+	}
+}
+
+func ClientTimeout(timeout time.Duration) func(*ClientSettings) {
+	return func(cs *ClientSettings) {
+		cs.Timeout = timeout
+	}
+}
+
+func ClientRetries(policy RetryPolicy) func(*ClientSettings) {
+	return func(cs *ClientSettings) {
+		cs.Retry = policy
+	}
+}
+
+func ClientHTTPClient(client *http.Client) func(*ClientSettings) {
+	return func(cs *ClientSettings) {
+		cs.HTTPClient = client
+	}
+}
+
+// HTTP client for calling other services. Idempotent requests are retried with exponential backoff on
+// network errors and 429/502/503/504 responses.
+type Client struct {
+	Settings ClientSettings
+}
+
+func NewClient(settings ...SettingsFunc[ClientSettings]) *Client {
+	clientSettings := DefaultClientSettings()
+	for _, setting := range settings {
+		setting(&clientSettings)
+	}
+	return &Client{Settings: clientSettings}
+}
+
+// Returned when the response has a status code of 400 or above.
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", err.StatusCode, string(err.Body))
+}
+
+var idempotentMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete}
+
+// Send a request with body as the raw request body. path is appended to the base URL. The caller must close
+// the body of the returned response.
+func (client *Client) Do(ctx context.Context, method string, path string, body []byte, headers http.Header) (*http.Response, error) {
+	policy := client.Settings.Retry
+	if !slices.Contains(idempotentMethods, method) {
+		policy.MaxAttempts = 1
+	}
+
+	var response *http.Response
+	attempt := 0
+	err := Retry(ctx, policy, func() error {
+		attempt++
+		var err error
+		response, err = client.attempt(ctx, method, path, body, headers)
+		if err != nil {
+			if ctx.Err() != nil {
+				return Permanent(err)
+			}
+			return err
+		}
+		// The response of the last attempt is returned as is so the caller can inspect it.
+		if isRetryableStatus(response.StatusCode) && attempt < policy.MaxAttempts {
+			response.Body.Close()
+			return fmt.Errorf("received status %d", response.StatusCode)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (client *Client) attempt(ctx context.Context, method string, path string, body []byte, headers http.Header) (*http.Response, error) {
+	attemptCtx := ctx
+	cancel := func() {}
+	if client.Settings.Timeout > 0 {
+		attemptCtx, cancel = context.WithTimeout(ctx, client.Settings.Timeout)
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(attemptCtx, method, client.Settings.BaseURL+path, bodyReader)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for name, values := range client.Settings.Headers {
+		request.Header[name] = slices.Clone(values)
+	}
+	for name, values := range headers {
+		request.Header[name] = slices.Clone(values)
+	}
+	if id := RequestID(ctx); id != "" && request.Header.Get(RequestIDHeader) == "" {
+		request.Header.Set(RequestIDHeader, id)
+	}
+
+	response, err := client.Settings.HTTPClient.Do(request)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	response.Body = &cancelOnClose{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+// Keeps the per-attempt timeout context alive until the body has been read.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel func()
+}
+
+func (body *cancelOnClose) Close() error {
+	defer body.cancel()
+	return body.ReadCloser.Close()
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusBadGateway ||
+		status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+// Send request encoded as JSON, or no body if request is nil, and decode the JSON response into T.
+// Responses with a status of 400 or above are returned as a [*StatusError].
+func ClientJSON[T any](ctx context.Context, client *Client, method string, path string, request any) (T, error) {
+	var result T
+	var body []byte
+	headers := http.Header{"Accept": []string{"application/json"}}
+	if request != nil {
+		var err error
+		body, err = json.Marshal(request)
+		if err != nil {
+			return result, err
+		}
+		headers.Set("Content-Type", "application/json")
+	}
+
+	response, err := client.Do(ctx, method, path, body, headers)
+	if err != nil {
+		return result, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 400 {
+		content, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return result, &StatusError{StatusCode: response.StatusCode, Body: content}
+	}
+	if response.StatusCode == http.StatusNoContent {
+		return result, nil
+	}
+	err = json.NewDecoder(response.Body).Decode(&result)
+	return result, err
+}
+
+// GET path and decode the JSON response into T.
+func GetJSON[T any](ctx context.Context, client *Client, path string) (T, error) {
+	return ClientJSON[T](ctx, client, http.MethodGet, path, nil)
+}
+
+// POST request as JSON to path and decode the JSON response into T.
+func PostJSON[T any](ctx context.Context, client *Client, path string, request any) (T, error) {
+	return ClientJSON[T](ctx, client, http.MethodPost, path, request)
+}
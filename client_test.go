@@ -0,0 +1,99 @@
+package gyr_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestClientJSON(t *testing.T) {
+	router := defaultTestRouter()
+	router.Path("/points").Post(func(ctx *gyr.Context) *gyr.Response {
+		p, err := gyr.ReadBody[point](ctx)
+		if err != nil {
+			return ctx.Response().Status(http.StatusBadRequest)
+		}
+		p.X *= 2
+		return ctx.Response().Json(p)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client := gyr.NewClient(gyr.ClientBaseURL(server.URL))
+	received, err := gyr.PostJSON[point](context.Background(), client, "/points", point{X: 2, Y: 1})
+	if err != nil || received != (point{X: 4, Y: 1}) {
+		t.Logf("Received %+v (%v)\n", received, err)
+		t.FailNow()
+	}
+
+	_, err = gyr.GetJSON[point](context.Background(), client, "/missing")
+	var statusErr *gyr.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Logf("Expected StatusError 404. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestClientRetriesIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"x":1,"y":2}`))
+	}))
+	defer server.Close()
+
+	clock := gyr.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	gyr.SetClock(clock)
+	defer gyr.SetClock(nil)
+
+	client := gyr.NewClient(gyr.ClientBaseURL(server.URL), gyr.ClientRetries(gyr.RetryPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: time.Minute}))
+	var received point
+	done := make(chan error)
+	go func() {
+		var err error
+		received, err = gyr.GetJSON[point](context.Background(), client, "/")
+		done <- err
+	}()
+	for range 2 {
+		for !clock.Waiting() {
+			time.Sleep(time.Millisecond)
+		}
+		clock.Advance(time.Minute)
+	}
+	if err := <-done; err != nil || received != (point{X: 1, Y: 2}) || calls.Load() != 3 {
+		t.Logf("Calls %d, received %+v (%v)\n", calls.Load(), received, err)
+		t.FailNow()
+	}
+
+	calls.Store(0)
+	gyr.PostJSON[point](context.Background(), client, "/", point{})
+	if calls.Load() != 1 {
+		t.Logf("Expected POST not to be retried. Calls %d\n", calls.Load())
+		t.FailNow()
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := gyr.NewClient(gyr.ClientBaseURL(server.URL), gyr.ClientTimeout(10*time.Millisecond), gyr.ClientRetries(gyr.RetryPolicy{MaxAttempts: 1}))
+	if _, err := gyr.GetJSON[point](context.Background(), client, "/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Logf("Expected deadline exceeded. Received %v\n", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,153 @@
+package gyr
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Source of the current time and timers for UUIDs, cache and session expiry, rate limiters, retry backoff and the
+// job queue.
+// Replace it with a [FakeClock] in tests to control the passing of time.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTimer(d time.Duration) Timer
+}
+
+// A timer created by a [Clock], like [time.Timer].
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (timer systemTimer) C() <-chan time.Time {
+	return timer.Timer.C
+}
+
+var clockOverride atomic.Pointer[Clock]
+
+// Use clock instead of the system clock. nil goes back to the system clock.
+func SetClock(clock Clock) {
+	if clock == nil {
+		clockOverride.Store(nil)
+		return
+	}
+	clockOverride.Store(&clock)
+}
+
+// The clock set with [SetClock], or the system clock.
+func CurrentClock() Clock {
+	if clock := clockOverride.Load(); clock != nil {
+		return *clock
+	}
+	return systemClock{}
+}
+
+// A [Clock] that only moves when told to, for deterministic tests. Timers fire when the clock is advanced past
+// their deadline. Safe for concurrent use.
+//
+//	clock := gyr.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	gyr.SetClock(clock)
+//	defer gyr.SetClock(nil)
+//	clock.Advance(time.Hour)
+type FakeClock struct {
+	mx     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (clock *FakeClock) Now() time.Time {
+	clock.mx.Lock()
+	defer clock.mx.Unlock()
+	return clock.now
+}
+
+func (clock *FakeClock) Since(t time.Time) time.Duration {
+	return clock.Now().Sub(t)
+}
+
+func (clock *FakeClock) NewTimer(d time.Duration) Timer {
+	clock.mx.Lock()
+	defer clock.mx.Unlock()
+	timer := &fakeTimer{clock: clock, deadline: clock.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		timer.c <- clock.now
+		return timer
+	}
+	clock.timers = append(clock.timers, timer)
+	return timer
+}
+
+// Move the clock forward by d, firing the timers that expire.
+func (clock *FakeClock) Advance(d time.Duration) {
+	clock.Set(clock.Now().Add(d))
+}
+
+// Move the clock to t, firing the timers that expire.
+func (clock *FakeClock) Set(t time.Time) {
+	clock.mx.Lock()
+	defer clock.mx.Unlock()
+	clock.now = t
+	pending := clock.timers[:0]
+	for _, timer := range clock.timers {
+		if timer.deadline.After(t) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.c <- t
+	}
+	clock.timers = pending
+}
+
+// Reports whether a timer is waiting for the clock to advance, for tests to wait for a goroutine to block.
+func (clock *FakeClock) Waiting() bool {
+	clock.mx.Lock()
+	defer clock.mx.Unlock()
+	return len(clock.timers) > 0
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	c        chan time.Time
+}
+
+func (timer *fakeTimer) C() <-chan time.Time {
+	return timer.c
+}
+
+// Reports whether the timer was stopped before it fired.
+func (timer *fakeTimer) Stop() bool {
+	timer.clock.mx.Lock()
+	defer timer.clock.mx.Unlock()
+	for i, pending := range timer.clock.timers {
+		if pending == timer {
+			timer.clock.timers = append(timer.clock.timers[:i], timer.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package gyr_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestFakeClock(t *testing.T) {
+	clock := gyr.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	gyr.SetClock(clock)
+	defer gyr.SetClock(nil)
+
+	cache := gyr.NewCache[string, string](gyr.CacheTTL(time.Minute))
+	cache.Set("key", "value")
+	clock.Advance(59 * time.Second)
+	if _, found := cache.Get("key"); !found {
+		t.Log("Expected the entry before its TTL")
+		t.FailNow()
+	}
+	clock.Advance(2 * time.Second)
+	if _, found := cache.Get("key"); found {
+		t.Log("Expected the entry to expire after its TTL")
+		t.FailNow()
+	}
+
+	limiter := gyr.NewLimiter(1, 1)
+	limiter.Allow()
+	waited := make(chan error)
+	go func() { waited <- limiter.Wait(context.Background()) }()
+	for !clock.Waiting() {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Second)
+	if err := <-waited; err != nil {
+		t.Logf("Expected Wait to return when the clock is advanced. Received %v\n", err)
+		t.FailNow()
+	}
+
+	timer := clock.NewTimer(time.Hour)
+	if !timer.Stop() || clock.Since(clock.Now()) != 0 {
+		t.Log("Expected a pending timer to be stopped")
+		t.FailNow()
+	}
+}
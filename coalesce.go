@@ -0,0 +1,122 @@
+package gyr
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type CoalesceSettings struct {
+	// Requests with the same key share a handler execution. Defaults to the method, URL and the Authorization
+	// and Cookie headers, so requests made with different credentials never share a response.
+	Key func(*Context) string
+}
+
+func DefaultCoalesceSettings() CoalesceSettings {
+	return CoalesceSettings{
+		Key: func(ctx *Context) string {
+			header := ctx.Request.Header
+			return strings.Join([]string{
+				ctx.Request.Method, ctx.Request.URL.String(),
+				strings.Join(header.Values("Authorization"), ","), strings.Join(header.Values("Cookie"), ";"),
+			}, "\x00")
+		},
+	}
+}
+
+func CoalesceKey(key func(*Context) string) func(*CoalesceSettings) {
+	return func(cs *CoalesceSettings) {
+		cs.Key = key
+	}
+}
+
+type coalescedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+type coalescedCall struct {
+	wg       sync.WaitGroup
+	response *coalescedResponse
+}
+
+// Wrap handler so that concurrent identical GET and HEAD requests share one execution of it. The request
+// that arrives first runs handler and the others wait for it and receive a copy of its status, body and the
+// headers set by handler, except Set-Cookie. Headers set by middleware are not shared. Other methods are passed
+// straight to handler.
+//
+//	router.Path("/reports/:id").Get(gyr.Coalesce(expensiveReport))
+func Coalesce(handler Handler, settings ...SettingsFunc[CoalesceSettings]) Handler {
+	coalesceSettings := DefaultCoalesceSettings()
+	for _, setting := range settings {
+		setting(&coalesceSettings)
+	}
+
+	var mx sync.Mutex
+	calls := make(map[string]*coalescedCall)
+	return func(ctx *Context) *Response {
+		if ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead {
+			return handler(ctx)
+		}
+
+		key := coalesceSettings.Key(ctx)
+		mx.Lock()
+		if call, running := calls[key]; running {
+			mx.Unlock()
+			call.wg.Wait()
+			return call.response.replay(ctx)
+		}
+		call := &coalescedCall{}
+		call.wg.Add(1)
+		calls[key] = call
+		mx.Unlock()
+
+		defer func() {
+			mx.Lock()
+			delete(calls, key)
+			mx.Unlock()
+			call.wg.Done()
+		}()
+
+		before := ctx.writer.Header().Clone()
+		response := handler(ctx)
+		if response == nil {
+			response = NewResponse(ctx)
+		}
+		call.response = &coalescedResponse{
+			status: response.status,
+			header: handlerHeaders(before, response.w.Header()),
+			body:   response.toWrite,
+		}
+		return response
+	}
+}
+
+// A response for ctx with the status, headers and body of the shared response.
+func (cr *coalescedResponse) replay(ctx *Context) *Response {
+	response := ctx.Response()
+	if cr == nil {
+		// The handler panicked while the request was waiting.
+		return response.InternalError().Text("Internal Server Error")
+	}
+	for name, values := range cr.header {
+		ctx.writer.Header()[name] = append([]string(nil), values...)
+	}
+	response.status = cr.status
+	response.toWrite = cr.body
+	return response
+}
+
+// The headers in after that the handler added or changed since before, leaving out Set-Cookie.
+func handlerHeaders(before http.Header, after http.Header) http.Header {
+	header := make(http.Header)
+	for name, values := range after {
+		if name == "Set-Cookie" || slices.Equal(before[name], values) {
+			continue
+		}
+		header[name] = append([]string(nil), values...)
+	}
+	return header
+}
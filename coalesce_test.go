@@ -0,0 +1,109 @@
+package gyr_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestCoalesce(t *testing.T) {
+	var executions atomic.Int32
+	release := make(chan struct{})
+	router := defaultTestRouter()
+	router.Path("/report").Get(gyr.Coalesce(func(ctx *gyr.Context) *gyr.Response {
+		executions.Add(1)
+		<-release
+		return ctx.Response().Header("X-Report", "1").Text("report")
+	}))
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 5)
+	headers := make([]string, 5)
+	for i := range bodies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			request, _ := http.NewRequest(http.MethodGet, "/report", nil)
+			response := sendRequest(router, request)
+			bodies[i] = response.Body.String()
+			headers[i] = response.Header().Get("X-Report")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if executions.Load() != 1 {
+		t.Logf("Expected a single handler execution. Received %d\n", executions.Load())
+		t.FailNow()
+	}
+	for i := range bodies {
+		if bodies[i] != "report" || headers[i] != "1" {
+			t.Logf("Expected every request to get the shared response. Received %q %q\n", bodies[i], headers[i])
+			t.FailNow()
+		}
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, "/report", nil)
+	sendRequest(router, request)
+	if executions.Load() != 2 {
+		t.Logf("Expected a new execution once the first finished. Received %d\n", executions.Load())
+		t.FailNow()
+	}
+}
+
+func TestCoalesceKeepsCallersApart(t *testing.T) {
+	var executions atomic.Int32
+	release := make(chan struct{})
+	router := defaultTestRouter()
+	router.Middleware(func(ctx *gyr.Context) *gyr.Response {
+		cookie, _ := ctx.Request.Cookie("user")
+		ctx.Response().Header("X-Middleware", cookie.Value).Header("Set-Cookie", "seen="+cookie.Value)
+		return nil
+	})
+	router.Path("/me").Get(gyr.Coalesce(func(ctx *gyr.Context) *gyr.Response {
+		executions.Add(1)
+		<-release
+		cookie, _ := ctx.Request.Cookie("user")
+		return ctx.Response().Header("Set-Cookie", "handler="+cookie.Value).Text(cookie.Value)
+	}))
+
+	users := []string{"alice", "bob", "alice"}
+	responses := make([]*httptest.ResponseRecorder, len(users))
+	var wg sync.WaitGroup
+	for i, user := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			request, _ := http.NewRequest(http.MethodGet, "/me", nil)
+			request.AddCookie(&http.Cookie{Name: "user", Value: user})
+			responses[i] = sendRequest(router, request)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if executions.Load() != 2 {
+		t.Logf("Expected one execution per user. Received %d\n", executions.Load())
+		t.FailNow()
+	}
+	for i, user := range users {
+		response := responses[i]
+		if response.Body.String() != user || response.Header().Get("X-Middleware") != user {
+			t.Logf("Expected the response of %s. Received %q %q\n", user, response.Body.String(), response.Header().Get("X-Middleware"))
+			t.FailNow()
+		}
+		for _, cookie := range response.Result().Cookies() {
+			if cookie.Value != user {
+				t.Logf("Expected only cookies of %s. Received %v\n", user, cookie)
+				t.FailNow()
+			}
+		}
+	}
+}
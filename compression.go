@@ -0,0 +1,149 @@
+package gyr
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type CompressionSettings struct {
+	// Bodies smaller than this many bytes are sent uncompressed.
+	MinSize int
+	// Compression level from 1 (fastest) to 9 (smallest), or -1 for the default of the encoding.
+	Level int
+	// Content types that are already compressed, matched by prefix, e.g. "image/".
+	SkipTypes []string
+}
+
+func DefaultCompressionSettings() CompressionSettings {
+	return CompressionSettings{
+		MinSize: 1024,
+		Level:   gzip.DefaultCompression,
+		SkipTypes: []string{
+			"image/", "video/", "audio/", "font/woff",
+			"application/zip", "application/gzip", "application/x-gzip", "application/pdf", "application/octet-stream",
+		},
+	}
+}
+
+func CompressionMinSize(size int) func(*CompressionSettings) {
+	return func(cs *CompressionSettings) {
+		cs.MinSize = size
+	}
+}
+
+func CompressionLevel(level int) func(*CompressionSettings) {
+	return func(cs *CompressionSettings) {
+		cs.Level = level
+	}
+}
+
+// Compress responses with gzip or deflate, whichever the Accept-Encoding header of the request prefers.
+// Already compressed content types, small bodies, streams and responses with a Content-Encoding are sent as
+// they are. The body is compressed as the response is sent, so middleware and cleanups see it uncompressed.
+func Compress(settings ...SettingsFunc[CompressionSettings]) Handler {
+	compressionSettings := DefaultCompressionSettings()
+	for _, setting := range settings {
+		setting(&compressionSettings)
+	}
+	compressor := &compressor{
+		settings: compressionSettings,
+		gzipWriters: NewPool(func() *gzip.Writer {
+			writer, err := gzip.NewWriterLevel(io.Discard, compressionSettings.Level)
+			if err != nil {
+				writer = gzip.NewWriter(io.Discard)
+			}
+			return writer
+		}, nil),
+		flateWriters: NewPool(func() *flate.Writer {
+			writer, err := flate.NewWriter(io.Discard, compressionSettings.Level)
+			if err != nil {
+				writer, _ = flate.NewWriter(io.Discard, flate.DefaultCompression)
+			}
+			return writer
+		}, nil),
+	}
+	return func(ctx *Context) *Response {
+		ctx.compressor = compressor
+		return nil
+	}
+}
+
+type compressor struct {
+	settings     CompressionSettings
+	gzipWriters  *Pool[*gzip.Writer]
+	flateWriters *Pool[*flate.Writer]
+}
+
+// The body to send for response, compressed if the request accepts it, with release returning its buffer.
+// Sets the Content-Type before compressing so it isn't sniffed from the compressed body.
+func (compressor *compressor) encode(request *http.Request, header http.Header, status int, body []byte) ([]byte, func()) {
+	noop := func() {}
+	if status < 200 || status == http.StatusNoContent || status == http.StatusNotModified || header.Get("Content-Encoding") != "" {
+		return body, noop
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" && len(body) > 0 {
+		contentType = http.DetectContentType(body)
+		header.Set("Content-Type", contentType)
+	}
+	if slices.ContainsFunc(compressor.settings.SkipTypes, func(skip string) bool {
+		return strings.HasPrefix(contentType, skip)
+	}) {
+		return body, noop
+	}
+	header.Add("Vary", "Accept-Encoding")
+	encoding := negotiateEncoding(request.Header.Get("Accept-Encoding"))
+	if encoding == "" || len(body) < compressor.settings.MinSize {
+		return body, noop
+	}
+
+	buf := bufferPool.Get()
+	switch encoding {
+	case "gzip":
+		writer := compressor.gzipWriters.Get()
+		writer.Reset(buf)
+		writer.Write(body)
+		writer.Close()
+		compressor.gzipWriters.Put(writer)
+	case "deflate":
+		writer := compressor.flateWriters.Get()
+		writer.Reset(buf)
+		writer.Write(body)
+		writer.Close()
+		compressor.flateWriters.Put(writer)
+	}
+	header.Set("Content-Encoding", encoding)
+	header.Set("Content-Length", strconv.Itoa(buf.Len()))
+	return buf.Bytes(), func() { putBuffer(buf) }
+}
+
+// The supported encoding with the highest quality in accept, preferring gzip on ties, or an empty string if
+// neither is acceptable.
+func negotiateEncoding(accept string) string {
+	best, bestQuality := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		quality := 1.0
+		if value, hasQuality := strings.CutPrefix(strings.TrimSpace(params), "q="); hasQuality {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if coding == "*" && !strings.Contains(strings.ToLower(accept), "gzip") {
+			coding = "gzip"
+		}
+		if (coding != "gzip" && coding != "deflate") || quality <= 0 {
+			continue
+		}
+		if quality > bestQuality || (quality == bestQuality && coding == "gzip") {
+			best, bestQuality = coding, quality
+		}
+	}
+	return best
+}
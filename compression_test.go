@@ -0,0 +1,61 @@
+package gyr_test
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestCompress(t *testing.T) {
+	router := defaultTestRouter()
+	router.Middleware(gyr.Compress())
+	large := strings.Repeat("gyr ", 1000)
+	router.Path("/large").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text(large)
+	})
+	router.Path("/small").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text("small")
+	})
+	router.Path("/image").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Header("Content-Type", "image/png").Raw(large)
+	})
+
+	response := gyrtest.Get("/large").Header("Accept-Encoding", "deflate;q=0.5, gzip").Send(router).
+		AssertStatus(t, http.StatusOK).
+		AssertHeader(t, "Content-Encoding", "gzip").
+		AssertHeader(t, "Content-Type", "text/plain").
+		AssertHeader(t, "Vary", "Accept-Encoding")
+	if response.Header().Get("Content-Length") != strconv.Itoa(response.Body.Len()) {
+		t.Logf("Expected Content-Length of the compressed body. Received %s for %d bytes\n", response.Header().Get("Content-Length"), response.Body.Len())
+		t.FailNow()
+	}
+	reader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		t.Logf("Body is not gzip: %v\n", err)
+		t.FailNow()
+	}
+	if body, _ := io.ReadAll(reader); string(body) != large {
+		t.Logf("Expected the decompressed body to match. Received %d bytes\n", len(body))
+		t.FailNow()
+	}
+
+	response = gyrtest.Get("/large").Header("Accept-Encoding", "gzip;q=0, deflate").Send(router).
+		AssertHeader(t, "Content-Encoding", "deflate")
+	if body, _ := io.ReadAll(flate.NewReader(response.Body)); string(body) != large {
+		t.Logf("Expected the inflated body to match. Received %d bytes\n", len(body))
+		t.FailNow()
+	}
+
+	gyrtest.NewRequest(http.MethodHead, "/large").Header("Accept-Encoding", "gzip").Send(router).
+		AssertHeader(t, "Content-Encoding", "gzip")
+	gyrtest.Get("/large").Send(router).AssertHeader(t, "Content-Encoding", "")
+	gyrtest.Get("/small").Header("Accept-Encoding", "gzip").Send(router).AssertHeader(t, "Content-Encoding", "")
+	gyrtest.Get("/image").Header("Accept-Encoding", "gzip").Send(router).AssertHeader(t, "Content-Encoding", "")
+}
@@ -0,0 +1,46 @@
+package gyr
+
+import (
+	"slices"
+	"strings"
+)
+
+// Run middleware only for requests matching predicate.
+//
+//	router.Middleware(gyr.When(func(ctx *gyr.Context) bool { return ctx.Request.TLS == nil }, redirectToHTTPS))
+func When(predicate func(*Context) bool, middleware ...Handler) Handler {
+	return func(ctx *Context) *Response {
+		if !predicate(ctx) {
+			return nil
+		}
+		if rest := ctx.next; rest != nil {
+			return runChain(middleware, func(*Context) *Response { return rest() }, ctx)
+		}
+		return runMiddlewares(middleware, ctx)
+	}
+}
+
+// Skip the middleware passed to the returned function for requests whose path starts with one of
+// pathPrefixes, such as health checks and static files.
+//
+//	router.Middleware(gyr.Unless("/health", "/static")(gyr.Authorize()))
+func Unless(pathPrefixes ...string) func(middleware ...Handler) Handler {
+	return func(middleware ...Handler) Handler {
+		return When(func(ctx *Context) bool {
+			return !slices.ContainsFunc(pathPrefixes, func(prefix string) bool {
+				return strings.HasPrefix(ctx.Request.URL.Path, prefix)
+			})
+		}, middleware...)
+	}
+}
+
+// Run the middleware passed to the returned function only for requests with one of methods.
+//
+//	router.Middleware(gyr.OnlyMethods("POST", "PUT", "PATCH", "DELETE")(csrfCheck))
+func OnlyMethods(methods ...string) func(middleware ...Handler) Handler {
+	return func(middleware ...Handler) Handler {
+		return When(func(ctx *Context) bool {
+			return slices.Contains(methods, ctx.Request.Method)
+		}, middleware...)
+	}
+}
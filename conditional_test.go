@@ -0,0 +1,25 @@
+package gyr_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestConditionalMiddleware(t *testing.T) {
+	deny := func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Status(http.StatusForbidden) }
+	ok := func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Text("ok") }
+
+	router := gyr.DefaultRouter()
+	router.Middleware(gyr.Unless("/health", "/static")(gyr.OnlyMethods(http.MethodPost)(deny)))
+	router.Middleware(gyr.When(func(ctx *gyr.Context) bool { return ctx.Request.Header.Get("X-Block") != "" }, deny))
+	router.Path("/health").Post(ok)
+	router.Path("/items").Get(ok).Post(ok)
+
+	gyrtest.Post("/health").Send(router).AssertStatus(t, http.StatusOK)
+	gyrtest.Get("/items").Send(router).AssertStatus(t, http.StatusOK)
+	gyrtest.Post("/items").Send(router).AssertStatus(t, http.StatusForbidden)
+	gyrtest.Get("/items").Header("X-Block", "1").Send(router).AssertStatus(t, http.StatusForbidden)
+}
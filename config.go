@@ -0,0 +1,402 @@
+package gyr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Returned by [Config.Bind] when a value can't be converted to the type of its field.
+var ErrInvalidConfigValue = errors.New("invalid config value")
+
+// Decodes the content of a config file into a nested map.
+type ConfigDecoder func([]byte) (map[string]any, error)
+
+type ConfigSettings struct {
+	Defaults map[string]any
+	// Config file to read. Skipped if it doesn't exist. The decoder is chosen by the file extension.
+	File string
+	// Prefix of environment variables overriding config values. With the prefix APP the key db.host is
+	// overridden by APP_DB_HOST.
+	EnvPrefix string
+	// Env files loaded into the process environment with [LoadEnvironmentFiles] before reading it. Nil loads the
+	// default files from [EnvironmentFiles].
+	EnvFiles []string
+	// Decoders by file extension. JSON is supported out of the box, decoders for other formats such as
+	// TOML or YAML can be added with [ConfigFormat].
+	Decoders map[string]ConfigDecoder
+}
+
+func DefaultConfigSettings() ConfigSettings {
+	return ConfigSettings{
+		Defaults: make(map[string]any),
+		File:     "config.json",
+		Decoders: map[string]ConfigDecoder{
+			".json": func(content []byte) (map[string]any, error) {
+				values := make(map[string]any)
+				err := json.Unmarshal(content, &values)
+				return values, err
+			},
+		},
+	}
+}
+
+func ConfigDefaults(defaults map[string]any) func(*ConfigSettings) {
+	return func(cs *ConfigSettings) {
+		for key, value := range defaults {
+			cs.Defaults[key] = value
+		}
+	}
+}
+
+func ConfigFile(path string) func(*ConfigSettings) {
+	return func(cs *ConfigSettings) {
+		cs.File = path
+	}
+}
+
+func ConfigEnvPrefix(prefix string) func(*ConfigSettings) {
+	return func(cs *ConfigSettings) {
+		cs.EnvPrefix = prefix
+	}
+}
+
+func ConfigEnvFiles(files ...string) func(*ConfigSettings) {
+	return func(cs *ConfigSettings) {
+		cs.EnvFiles = files
+	}
+}
+
+// Register a decoder for config files with the extension, e.g. ".yaml".
+func ConfigFormat(extension string, decoder ConfigDecoder) func(*ConfigSettings) {
+	return func(cs *ConfigSettings) {
+		cs.Decoders[extension] = decoder
+	}
+}
+
+// Configuration loaded from defaults, then a config file, then environment variables, with later sources
+// taking precedence. Keys are dot separated paths into the config file, e.g. "db.host".
+type Config struct {
+	mx     sync.RWMutex
+	values map[string]any
+	// Values from Set, checked before the environment.
+	overrides map[string]any
+	envPrefix string
+}
+
+// Load the config from the sources in settings. The env files in EnvFiles, by default those from
+// [EnvironmentFiles], are loaded into the process environment, which stays set for the rest of the process
+// just as with [LoadEnvironmentFiles]. Pass [ConfigEnvFiles] with the files to load, if any, to change that.
+func LoadConfig(settings ...SettingsFunc[ConfigSettings]) (*Config, error) {
+	configSettings := DefaultConfigSettings()
+	for _, setting := range settings {
+		setting(&configSettings)
+	}
+
+	config := &Config{
+		values:    make(map[string]any),
+		overrides: make(map[string]any),
+		envPrefix: configSettings.EnvPrefix,
+	}
+	flattenConfig("", configSettings.Defaults, config.values)
+
+	if configSettings.File != "" {
+		content, err := os.ReadFile(configSettings.File)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			extension := filepath.Ext(configSettings.File)
+			decoder, ok := configSettings.Decoders[extension]
+			if !ok {
+				return nil, fmt.Errorf("no config decoder for %s files", extension)
+			}
+			fileValues, err := decoder(content)
+			if err != nil {
+				return nil, fmt.Errorf("decoding %s: %w", configSettings.File, err)
+			}
+			flattenConfig("", fileValues, config.values)
+		}
+	}
+
+	if err := LoadEnvironmentFiles(configSettings.EnvFiles...); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func flattenConfig(prefix string, nested map[string]any, flat map[string]any) {
+	for key, value := range nested {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if child, isMap := value.(map[string]any); isMap {
+			flattenConfig(key, child, flat)
+			continue
+		}
+		flat[key] = value
+	}
+}
+
+// Name of the environment variable overriding key.
+func (config *Config) envName(key string) string {
+	name := strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+	if config.envPrefix != "" {
+		name = config.envPrefix + "_" + name
+	}
+	return name
+}
+
+// Set a value, taking precedence over every source.
+func (config *Config) Set(key string, value any) {
+	config.mx.Lock()
+	defer config.mx.Unlock()
+	config.overrides[key] = value
+}
+
+// Get the value for key, or nil if it isn't set in any source.
+func (config *Config) Get(key string) any {
+	config.mx.RLock()
+	defer config.mx.RUnlock()
+	if value, isSet := config.overrides[key]; isSet {
+		return value
+	}
+	if value, isSet := os.LookupEnv(config.envName(key)); isSet {
+		return value
+	}
+	return config.values[key]
+}
+
+func (config *Config) Has(key string) bool {
+	return config.Get(key) != nil
+}
+
+func (config *Config) String(key string) string {
+	switch value := config.Get(key).(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// Get the value as an int. Returns 0 if the value is missing or not a number.
+func (config *Config) Int(key string) int {
+	number, _ := config.parseInt(key)
+	return number
+}
+
+// Get the value as a float64. Returns 0 if the value is missing or not a number.
+func (config *Config) Float(key string) float64 {
+	number, _ := config.parseFloat(key)
+	return number
+}
+
+func (config *Config) Bool(key string) bool {
+	boolean, _ := config.parseBool(key)
+	return boolean
+}
+
+// Get the value as a duration. Strings are parsed with [time.ParseDuration] and numbers are seconds.
+func (config *Config) Duration(key string) time.Duration {
+	duration, _ := config.parseDuration(key)
+	return duration
+}
+
+func (config *Config) parseInt(key string) (int, error) {
+	switch value := config.Get(key).(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return value, nil
+	case float64:
+		return int(value), nil
+	case string:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, invalidConfigValue(key, value, "an int")
+		}
+		return number, nil
+	default:
+		return 0, invalidConfigValue(key, value, "an int")
+	}
+}
+
+func (config *Config) parseFloat(key string) (float64, error) {
+	switch value := config.Get(key).(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	case string:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, invalidConfigValue(key, value, "a number")
+		}
+		return number, nil
+	default:
+		return 0, invalidConfigValue(key, value, "a number")
+	}
+}
+
+func (config *Config) parseBool(key string) (bool, error) {
+	switch value := config.Get(key).(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return value, nil
+	case string:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, invalidConfigValue(key, value, "a bool")
+		}
+		return boolean, nil
+	default:
+		return false, invalidConfigValue(key, value, "a bool")
+	}
+}
+
+func (config *Config) parseDuration(key string) (time.Duration, error) {
+	switch value := config.Get(key).(type) {
+	case nil:
+		return 0, nil
+	case time.Duration:
+		return value, nil
+	case string:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, invalidConfigValue(key, value, "a duration")
+		}
+		return duration, nil
+	case int, float64:
+		seconds, err := config.parseFloat(key)
+		return time.Duration(seconds * float64(time.Second)), err
+	default:
+		return 0, invalidConfigValue(key, value, "a duration")
+	}
+}
+
+func invalidConfigValue(key string, value any, expected string) error {
+	return fmt.Errorf("%w: %s is %q, expected %s", ErrInvalidConfigValue, key, fmt.Sprint(value), expected)
+}
+
+// Fill the fields of the struct pointed to by target that have a config tag, e.g. `config:"db.host"`.
+// Tagged struct fields are bound recursively with the tag as key prefix. Values that can't be converted to the
+// type of their field return an error wrapping [ErrInvalidConfigValue]. The bound struct is checked with [Validate].
+func (config *Config) Bind(target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config can only be bound to a struct pointer, received %T", target)
+	}
+	if err := config.bindStruct("", value.Elem()); err != nil {
+		return err
+	}
+	return Validate(target)
+}
+
+func (config *Config) bindStruct(prefix string, target reflect.Value) error {
+	targetType := target.Type()
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		key, hasTag := field.Tag.Lookup("config")
+		if !hasTag || !field.IsExported() {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		fieldValue := target.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeFor[time.Time]() {
+			if err := config.bindStruct(key, fieldValue); err != nil {
+				return err
+			}
+			continue
+		}
+		if !config.Has(key) {
+			continue
+		}
+		if err := config.bindField(key, fieldValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (config *Config) bindField(key string, field reflect.Value) error {
+	if field.Type() == reflect.TypeFor[time.Duration]() {
+		duration, err := config.parseDuration(key)
+		field.SetInt(int64(duration))
+		return err
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(config.String(key))
+	case reflect.Bool:
+		boolean, err := config.parseBool(key)
+		if err != nil {
+			return err
+		}
+		field.SetBool(boolean)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := config.parseInt(key)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(number))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := config.parseInt(key)
+		if err != nil {
+			return err
+		}
+		field.SetUint(uint64(number))
+	case reflect.Float32, reflect.Float64:
+		number, err := config.parseFloat(key)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(number)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported config field type %s for %s", field.Type(), key)
+		}
+		field.Set(reflect.ValueOf(config.Strings(key)))
+	default:
+		return fmt.Errorf("unsupported config field type %s for %s", field.Type(), key)
+	}
+	return nil
+}
+
+// Get the value as a list of strings. Strings, e.g. from the environment, are split on commas.
+func (config *Config) Strings(key string) []string {
+	switch value := config.Get(key).(type) {
+	case []string:
+		return value
+	case []any:
+		strs := make([]string, len(value))
+		for i, element := range value {
+			strs[i] = fmt.Sprint(element)
+		}
+		return strs
+	case string:
+		parts := strings.Split(value, ",")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		return parts
+	default:
+		return nil
+	}
+}
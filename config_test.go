@@ -0,0 +1,100 @@
+package gyr_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+func loadTestConfig(t *testing.T) *gyr.Config {
+	config, err := gyr.LoadConfig(
+		gyr.ConfigDefaults(map[string]any{"port": 80, "name": "default-name", "db": map[string]any{"user": "root"}}),
+		gyr.ConfigFile("test_files/config.json"),
+		gyr.ConfigEnvPrefix("GYRTEST"),
+		gyr.ConfigEnvFiles("test_files/does-not-exist.env"),
+	)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	return config
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	t.Setenv("GYRTEST_DB_HOST", "env-host")
+	config := loadTestConfig(t)
+
+	expectations := map[string]string{
+		"name":    "default-name",
+		"db.user": "root",
+		"port":    "8080",
+		"db.host": "env-host",
+	}
+	for key, expected := range expectations {
+		if received := config.String(key); received != expected {
+			t.Logf("Expected %s to be %s. Received %s\n", key, expected, received)
+			t.Fail()
+		}
+	}
+}
+
+func TestConfigSetOverridesEnvironment(t *testing.T) {
+	t.Setenv("GYRTEST_DB_HOST", "env-host")
+	config := loadTestConfig(t)
+	config.Set("db.host", "set-host")
+	if received := config.String("db.host"); received != "set-host" {
+		t.Logf("Expected the value from Set. Received %s\n", received)
+		t.FailNow()
+	}
+}
+
+func TestConfigTypedAccessors(t *testing.T) {
+	config := loadTestConfig(t)
+	if config.Int("port") != 8080 || !config.Bool("debug") || config.Duration("db.timeout") != 5*time.Second {
+		t.Logf("Received port %d, debug %v, timeout %v\n", config.Int("port"), config.Bool("debug"), config.Duration("db.timeout"))
+		t.FailNow()
+	}
+}
+
+func TestConfigBind(t *testing.T) {
+	t.Setenv("GYRTEST_PORT", "9090")
+	type database struct {
+		Host    string        `config:"host"`
+		Timeout time.Duration `config:"timeout"`
+	}
+	var target struct {
+		Port    int      `config:"port"`
+		Debug   bool     `config:"debug"`
+		Origins []string `config:"origins"`
+		DB      database `config:"db"`
+		Skipped string
+	}
+	if err := loadTestConfig(t).Bind(&target); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if target.Port != 9090 || !target.Debug || target.DB.Host != "db.internal" || target.DB.Timeout != 5*time.Second {
+		t.Logf("Received %+v\n", target)
+		t.FailNow()
+	}
+	if !slices.Equal(target.Origins, []string{"https://a.example", "https://b.example"}) {
+		t.Logf("Received %+v\n", target.Origins)
+		t.FailNow()
+	}
+}
+
+func TestConfigBindInvalidValue(t *testing.T) {
+	t.Setenv("GYRTEST_PORT", "80a")
+	var target struct {
+		Port int `config:"port"`
+	}
+	err := loadTestConfig(t).Bind(&target)
+	if !errors.Is(err, gyr.ErrInvalidConfigValue) || !strings.Contains(err.Error(), "port") || !strings.Contains(err.Error(), `"80a"`) {
+		t.Logf("Expected an error naming port and 80a. Received %v\n", err)
+		t.FailNow()
+	}
+}
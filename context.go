@@ -1,11 +1,15 @@
 package gyr
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type Context struct {
@@ -13,6 +17,25 @@ type Context struct {
 	FallbackDecoder BodyDecoder
 	writer          http.ResponseWriter
 	variables       map[string]any
+	app             *App
+	translator      *Translator
+	locale          string
+	// Run after the response has been sent.
+	cleanups  []func()
+	route     *Route
+	principal *Principal
+	// The response being sent, available to cleanups.
+	response *Response
+	// Runs the tasks scheduled with Defer, nil outside of a router.
+	background *BackgroundPool
+	// Tracks the streams opened with Stream, nil outside of a router.
+	streams *streamRegistry
+	// Set by the Compress middleware.
+	compressor *compressor
+	// Set by the Sessions middleware.
+	session *Session
+	// Runs the rest of the middleware chain and the handler, for Wrap.
+	next func() *Response
 }
 
 type BodyDecoder interface {
@@ -31,6 +54,88 @@ func (ctx *Context) Response() *Response {
 	return NewResponse(ctx)
 }
 
+// Send an informational 1xx response, such as 102 Processing or 103 Early Hints, before the final response.
+// The headers are sent with it and kept for the final response.
+func (ctx *Context) Informational(status int, header http.Header) error {
+	if status < 100 || status > 199 || status == http.StatusSwitchingProtocols {
+		return fmt.Errorf("%d is not an informational status", status)
+	}
+	for name, values := range header {
+		for _, value := range values {
+			ctx.writer.Header().Add(name, value)
+		}
+	}
+	ctx.writer.WriteHeader(status)
+	return nil
+}
+
+// Send 103 Early Hints with a Link header for each of links, such as "</app.css>; rel=preload; as=style",
+// so the client can start loading them while the response is prepared.
+func (ctx *Context) EarlyHints(links ...string) error {
+	return ctx.Informational(http.StatusEarlyHints, http.Header{"Link": links})
+}
+
+// The context of the request, canceled when the client disconnects. Pass it to database calls and outgoing
+// requests so they stop when the response is no longer wanted.
+func (ctx *Context) Context() context.Context {
+	return ctx.Request.Context()
+}
+
+// Replace the context of the request with c, which should be derived from [Context.Context]. Later middleware
+// and the handler see c.
+func (ctx *Context) SetContext(c context.Context) {
+	ctx.Request = ctx.Request.WithContext(c)
+}
+
+// Add a value to the context of the request, readable with ctx.Context().Value(key).
+func (ctx *Context) WithValue(key any, value any) {
+	ctx.SetContext(context.WithValue(ctx.Context(), key, value))
+}
+
+// Give the rest of the request a deadline of timeout from now. The context is canceled when the deadline passes
+// or the response has been sent.
+//
+//	router.Middleware(func(ctx *gyr.Context) *gyr.Response {
+//		ctx.WithTimeout(5 * time.Second)
+//		return nil
+//	})
+func (ctx *Context) WithTimeout(timeout time.Duration) {
+	c, cancel := context.WithTimeout(ctx.Context(), timeout)
+	ctx.SetContext(c)
+	ctx.onDone(cancel)
+}
+
+// Logger with the method and path of the request, see [LoggerFrom].
+func (ctx *Context) Logger() *slog.Logger {
+	return LoggerFrom(ctx.Context())
+}
+
+// The route matching the request, or nil if no route matched.
+func (ctx *Context) Route() *Route {
+	return ctx.route
+}
+
+// Get the value set with [Route.Set] on the route matching the request. Reports false if there is no
+// such value or it isn't a T.
+func RouteMeta[T any](ctx *Context, key string) (T, bool) {
+	var value T
+	if ctx.route == nil {
+		return value, false
+	}
+	value, ok := ctx.route.meta[key].(T)
+	return value, ok
+}
+
+func (ctx *Context) onDone(cleanup func()) {
+	ctx.cleanups = append(ctx.cleanups, cleanup)
+}
+
+func (ctx *Context) runCleanups() {
+	for i := len(ctx.cleanups) - 1; i >= 0; i-- {
+		ctx.cleanups[i]()
+	}
+}
+
 func (ctx *Context) SetVariable(key string, value any) {
 	ctx.variables[key] = value
 }
@@ -55,6 +160,7 @@ func (ctx *Context) StringVariable(key string) string {
 	return ctx.Variable(key).(string)
 }
 
+// Decode the request body into T based on the Content-Type header and check its validate tags with [Validate].
 func ReadBody[T any](ctx *Context) (T, error) {
 	var target T
 	var decoder BodyDecoder
@@ -72,8 +178,10 @@ func ReadBody[T any](ctx *Context) (T, error) {
 			return target, errors.New("can not determine decoder to use from Content-Type header and no fallback set")
 		}
 	}
-	err := decoder.Decode(&target)
-	return target, err
+	if err := decoder.Decode(&target); err != nil {
+		return target, err
+	}
+	return target, Validate(target)
 }
 
 type contentType struct {
@@ -0,0 +1,56 @@
+package gyr_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+type tenantKey struct{}
+
+func TestContextValues(t *testing.T) {
+	router := defaultTestRouter()
+	router.Middleware(func(ctx *gyr.Context) *gyr.Response {
+		ctx.WithValue(tenantKey{}, "acme")
+		ctx.WithTimeout(time.Minute)
+		return nil
+	})
+	var deadline time.Time
+	var hasDeadline bool
+	router.Path("/tenant").Get(func(ctx *gyr.Context) *gyr.Response {
+		deadline, hasDeadline = ctx.Context().Deadline()
+		return ctx.Response().Text(ctx.Context().Value(tenantKey{}).(string))
+	})
+
+	response := sendRequest(router, httptest.NewRequest(http.MethodGet, "/tenant", nil))
+	if response.Body.String() != "acme" {
+		t.Logf("Expected the value set by the middleware. Received %q", response.Body.String())
+		t.FailNow()
+	}
+	if !hasDeadline || time.Until(deadline) > time.Minute {
+		t.Logf("Expected a deadline within a minute. Received %v %v", deadline, hasDeadline)
+		t.FailNow()
+	}
+}
+
+func TestContextCanceledWithRequest(t *testing.T) {
+	router := defaultTestRouter()
+	var err error
+	router.Path("/slow").Get(func(ctx *gyr.Context) *gyr.Response {
+		<-ctx.Context().Done()
+		err = ctx.Context().Err()
+		return ctx.Response().NoContent()
+	})
+
+	requestCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sendRequest(router, httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(requestCtx))
+	if err != context.Canceled {
+		t.Logf("Expected the handler to see the client disconnect. Received %v", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,109 @@
+package gyr
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CORSOptions struct {
+	// Origins allowed to make requests, such as "https://example.com". "*" allows any origin and
+	// "https://*.example.com" any subdomain. Any origin is allowed when the [Profile] has RelaxedCORS.
+	AllowedOrigins []string
+	// Methods allowed in preflight requests, limited to those the route has handlers for. Empty allows every
+	// method of the route.
+	AllowedMethods []string
+	// Request headers allowed in preflight requests. Empty allows the headers the preflight asks for.
+	AllowedHeaders []string
+	// Response headers readable by the client besides the CORS-safelisted ones.
+	ExposedHeaders []string
+	// How long browsers may cache the result of a preflight request. 0 leaves it to the browser.
+	MaxAge time.Duration
+	// Allow cookies and authorization headers to be sent with requests.
+	Credentials bool
+}
+
+// Set the CORS headers for requests from allowed origins and answer preflight requests with the methods of
+// the matched route. Register it before any authentication middleware, since browsers send preflight requests
+// without credentials.
+//
+//	router.Middleware(gyr.CORS(gyr.CORSOptions{AllowedOrigins: []string{"https://app.example.com"}, Credentials: true}))
+func CORS(options CORSOptions) Handler {
+	return func(ctx *Context) *Response {
+		origin := ctx.Request.Header.Get("Origin")
+		header := ctx.writer.Header()
+		header.Add("Vary", "Origin")
+		if origin == "" || !options.allowsOrigin(origin) {
+			return nil
+		}
+
+		requestedMethod := ctx.Request.Header.Get("Access-Control-Request-Method")
+		if ctx.Request.Method != http.MethodOptions || requestedMethod == "" {
+			options.setOrigin(header, origin)
+			if len(options.ExposedHeaders) > 0 {
+				header.Set("Access-Control-Expose-Headers", strings.Join(options.ExposedHeaders, ", "))
+			}
+			return nil
+		}
+
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		methods := options.methodsOf(ctx.Route())
+		if !slices.Contains(methods, requestedMethod) {
+			return nil
+		}
+		options.setOrigin(header, origin)
+		header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		if len(options.AllowedHeaders) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(options.AllowedHeaders, ", "))
+		} else if requested := ctx.Request.Header.Get("Access-Control-Request-Headers"); requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+		}
+		if options.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(options.MaxAge.Seconds())))
+		}
+		return ctx.Response().NoContent()
+	}
+}
+
+func (options CORSOptions) allowsOrigin(origin string) bool {
+	if CurrentProfile().RelaxedCORS {
+		return true
+	}
+	return slices.ContainsFunc(options.AllowedOrigins, func(allowed string) bool {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		prefix, suffix, isWildcard := strings.Cut(allowed, "*")
+		return isWildcard && len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+	})
+}
+
+// Echo the origin unless any origin is allowed without credentials, since browsers reject "*" with credentials.
+func (options CORSOptions) setOrigin(header http.Header, origin string) {
+	if slices.Contains(options.AllowedOrigins, "*") && !options.Credentials && !CurrentProfile().RelaxedCORS {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if options.Credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// The methods of route allowed by the options.
+func (options CORSOptions) methodsOf(route *Route) []string {
+	if route == nil {
+		return options.AllowedMethods
+	}
+	methods := route.allowedMethods()
+	if len(options.AllowedMethods) > 0 {
+		methods = slices.DeleteFunc(methods, func(method string) bool {
+			return !slices.Contains(options.AllowedMethods, method)
+		})
+	}
+	return methods
+}
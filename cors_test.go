@@ -0,0 +1,66 @@
+package gyr_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestCORS(t *testing.T) {
+	gyr.SetProfile(gyr.ProfileProduction)
+	defer gyr.SetProfile(gyr.Profile{})
+	router := defaultTestRouter()
+	router.Middleware(gyr.CORS(gyr.CORSOptions{
+		AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		MaxAge:         time.Hour,
+		Credentials:    true,
+	}))
+	router.Middleware(func(ctx *gyr.Context) *gyr.Response {
+		if ctx.Request.Header.Get("Authorization") == "" {
+			return ctx.Response().Status(http.StatusUnauthorized)
+		}
+		return nil
+	})
+	router.Path("/items").Get(listUsers).Post(listUsers)
+
+	gyrtest.NewRequest(http.MethodOptions, "/items").
+		Header("Origin", "https://app.example.com").
+		Header("Access-Control-Request-Method", "POST").
+		Send(router).
+		AssertStatus(t, http.StatusNoContent).
+		AssertHeader(t, "Access-Control-Allow-Origin", "https://app.example.com").
+		AssertHeader(t, "Access-Control-Allow-Methods", "GET, HEAD, OPTIONS, POST").
+		AssertHeader(t, "Access-Control-Allow-Headers", "Content-Type, Authorization").
+		AssertHeader(t, "Access-Control-Allow-Credentials", "true").
+		AssertHeader(t, "Access-Control-Max-Age", "3600")
+
+	gyrtest.NewRequest(http.MethodOptions, "/items").
+		Header("Origin", "https://app.example.com").
+		Header("Access-Control-Request-Method", "DELETE").
+		Send(router).
+		AssertHeader(t, "Access-Control-Allow-Origin", "")
+
+	gyrtest.Get("/items").
+		Header("Origin", "https://shop.example.org").
+		Header("Authorization", "token").
+		Send(router).
+		AssertStatus(t, http.StatusNoContent).
+		AssertHeader(t, "Access-Control-Allow-Origin", "https://shop.example.org")
+
+	gyrtest.Get("/items").
+		Header("Origin", "https://evil.example.com").
+		Header("Authorization", "token").
+		Send(router).
+		AssertHeader(t, "Access-Control-Allow-Origin", "")
+
+	gyr.SetProfile(gyr.ProfileDevelopment)
+	gyrtest.Get("/items").
+		Header("Origin", "http://localhost:5173").
+		Header("Authorization", "token").
+		Send(router).
+		AssertHeader(t, "Access-Control-Allow-Origin", "http://localhost:5173")
+}
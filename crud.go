@@ -0,0 +1,107 @@
+package gyr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Anything routes can be added to, such as a [Router] or a [RouteGroup].
+type RouteRegistrar interface {
+	Path(path string) *Route
+}
+
+type CRUDSettings struct {
+	// Settings for parsing the filters, sorting and pagination of list requests.
+	ListQuery []SettingsFunc[ListQuerySettings]
+}
+
+func DefaultCRUDSettings() CRUDSettings {
+	return CRUDSettings{ListQuery: make([]SettingsFunc[ListQuerySettings], 0)}
+}
+
+func CRUDListQuery(settings ...SettingsFunc[ListQuerySettings]) func(*CRUDSettings) {
+	return func(cs *CRUDSettings) {
+		cs.ListQuery = append(cs.ListQuery, settings...)
+	}
+}
+
+// Body of the responses to list requests registered by [MountCRUD].
+type Page[EntityType any] struct {
+	Items    []EntityType `json:"items" fields:"items"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
+
+// Register routes for listing, reading, creating, updating and deleting entities through repo:
+//
+//	GET    /users      list, with the filters, sorting and pagination of [ParseListQuery]
+//	POST   /users      create from the validated body, 201
+//	GET    /users/:id  read, 404 if it doesn't exist
+//	PUT    /users/:id  replace with the validated body, 404 if it doesn't exist
+//	DELETE /users/:id  delete, 204
+//
+// Invalid input is reported with [Response.ValidationError] and other failures with [Response.Error].
+func MountCRUD[EntityType any](router RouteRegistrar, path string, repo *Repository[EntityType], settings ...SettingsFunc[CRUDSettings]) {
+	crudSettings := DefaultCRUDSettings()
+	for _, setting := range settings {
+		setting(&crudSettings)
+	}
+
+	router.Path(path).
+		Get(func(ctx *Context) *Response {
+			listQuery, err := ReadListQuery(ctx, crudSettings.ListQuery...)
+			if err != nil {
+				return ctx.Response().ValidationError(err)
+			}
+			entities, err := repo.FindAll(ctx.Context(), listQuery)
+			if err != nil {
+				return crudError(ctx, err)
+			}
+			return ctx.Response().Json(Page[EntityType]{Items: entities, Page: listQuery.Page, PageSize: listQuery.PageSize})
+		}).
+		Post(func(ctx *Context) *Response {
+			entity, err := ReadBody[EntityType](ctx)
+			if err != nil {
+				return ctx.Response().ValidationError(err)
+			}
+			if err := repo.Insert(ctx.Context(), &entity); err != nil {
+				return crudError(ctx, err)
+			}
+			return ctx.Response().Status(http.StatusCreated).Json(entity)
+		})
+
+	router.Path(path + "/:id").
+		Get(func(ctx *Context) *Response {
+			entity, err := repo.FindByID(ctx.Context(), ctx.Variable("id"))
+			if err != nil {
+				return crudError(ctx, err)
+			}
+			return ctx.Response().Json(entity)
+		}).
+		Put(func(ctx *Context) *Response {
+			entity, err := ReadBody[EntityType](ctx)
+			if err != nil {
+				return ctx.Response().ValidationError(err)
+			}
+			if err := repo.setID(&entity, ctx.Variable("id")); err != nil {
+				return ctx.Response().ValidationError(ValidationErrors{{Field: "id", Rule: "type", Message: err.Error(), Code: validationCode("type")}})
+			}
+			if err := repo.Update(ctx.Context(), entity); err != nil {
+				return crudError(ctx, err)
+			}
+			return ctx.Response().Json(entity)
+		}).
+		Delete(func(ctx *Context) *Response {
+			if err := repo.Delete(ctx.Context(), ctx.Variable("id")); err != nil {
+				return crudError(ctx, err)
+			}
+			return ctx.Response().NoContent()
+		})
+}
+
+func crudError(ctx *Context, err error) *Response {
+	if errors.Is(err, ErrEntityNotFound) {
+		return ctx.Response().Error(ErrorNotFound.Wrap(err))
+	}
+	return ctx.Response().Error(err)
+}
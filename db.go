@@ -0,0 +1,243 @@
+package gyr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNoDatabaseDriver = errors.New("no database driver configured")
+
+type DBSettings struct {
+	// Name of a driver registered with database/sql, e.g. "postgres" or "sqlite3".
+	Driver string
+	DSN    string
+	// Pool limits, see [sql.DB.SetMaxOpenConns] and friends. Zero keeps the database/sql default.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// How long each connection attempt may take.
+	ConnectTimeout time.Duration
+	// How connecting is retried while the database is unreachable, for example while it is starting.
+	Retry RetryPolicy
+	// Name of the check registered with [RegisterHealthCheck]. Empty registers no check.
+	HealthCheck string
+	// Statements run on every new connection, such as SQLite pragmas, see [DBSQLite].
+	ConnectStatements []string
+}
+
+func DefaultDBSettings() DBSettings {
+	return DBSettings{
+		ConnectTimeout: 5 * time.Second,
+		Retry: RetryPolicy{
+			MaxAttempts: 5,
+			Backoff:     500 * time.Millisecond,
+			MaxBackoff:  5 * time.Second,
+			Jitter:      0.2,
+		},
+		HealthCheck: "db",
+	}
+}
+
+// Read settings from config. The keys are db.driver, db.dsn, db.max_open_conns, db.max_idle_conns,
+// db.conn_max_lifetime, db.conn_max_idle_time, db.connect_timeout, db.sqlite_busy_timeout and db.sqlite_wal.
+// Keys that aren't set are left as they are.
+func DBConfig(config *Config) func(*DBSettings) {
+	return func(ds *DBSettings) {
+		if config.Has("db.driver") {
+			ds.Driver = config.String("db.driver")
+		}
+		if config.Has("db.dsn") {
+			ds.DSN = config.String("db.dsn")
+		}
+		if config.Has("db.max_open_conns") {
+			ds.MaxOpenConns = config.Int("db.max_open_conns")
+		}
+		if config.Has("db.max_idle_conns") {
+			ds.MaxIdleConns = config.Int("db.max_idle_conns")
+		}
+		if config.Has("db.conn_max_lifetime") {
+			ds.ConnMaxLifetime = config.Duration("db.conn_max_lifetime")
+		}
+		if config.Has("db.conn_max_idle_time") {
+			ds.ConnMaxIdleTime = config.Duration("db.conn_max_idle_time")
+		}
+		if config.Has("db.connect_timeout") {
+			ds.ConnectTimeout = config.Duration("db.connect_timeout")
+		}
+		if config.Has("db.sqlite_busy_timeout") || config.Has("db.sqlite_wal") {
+			DBSQLite(config.Duration("db.sqlite_busy_timeout"), config.Bool("db.sqlite_wal"))(ds)
+		}
+	}
+}
+
+func DBDriver(driver string, dsn string) func(*DBSettings) {
+	return func(ds *DBSettings) {
+		ds.Driver = driver
+		ds.DSN = dsn
+	}
+}
+
+func DBPool(maxOpen int, maxIdle int, maxLifetime time.Duration) func(*DBSettings) {
+	return func(ds *DBSettings) {
+		ds.MaxOpenConns = maxOpen
+		ds.MaxIdleConns = maxIdle
+		ds.ConnMaxLifetime = maxLifetime
+	}
+}
+
+func DBConnectRetry(policy RetryPolicy) func(*DBSettings) {
+	return func(ds *DBSettings) {
+		ds.Retry = policy
+	}
+}
+
+// Set the SQLite busy timeout, so writers wait for locks instead of failing with "database is locked", and
+// enable write-ahead logging, which lets readers continue while a write is in progress. A zero timeout and
+// false wal leave the SQLite defaults.
+func DBSQLite(busyTimeout time.Duration, wal bool) func(*DBSettings) {
+	return func(ds *DBSettings) {
+		if busyTimeout > 0 {
+			ds.ConnectStatements = append(ds.ConnectStatements, fmt.Sprintf("pragma busy_timeout = %d", busyTimeout.Milliseconds()))
+		}
+		if wal {
+			ds.ConnectStatements = append(ds.ConnectStatements, "pragma journal_mode = wal")
+		}
+	}
+}
+
+func DBHealthCheck(name string) func(*DBSettings) {
+	return func(ds *DBSettings) {
+		ds.HealthCheck = name
+	}
+}
+
+// Open a database and verify that it can be reached, retrying with the Retry policy of the settings. Settings
+// are read from the environment variables DB_DRIVER, DB_DSN, DB_MAX_OPEN_CONNS and so on, see [DBConfig], and
+// then from settings. The returned handle can be passed to [NewMigrator] and [NewRepository].
+//
+//	db, err := gyr.OpenDB()
+//	err = gyr.NewMigrator(db).Migrate()
+func OpenDB(settings ...SettingsFunc[DBSettings]) (*sql.DB, error) {
+	dbSettings := DefaultDBSettings()
+	DBConfig(&Config{values: make(map[string]any)})(&dbSettings)
+	for _, setting := range settings {
+		setting(&dbSettings)
+	}
+	if dbSettings.Driver == "" {
+		return nil, ErrNoDatabaseDriver
+	}
+
+	db, err := sql.Open(dbSettings.Driver, dbSettings.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if len(dbSettings.ConnectStatements) > 0 {
+		connector, err := newStatementConnector(db.Driver(), dbSettings.DSN, dbSettings.ConnectStatements)
+		db.Close()
+		if err != nil {
+			return nil, err
+		}
+		db = sql.OpenDB(connector)
+	}
+	if dbSettings.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(dbSettings.MaxOpenConns)
+	}
+	if dbSettings.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(dbSettings.MaxIdleConns)
+	}
+	if dbSettings.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(dbSettings.ConnMaxLifetime)
+	}
+	if dbSettings.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(dbSettings.ConnMaxIdleTime)
+	}
+
+	logger := Logger().With("component", "sql")
+	attempt := 0
+	err = Retry(context.Background(), dbSettings.Retry, func() error {
+		attempt++
+		pingCtx, cancel := context.WithTimeout(context.Background(), dbSettings.ConnectTimeout)
+		defer cancel()
+		err := db.PingContext(pingCtx)
+		if err != nil {
+			logger.Warn("Failed to connect to database", "driver", dbSettings.Driver, "attempt", attempt, "error", err)
+		}
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connecting to %s database: %w", dbSettings.Driver, err)
+	}
+	logger.Info("Connected to database", "driver", dbSettings.Driver)
+
+	if dbSettings.HealthCheck != "" {
+		RegisterHealthCheck(dbSettings.HealthCheck, db.PingContext)
+	}
+	return db, nil
+}
+
+// Runs statements on every connection it opens.
+type statementConnector struct {
+	driver.Connector
+	statements []string
+}
+
+func newStatementConnector(dbDriver driver.Driver, dsn string, statements []string) (*statementConnector, error) {
+	if withConnector, ok := dbDriver.(driver.DriverContext); ok {
+		connector, err := withConnector.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return &statementConnector{Connector: connector, statements: statements}, nil
+	}
+	return &statementConnector{Connector: dsnConnector{driver: dbDriver, dsn: dsn}, statements: statements}, nil
+}
+
+func (connector *statementConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := connector.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, statement := range connector.statements {
+		if err := execOnConn(ctx, conn, statement); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("running %q: %w", statement, err)
+		}
+	}
+	return conn, nil
+}
+
+func execOnConn(ctx context.Context, conn driver.Conn, statement string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, statement, nil)
+		if !errors.Is(err, driver.ErrSkip) {
+			return err
+		}
+	}
+	stmt, err := conn.Prepare(statement)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(nil)
+	return err
+}
+
+// Connector for drivers without one of their own.
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (connector dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return connector.driver.Open(connector.dsn)
+}
+
+func (connector dsnConnector) Driver() driver.Driver {
+	return connector.driver
+}
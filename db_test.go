@@ -0,0 +1,81 @@
+package gyr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenDB(t *testing.T) {
+	t.Setenv("DB_DRIVER", "gyr_fake")
+	t.Setenv("DB_MAX_OPEN_CONNS", "3")
+	db, err := OpenDB(DBHealthCheck("primary"))
+	if err != nil {
+		t.Logf("Failed to open database: %v\n", err)
+		t.FailNow()
+	}
+	defer db.Close()
+	defer UnregisterHealthCheck("primary")
+	if db.Stats().MaxOpenConnections != 3 {
+		t.Logf("Expected 3 max open connections, got %d\n", db.Stats().MaxOpenConnections)
+		t.FailNow()
+	}
+
+	router := DefaultRouter()
+	router.Path("/health").Get(HealthHandler())
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"primary":"ok"`) {
+		t.Logf("Unexpected health response %d %s\n", recorder.Code, recorder.Body.String())
+		t.FailNow()
+	}
+
+	t.Run("unknown driver", func(t *testing.T) {
+		_, err := OpenDB(DBDriver("missing", ""), DBConnectRetry(RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}))
+		if err == nil {
+			t.Log("Expected an error for an unregistered driver")
+			t.FailNow()
+		}
+	})
+
+	t.Run("no driver", func(t *testing.T) {
+		t.Setenv("DB_DRIVER", "")
+		if _, err := OpenDB(); !errors.Is(err, ErrNoDatabaseDriver) {
+			t.Logf("Expected ErrNoDatabaseDriver, got %v\n", err)
+			t.FailNow()
+		}
+	})
+}
+
+func TestOpenDBConnectStatements(t *testing.T) {
+	openFakeDB().Close()
+	db, err := OpenDB(DBDriver("gyr_fake", "app.db"), DBSQLite(5*time.Second, true), DBHealthCheck(""))
+	if err != nil {
+		t.Logf("Failed to open database: %v\n", err)
+		t.FailNow()
+	}
+	defer db.Close()
+	expected := []string{"pragma busy_timeout = 5000", "pragma journal_mode = wal"}
+	if executed := testDriver.executed(); !slices.Equal(executed, expected) {
+		t.Logf("Expected %v. Received %v\n", expected, executed)
+		t.FailNow()
+	}
+}
+
+func TestHealthHandlerReportsFailure(t *testing.T) {
+	RegisterHealthCheck("broken", func(ctx context.Context) error { return errors.New("down") })
+	defer UnregisterHealthCheck("broken")
+	router := DefaultRouter()
+	router.Path("/health").Get(HealthHandler())
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if recorder.Code != http.StatusServiceUnavailable || !strings.Contains(recorder.Body.String(), `"broken":"down"`) {
+		t.Logf("Unexpected health response %d %s\n", recorder.Code, recorder.Body.String())
+		t.FailNow()
+	}
+}
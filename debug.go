@@ -0,0 +1,275 @@
+package gyr
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type DebugRecorderSettings struct {
+	// Number of requests kept. Older requests are dropped.
+	Capacity int
+	// Bytes of each request and response body that are kept.
+	MaxBodySize int
+	// Where the inspection page is served. The recorded requests are available as JSON at Path/requests.
+	Path string
+}
+
+func DefaultDebugRecorderSettings() DebugRecorderSettings {
+	return DebugRecorderSettings{
+		Capacity:    100,
+		MaxBodySize: 64 * 1024,
+		Path:        "/debug",
+	}
+}
+
+func DebugCapacity(capacity int) func(*DebugRecorderSettings) {
+	return func(drs *DebugRecorderSettings) {
+		drs.Capacity = capacity
+	}
+}
+
+func DebugMaxBodySize(size int) func(*DebugRecorderSettings) {
+	return func(drs *DebugRecorderSettings) {
+		drs.MaxBodySize = size
+	}
+}
+
+func DebugPath(path string) func(*DebugRecorderSettings) {
+	return func(drs *DebugRecorderSettings) {
+		drs.Path = strings.TrimSuffix(path, "/")
+	}
+}
+
+type RecordedRequest struct {
+	ID              int           `json:"id"`
+	Time            time.Time     `json:"time"`
+	Method          string        `json:"method"`
+	URL             string        `json:"url"`
+	Route           string        `json:"route"`
+	RequestHeaders  http.Header   `json:"requestHeaders"`
+	RequestBody     string        `json:"requestBody"`
+	Status          int           `json:"status"`
+	ResponseHeaders http.Header   `json:"responseHeaders"`
+	ResponseBody    string        `json:"responseBody"`
+	Duration        time.Duration `json:"duration"`
+	Logs            []RecordedLog `json:"logs"`
+}
+
+// Log written with [Context.Logger] or [LoggerFrom] while handling a recorded request.
+type RecordedLog struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Attrs   map[string]string `json:"attrs"`
+}
+
+// Keeps the most recent requests handled by a [Router] for inspection during development.
+// Enable it with [Router.Debug].
+type DebugRecorder struct {
+	mx       sync.Mutex
+	requests []RecordedRequest
+	next     int
+	lastID   int
+	Settings DebugRecorderSettings
+}
+
+func NewDebugRecorder(settings ...SettingsFunc[DebugRecorderSettings]) *DebugRecorder {
+	recorderSettings := DefaultDebugRecorderSettings()
+	for _, setting := range settings {
+		setting(&recorderSettings)
+	}
+	recorderSettings.Capacity = max(recorderSettings.Capacity, 1)
+	return &DebugRecorder{
+		requests: make([]RecordedRequest, 0, recorderSettings.Capacity),
+		Settings: recorderSettings,
+	}
+}
+
+// The recorded requests, newest first.
+func (recorder *DebugRecorder) Requests() []RecordedRequest {
+	recorder.mx.Lock()
+	defer recorder.mx.Unlock()
+	requests := make([]RecordedRequest, 0, len(recorder.requests))
+	for i := 1; i <= len(recorder.requests); i++ {
+		requests = append(requests, recorder.requests[(recorder.next-i+len(recorder.requests))%len(recorder.requests)])
+	}
+	return requests
+}
+
+func (recorder *DebugRecorder) add(request RecordedRequest) {
+	recorder.mx.Lock()
+	defer recorder.mx.Unlock()
+	recorder.lastID++
+	request.ID = recorder.lastID
+	if len(recorder.requests) < recorder.Settings.Capacity {
+		recorder.requests = append(recorder.requests, request)
+		recorder.next = len(recorder.requests) % recorder.Settings.Capacity
+		return
+	}
+	recorder.requests[recorder.next] = request
+	recorder.next = (recorder.next + 1) % recorder.Settings.Capacity
+}
+
+// Record requests handled by router with recorder and serve the inspection page and JSON endpoint. Does
+// nothing unless the [Profile] has Debug, such as when GYR_DEBUG is set, so it can be left in place for
+// production builds.
+func (router *Router) Debug(recorder *DebugRecorder) {
+	if !isGyrDebug() {
+		return
+	}
+	router.recorder = recorder
+	router.Path(recorder.Settings.Path).Get(func(ctx *Context) *Response {
+		sb := strings.Builder{}
+		if err := debugPageTemplate.Execute(&sb, recorder.Requests()); err != nil {
+			return ctx.Response().InternalError().Text(err.Error())
+		}
+		return ctx.Response().Html(sb.String())
+	})
+	router.Path(recorder.Settings.Path + "/requests").Get(func(ctx *Context) *Response {
+		return ctx.Response().Json(recorder.Requests())
+	})
+}
+
+// Requests to the inspection page aren't recorded.
+func (recorder *DebugRecorder) isOwnPath(path string) bool {
+	return path == recorder.Settings.Path || strings.HasPrefix(path, recorder.Settings.Path+"/")
+}
+
+// State for recording a request that is being handled.
+type debugRecording struct {
+	mx      sync.Mutex
+	start   time.Time
+	request *http.Request
+	body    *limitedBuffer
+	logs    []RecordedLog
+}
+
+func (recorder *DebugRecorder) begin(req *http.Request) (*http.Request, *debugRecording) {
+	recording := &debugRecording{
+		start:   time.Now(),
+		request: req,
+		body:    &limitedBuffer{limit: recorder.Settings.MaxBodySize},
+		logs:    make([]RecordedLog, 0),
+	}
+	if req.Body != nil {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(req.Body, recording.body), req.Body}
+	}
+	return req.WithContext(context.WithValue(req.Context(), logCaptureKey{}, recording)), recording
+}
+
+func (recorder *DebugRecorder) finish(recording *debugRecording, route *Route, response *Response, redactor *Redactor) {
+	routePath := ""
+	if route != nil {
+		routePath = route.Path
+	}
+	responseBody := response.toWrite
+	if len(responseBody) > recorder.Settings.MaxBodySize {
+		responseBody = responseBody[:recorder.Settings.MaxBodySize]
+	}
+
+	recording.mx.Lock()
+	logs := recording.logs
+	recording.mx.Unlock()
+	recorder.add(RecordedRequest{
+		Time:            recording.start,
+		Method:          recording.request.Method,
+		URL:             redactor.URL(recording.request.URL),
+		Route:           routePath,
+		RequestHeaders:  redactor.Headers(recording.request.Header),
+		RequestBody:     redactor.Body(recording.request.Header.Get("Content-Type"), recording.body.String()),
+		Status:          response.status,
+		ResponseHeaders: redactor.Headers(response.w.Header()),
+		ResponseBody:    redactor.Body(response.w.Header().Get("Content-Type"), string(responseBody)),
+		Duration:        time.Since(recording.start),
+		Logs:            logs,
+	})
+}
+
+func (recording *debugRecording) addLog(record slog.Record) {
+	attrs := make(map[string]string)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.String()
+		return true
+	})
+	recording.mx.Lock()
+	defer recording.mx.Unlock()
+	recording.logs = append(recording.logs, RecordedLog{
+		Time:    record.Time,
+		Level:   record.Level.String(),
+		Message: record.Message,
+		Attrs:   attrs,
+	})
+}
+
+// Writer keeping at most limit bytes and silently dropping the rest.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (buffer *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := buffer.limit - buffer.Len(); remaining > 0 {
+		buffer.Buffer.Write(p[:min(len(p), remaining)])
+	}
+	return len(p), nil
+}
+
+type logCaptureKey struct{}
+
+// Passes records on to the wrapped handler and adds them to the recording of the current request.
+type captureHandler struct {
+	slog.Handler
+	recording *debugRecording
+}
+
+func (handler *captureHandler) Handle(ctx context.Context, record slog.Record) error {
+	handler.recording.addLog(record)
+	return handler.Handler.Handle(ctx, record)
+}
+
+func (handler *captureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &captureHandler{Handler: handler.Handler.WithAttrs(attrs), recording: handler.recording}
+}
+
+func (handler *captureHandler) WithGroup(name string) slog.Handler {
+	return &captureHandler{Handler: handler.Handler.WithGroup(name), recording: handler.recording}
+}
+
+var debugPageTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Gyr debug</title></head>
+<body>
+<h1>Recent requests</h1>
+{{ range . }}
+<details>
+<summary>#{{ .ID }} {{ .Method }} {{ .URL }} → {{ .Status }} ({{ .Duration }}) {{ with .Route }}route {{ . }}{{ end }}</summary>
+<h3>Request headers</h3>
+<pre>{{ range $name, $values := .RequestHeaders }}{{ $name }}: {{ range $values }}{{ . }} {{ end }}
+{{ end }}</pre>
+<h3>Request body</h3>
+<pre>{{ .RequestBody }}</pre>
+<h3>Response headers</h3>
+<pre>{{ range $name, $values := .ResponseHeaders }}{{ $name }}: {{ range $values }}{{ . }} {{ end }}
+{{ end }}</pre>
+<h3>Response body</h3>
+<pre>{{ .ResponseBody }}</pre>
+<h3>Logs</h3>
+<pre>{{ range .Logs }}{{ .Time.Format "15:04:05.000" }} {{ .Level }} {{ .Message }} {{ range $key, $value := .Attrs }}{{ $key }}={{ $value }} {{ end }}
+{{ end }}</pre>
+</details>
+{{ else }}
+<p>No requests recorded yet.</p>
+{{ end }}
+</body>
+</html>
+`))
@@ -0,0 +1,65 @@
+package gyr_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestDebugRecorder(t *testing.T) {
+	t.Setenv("GYR_DEBUG", "")
+	recorder := gyr.NewDebugRecorder(gyr.DebugCapacity(2), gyr.DebugMaxBodySize(5))
+	router := defaultTestRouter()
+	router.Debug(recorder)
+	router.Path("/echo/:word").Post(func(ctx *gyr.Context) *gyr.Response {
+		body, _ := gyr.ReadBody[map[string]string](ctx)
+		ctx.Logger().Info("echoing", "word", ctx.StringVariable("word"))
+		return ctx.Response().Json(body)
+	})
+
+	for _, word := range []string{"first", "second", "third"} {
+		request, _ := http.NewRequest(http.MethodPost, "/echo/"+word, createPayload(map[string]string{"w": word}))
+		request.Header.Set("Content-Type", "application/json")
+		sendRequest(router, request)
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, "/debug/requests", nil)
+	var recorded []gyr.RecordedRequest
+	json.Unmarshal(sendRequest(router, request).Body.Bytes(), &recorded)
+	if len(recorded) != 2 || recorded[0].URL != "/echo/third" || recorded[1].URL != "/echo/second" {
+		t.Logf("Expected the two latest requests, newest first. Received %+v\n", recorded)
+		t.FailNow()
+	}
+
+	latest := recorded[0]
+	if latest.Route != "/echo/:word" || latest.Status != http.StatusOK || latest.RequestBody != `{"w":` || latest.ResponseBody != `{"w":` {
+		t.Logf("Unexpected recording %+v\n", latest)
+		t.FailNow()
+	}
+	if len(latest.Logs) != 1 || latest.Logs[0].Message != "echoing" || latest.Logs[0].Attrs["word"] != "third" {
+		t.Logf("Expected handler log to be recorded. Received %+v\n", latest.Logs)
+		t.FailNow()
+	}
+
+	request, _ = http.NewRequest(http.MethodGet, "/debug", nil)
+	if page := sendRequest(router, request).Body.String(); !strings.Contains(page, "/echo/third") {
+		t.Logf("Expected inspection page to list requests. Received %s\n", page)
+		t.FailNow()
+	}
+}
+
+func TestDebugRecorderRequiresDebugMode(t *testing.T) {
+	t.Setenv("GYR_DEBUG", "")
+	os.Unsetenv("GYR_DEBUG")
+	router := defaultTestRouter()
+	router.Debug(gyr.NewDebugRecorder())
+	request, _ := http.NewRequest(http.MethodGet, "/debug", nil)
+	if response := sendRequest(router, request); response.Code != http.StatusNotFound {
+		t.Logf("Expected 404 without GYR_DEBUG. Received %d\n", response.Code)
+		t.FailNow()
+	}
+}
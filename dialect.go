@@ -0,0 +1,120 @@
+package gyr
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Describes the SQL differences between database engines that Gyr has to care about.
+type Dialect struct {
+	Name string
+	// Placeholder for the n:th (starting at 1) parameter of a query.
+	Placeholder func(n int) string
+	// Statement creating the migration history table if it does not exist.
+	HistoryTableDDL string
+	// Query for the length of the version column of the migration history table, and the statement widening it
+	// to the length in HistoryTableDDL. Tables created by earlier versions of Gyr have a version column too short
+	// for timestamp versions. Empty for engines that don't enforce the length.
+	VersionLengthQuery string
+	WidenVersionColumn string
+	// Statement creating the table keeping track of applied seeds if it does not exist.
+	SeedTableDDL string
+	// Clause making an insert update the columns in update when a row with the same conflict columns exists.
+	Upsert func(conflict []string, update []string) string
+}
+
+var (
+	DialectMySQL = Dialect{
+		Name:               "mysql",
+		Placeholder:        questionMarkPlaceholder,
+		HistoryTableDDL:    "create table if not exists gyr_migrator_version_history (version varchar(50), path varchar(255), applied_at timestamp null, duration_ms bigint, success boolean, checksum varchar(64));",
+		VersionLengthQuery: "select character_maximum_length from information_schema.columns where table_schema = database() and table_name = 'gyr_migrator_version_history' and column_name = 'version'",
+		WidenVersionColumn: "alter table gyr_migrator_version_history modify version varchar(50)",
+		SeedTableDDL:       "create table if not exists gyr_migrator_seed_history (name varchar(255), environment varchar(50), applied_at timestamp null);",
+		Upsert: func(conflict []string, update []string) string {
+			assignments := make([]string, len(update))
+			for i, column := range update {
+				assignments[i] = column + " = values(" + column + ")"
+			}
+			return "on duplicate key update " + strings.Join(assignments, ", ")
+		},
+	}
+	DialectPostgres = Dialect{
+		Name: "postgres",
+		Placeholder: func(n int) string {
+			return "$" + strconv.Itoa(n)
+		},
+		HistoryTableDDL:    "create table if not exists gyr_migrator_version_history (version varchar(50), path varchar(255), applied_at timestamptz, duration_ms bigint, success boolean, checksum varchar(64));",
+		VersionLengthQuery: "select character_maximum_length from information_schema.columns where table_schema = current_schema() and table_name = 'gyr_migrator_version_history' and column_name = 'version'",
+		WidenVersionColumn: "alter table gyr_migrator_version_history alter column version type varchar(50)",
+		SeedTableDDL:       "create table if not exists gyr_migrator_seed_history (name varchar(255), environment varchar(50), applied_at timestamptz);",
+		Upsert:             onConflictUpsert,
+	}
+	DialectSQLite = Dialect{
+		Name:            "sqlite",
+		Placeholder:     questionMarkPlaceholder,
+		HistoryTableDDL: "create table if not exists gyr_migrator_version_history (version text, path text, applied_at datetime, duration_ms integer, success boolean, checksum text);",
+		SeedTableDDL:    "create table if not exists gyr_migrator_seed_history (name text, environment text, applied_at datetime);",
+		Upsert:          onConflictUpsert,
+	}
+)
+
+func questionMarkPlaceholder(int) string {
+	return "?"
+}
+
+func onConflictUpsert(conflict []string, update []string) string {
+	assignments := make([]string, len(update))
+	for i, column := range update {
+		assignments[i] = column + " = excluded." + column
+	}
+	return "on conflict (" + strings.Join(conflict, ", ") + ") do update set " + strings.Join(assignments, ", ")
+}
+
+// Guess the dialect from the type of the driver behind the connection. Falls back to [DialectMySQL]
+// when the connection doesn't expose its driver, as is the case for *sql.Tx.
+func DetectDialect(connection DBTX) Dialect {
+	withDriver, hasDriver := connection.(interface{ Driver() driver.Driver })
+	if !hasDriver || withDriver.Driver() == nil {
+		return DialectMySQL
+	}
+	driverType := reflect.TypeOf(withDriver.Driver())
+	for driverType.Kind() == reflect.Pointer {
+		driverType = driverType.Elem()
+	}
+	driverName := strings.ToLower(driverType.PkgPath() + "." + driverType.Name())
+	switch {
+	case strings.Contains(driverName, "pq"), strings.Contains(driverName, "pgx"), strings.Contains(driverName, "postgres"):
+		return DialectPostgres
+	case strings.Contains(driverName, "sqlite"):
+		return DialectSQLite
+	default:
+		return DialectMySQL
+	}
+}
+
+// Rewrite the ? placeholders in query into the placeholder style of the dialect.
+func (dialect Dialect) Rebind(query string) string {
+	if dialect.Placeholder == nil || !strings.Contains(query, "?") {
+		return query
+	}
+
+	sb := strings.Builder{}
+	n := 0
+	inString := false
+	for _, ch := range query {
+		switch {
+		case ch == '\'':
+			inString = !inString
+			sb.WriteRune(ch)
+		case ch == '?' && !inString:
+			n++
+			sb.WriteString(dialect.Placeholder(n))
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,45 @@
+package gyr
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	query := "insert into t (a, b, c) values (?, ?, '?')"
+	t.Run("mysql", func(t *testing.T) {
+		if received := DialectMySQL.Rebind(query); received != query {
+			t.Logf("Expected %s. Received %s\n", query, received)
+			t.FailNow()
+		}
+	})
+	t.Run("postgres", func(t *testing.T) {
+		expected := "insert into t (a, b, c) values ($1, $2, '?')"
+		if received := DialectPostgres.Rebind(query); received != expected {
+			t.Logf("Expected %s. Received %s\n", expected, received)
+			t.FailNow()
+		}
+	})
+}
+
+func TestUpsert(t *testing.T) {
+	type upsertUser struct {
+		ID   int    `gyr_column:"id"`
+		Name string `gyr_column:"name"`
+	}
+	RegisterEntity[upsertUser](EntityMetadata{Table: "users"})
+	tests := map[string]struct {
+		dialect  Dialect
+		expected string
+	}{
+		"mysql":    {DialectMySQL, "insert into users (id, name) values (?,?) on duplicate key update name = values(name)"},
+		"postgres": {DialectPostgres, "insert into users (id, name) values (?,?) on conflict (id) do update set name = excluded.name"},
+		"sqlite":   {DialectSQLite, "insert into users (id, name) values (?,?) on conflict (id) do update set name = excluded.name"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			query := NewQuery[upsertUser]().InsertAll().AddValue().Upsert(test.dialect, []string{"id"}, []string{"name"}).Query()
+			if query != test.expected {
+				t.Logf("Expected %s. Received %s\n", test.expected, query)
+				t.FailNow()
+			}
+		})
+	}
+}
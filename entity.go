@@ -13,6 +13,8 @@ type EntityMetadata struct {
 	Table string
 	// Is overwritten by RegisterEntity if a field with a gyr_column tag is detected in the struct being registered
 	Columns []string
+	// Column identifying a row, used by [Repository]. Defaults to id.
+	PrimaryKey string
 }
 
 const (
@@ -20,6 +22,7 @@ const (
 	queryColumns        = 1 << 1
 	queryIsInConditions = 1 << 2
 	queryHasValueAdded  = 1 << 3
+	queryIsOrdered      = 1 << 4
 )
 
 type BaseQueryBuilder interface {
@@ -34,6 +37,26 @@ type QueryBuilder[EntityType any] struct {
 }
 
 type SelectBuilder interface {
+	ListBuilder
+	// Start adding WHERE-conditions to your query.
+	Where(string) WhereBuilder
+}
+
+type ListBuilder interface {
+	BaseQueryBuilder
+	// Sort by column. Can be called several times to sort by more columns.
+	OrderBy(column string, descending bool) ListBuilder
+	// Limit the number of rows and skip the first offset rows.
+	Paginate(limit int, offset int) BaseQueryBuilder
+}
+
+type UpdateBuilder interface {
+	BaseQueryBuilder
+	// Start adding WHERE-conditions to your query.
+	Where(string) WhereBuilder
+}
+
+type DeleteBuilder interface {
 	BaseQueryBuilder
 	// Start adding WHERE-conditions to your query.
 	Where(string) WhereBuilder
@@ -43,14 +66,21 @@ type InsertBuilder interface {
 	BaseQueryBuilder
 	// Add a set of values to the INSERT-query
 	AddValue() InsertBuilder
+	// Update the update columns instead when a row with the same conflict columns exists, using the
+	// syntax of dialect.
+	Upsert(dialect Dialect, conflict []string, update []string) BaseQueryBuilder
 }
 
 type WhereBuilder interface {
-	BaseQueryBuilder
+	ListBuilder
 	// Equals condition with a SQL template variable
 	EqualsVar() WhereBuilder
 	// Equals a set value
 	EqualsValue(any) WhereBuilder
+	// Compare with a SQL template variable using one of =, <>, <, <=, >, >= and like
+	CompareVar(operator string) WhereBuilder
+	// In a list of n SQL template variables
+	InVars(n int) WhereBuilder
 	And(string) WhereBuilder
 	Or(string) WhereBuilder
 }
@@ -127,6 +157,37 @@ func (qb *QueryBuilder[EntityType]) Insert(columns []string) InsertBuilder {
 	return qb
 }
 
+// Create an UPDATE-query setting columns to SQL template variables.
+func (qb *QueryBuilder[EntityType]) Update(columns []string) UpdateBuilder {
+	if qb.fieldsSet&queryType > 0 {
+		panic("query type already set")
+	}
+	for _, column := range columns {
+		if !qb.hasColumn(column) {
+			panic("Unknown column: " + column)
+		}
+	}
+
+	qb.sb.WriteString("update ")
+	qb.sb.WriteString(qb.entityMetadata.Table)
+	qb.sb.WriteString(" set ")
+	qb.sb.WriteString(strings.Join(columns, " = ?, "))
+	qb.sb.WriteString(" = ?")
+	qb.fieldsSet |= queryType
+	return qb
+}
+
+// Create a DELETE-query. Without conditions it deletes every row.
+func (qb *QueryBuilder[EntityType]) Delete() DeleteBuilder {
+	if qb.fieldsSet&queryType > 0 {
+		panic("query type already set")
+	}
+	qb.sb.WriteString("delete from ")
+	qb.sb.WriteString(qb.entityMetadata.Table)
+	qb.fieldsSet |= queryType
+	return qb
+}
+
 func (qb *QueryBuilder[EntityType]) AddValue() InsertBuilder {
 	if qb.fieldsSet&queryHasValueAdded > 0 {
 		qb.sb.WriteRune(',')
@@ -138,6 +199,20 @@ func (qb *QueryBuilder[EntityType]) AddValue() InsertBuilder {
 	return qb
 }
 
+func (qb *QueryBuilder[EntityType]) Upsert(dialect Dialect, conflict []string, update []string) BaseQueryBuilder {
+	if qb.fieldsSet&queryHasValueAdded == 0 {
+		panic("no values added")
+	}
+	for _, column := range slices.Concat(conflict, update) {
+		if !qb.hasColumn(column) {
+			panic("Unknown column: " + column)
+		}
+	}
+	qb.sb.WriteRune(' ')
+	qb.sb.WriteString(dialect.Upsert(conflict, update))
+	return qb
+}
+
 func (qb *QueryBuilder[EntityType]) Where(column string) WhereBuilder {
 	if qb.fieldsSet&queryType == 0 {
 		panic("no query type set")
@@ -178,6 +253,68 @@ func (qb *QueryBuilder[EntityType]) EqualsValue(value any) WhereBuilder {
 	return qb
 }
 
+var comparisonOperators = []string{"=", "<>", "<", "<=", ">", ">=", "like"}
+
+func (qb *QueryBuilder[EntityType]) CompareVar(operator string) WhereBuilder {
+	if qb.fieldsSet&queryIsInConditions == 0 {
+		panic("QueryBuilder is not in conditions phase")
+	}
+	if !slices.Contains(comparisonOperators, operator) {
+		panic("Unknown operator: " + operator)
+	}
+	qb.sb.WriteRune(' ')
+	qb.sb.WriteString(operator)
+	qb.sb.WriteString(" ?")
+	return qb
+}
+
+func (qb *QueryBuilder[EntityType]) InVars(n int) WhereBuilder {
+	if qb.fieldsSet&queryIsInConditions == 0 {
+		panic("QueryBuilder is not in conditions phase")
+	}
+	if n < 1 {
+		panic("in condition needs at least one variable")
+	}
+	qb.sb.WriteString(" in (")
+	qb.sb.WriteString(nVars(n))
+	qb.sb.WriteRune(')')
+	return qb
+}
+
+func (qb *QueryBuilder[EntityType]) OrderBy(column string, descending bool) ListBuilder {
+	if qb.fieldsSet&queryType == 0 {
+		panic("no query type set")
+	}
+	if !qb.hasColumn(column) {
+		panic("Unknown column: " + column)
+	}
+
+	if qb.fieldsSet&queryIsOrdered > 0 {
+		qb.sb.WriteString(", ")
+	} else {
+		qb.sb.WriteString(" order by ")
+	}
+	qb.sb.WriteString(column)
+	if descending {
+		qb.sb.WriteString(" desc")
+	}
+	qb.fieldsSet |= queryIsOrdered
+	return qb
+}
+
+func (qb *QueryBuilder[EntityType]) Paginate(limit int, offset int) BaseQueryBuilder {
+	if qb.fieldsSet&queryType == 0 {
+		panic("no query type set")
+	}
+	qb.sb.WriteString(" limit ")
+	qb.sb.WriteString(strconv.Itoa(limit))
+	if offset > 0 {
+		qb.sb.WriteString(" offset ")
+		qb.sb.WriteString(strconv.Itoa(offset))
+	}
+	return qb
+}
+
 func (qb *QueryBuilder[EntityType]) Or(column string) WhereBuilder {
 	if qb.fieldsSet&queryIsInConditions == 0 {
 		panic("QueryBuilder is not in conditions phase")
@@ -205,6 +342,9 @@ func RegisterEntity[EntityType any](metadata EntityMetadata) {
 	if detectedColumns := getColumnsFromType(entityType); len(detectedColumns) > 0 {
 		metadata.Columns = detectedColumns
 	}
+	if metadata.PrimaryKey == "" {
+		metadata.PrimaryKey = "id"
+	}
 	entityRegistry[entityType] = metadata
 }
 
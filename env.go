@@ -3,44 +3,325 @@ package gyr
 import (
 	"bufio"
 	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
 // The file [LoadEnvironment] will attempt to read environment variables from. Default is '.env'.
 var EnvFile = ".env"
-var lineMatcher = regexp.MustCompile(`^(?P<name>[a-zA-Z][a-zA-Z0-9_]+)=(?P<value>\S+)$`)
+var lineMatcher = regexp.MustCompile(`^(?P<name>[a-zA-Z][a-zA-Z0-9_]+)=(?P<value>.*)$`)
+var unquotedValueMatcher = regexp.MustCompile(`^\S+$`)
+var doubleQuoteUnescaper = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`)
+var doubleQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "$", `\$`)
+var nameMatcher = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]+$`)
+
+type envVariable struct {
+	name  string
+	value string
+}
+
+type EnvSettings struct {
+	// Overwrite variables that are already set in the environment.
+	Overwrite bool
+}
+
+func EnvOverwrite() func(*EnvSettings) {
+	return func(es *EnvSettings) {
+		es.Overwrite = true
+	}
+}
+
+// Which variables loading an env file set and which it left alone because they already were set.
+type EnvReport struct {
+	Set     []string
+	Skipped []string
+}
 
 // Reads variables in the file specified by [EnvFile] into the current environment.
-func LoadEnvironment() error {
+func LoadEnvironment(settings ...SettingsFunc[EnvSettings]) error {
+	_, err := LoadEnvironmentReport(settings...)
+	return err
+}
+
+// Like [LoadEnvironment] but reports which variables were set and which were skipped.
+func LoadEnvironmentReport(settings ...SettingsFunc[EnvSettings]) (EnvReport, error) {
 	file, err := os.Open(EnvFile)
+	if err != nil {
+		return EnvReport{}, err
+	}
+	defer file.Close()
+	return LoadEnvironmentFrom(file, settings...)
+}
+
+// Read dotenv formatted content from r into the current environment, for example from an embedded file
+// or a secrets mount.
+func LoadEnvironmentFrom(r io.Reader, settings ...SettingsFunc[EnvSettings]) (EnvReport, error) {
+	var envSettings EnvSettings
+	for _, setting := range settings {
+		setting(&envSettings)
+	}
+
+	report := EnvReport{Set: make([]string, 0), Skipped: make([]string, 0)}
+	err := applyEnvironment(r, envSettings.Overwrite, func(name string) bool {
+		_, isSet := os.LookupEnv(name)
+		if isSet && !envSettings.Overwrite {
+			report.Skipped = append(report.Skipped, name)
+			return false
+		}
+		report.Set = append(report.Set, name)
+		return true
+	})
+	return report, err
+}
+
+// Read the env file name in fsys into the current environment.
+func LoadEnvironmentFS(fsys fs.FS, name string, settings ...SettingsFunc[EnvSettings]) (EnvReport, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return EnvReport{}, err
+	}
+	defer file.Close()
+	return LoadEnvironmentFrom(file, settings...)
+}
+
+// Returned by [RequireEnv], naming every variable that is missing.
+type MissingEnvError struct {
+	Names []string
+}
+
+func (err *MissingEnvError) Error() string {
+	return "missing required environment variables: " + strings.Join(err.Names, ", ")
+}
+
+// Check that all of the variables are set to non-empty values. Meant to be called at startup so missing
+// configuration is discovered before it is first used. The returned error is a [*MissingEnvError].
+func RequireEnv(names ...string) error {
+	missing := make([]string, 0)
+	for _, name := range names {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingEnvError{Names: missing}
+	}
+	return nil
+}
+
+// Load several env files, with later files taking precedence over earlier ones. Variables that were set before
+// loading are never overwritten and files that don't exist are skipped. Without arguments the files from
+// [EnvironmentFiles] are loaded.
+func LoadEnvironmentFiles(files ...string) error {
+	if len(files) == 0 {
+		files = EnvironmentFiles()
+	}
+
+	preset := make(map[string]bool)
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		preset[name] = true
+	}
+	for _, path := range files {
+		err := loadEnvironmentFile(path, false, func(name string) bool {
+			return !preset[name]
+		})
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The env files for the environment named by APP_ENV, in increasing precedence. With [EnvFile] set to .env and
+// APP_ENV set to production these are .env, .env.local, .env.production and .env.production.local.
+func EnvironmentFiles() []string {
+	files := []string{EnvFile, EnvFile + ".local"}
+	if appEnv := os.Getenv("APP_ENV"); appEnv != "" {
+		files = append(files, EnvFile+"."+appEnv, EnvFile+"."+appEnv+".local")
+	}
+	return files
+}
+
+func loadEnvironmentFile(path string, preferFile bool, shouldSet func(name string) bool) error {
+	file, err := os.Open(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
+	return applyEnvironment(file, preferFile, shouldSet)
+}
+
+// Set the variables in r for which shouldSet returns true. When preferContent is set, references to
+// variables defined in the content resolve to the content's value rather than the current environment.
+func applyEnvironment(r io.Reader, preferContent bool, shouldSet func(name string) bool) error {
+	variables, err := parseEnvironment(r, preferContent)
+	if err != nil {
+		return err
+	}
+	for _, variable := range variables {
+		if shouldSet(variable.name) {
+			os.Setenv(variable.name, variable.value)
+		}
+	}
+	return nil
+}
 
-	reader := bufio.NewReader(file)
+// Parse dotenv formatted content. Values may be quoted with " or ', and quoted values may span multiple lines
+// and be followed by a # comment.
+// ${NAME} references in unquoted and double quoted values are resolved against the current environment
+// and the variables earlier in the content, with the environment taking precedence unless preferLoaded is set.
+func parseEnvironment(r io.Reader, preferLoaded bool) ([]envVariable, error) {
+	variables := make([]envVariable, 0)
+	loaded := make(map[string]string)
+	lookup := func(name string) (string, bool) {
+		if value, isSet := loaded[name]; isSet && preferLoaded {
+			return value, true
+		}
+		if value, isSet := os.LookupEnv(name); isSet {
+			return value, true
+		}
+		value, isSet := loaded[name]
+		return value, isSet
+	}
+	reader := bufio.NewReader(r)
 	for {
 		line, err := reader.ReadString('\n')
 		if len(line) == 0 && err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
-			return err
+			return nil, err
 		}
-		line = strings.TrimSpace(line)
+		// Only the start is trimmed, since whitespace at the end may be inside a quoted value.
+		line = strings.TrimLeftFunc(strings.TrimRight(line, "\r\n"), unicode.IsSpace)
 		if shouldSkipLine(line) {
 			continue
 		}
 		matches := regexNamedMatches(lineMatcher, line)
-		if _, isSet := os.LookupEnv(matches["name"]); isSet || len(matches) != 2 {
+		value := matches["value"]
+
+		if len(value) > 0 && value[0] == '\'' {
+			value, err = readQuotedValue(reader, value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", matches["name"], err)
+			}
+		} else if len(value) > 0 && value[0] == '"' {
+			value, err = readQuotedValue(reader, value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", matches["name"], err)
+			}
+			value = interpolate(value, lookup)
+		} else if value = strings.TrimSpace(value); unquotedValueMatcher.MatchString(value) {
+			value = interpolate(value, lookup)
+		} else {
 			continue
 		}
-		os.Setenv(matches["name"], matches["value"])
+		variables = append(variables, envVariable{name: matches["name"], value: value})
+		loaded[matches["name"]] = value
 	}
-	return nil
+	return variables, nil
+}
+
+// Replace ${NAME} references in value using lookup. References to unknown variables are replaced with
+// an empty string, and \$ produces a literal $.
+func interpolate(value string, lookup func(string) (string, bool)) string {
+	if !strings.Contains(value, "$") {
+		return value
+	}
+
+	sb := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == '$':
+			sb.WriteByte('$')
+			i++
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '{':
+			end := strings.IndexByte(value[i+2:], '}')
+			if end == -1 {
+				sb.WriteString(value[i:])
+				return sb.String()
+			}
+			replacement, _ := lookup(value[i+2 : i+2+end])
+			sb.WriteString(replacement)
+			i += end + 2
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+	return sb.String()
+}
+
+// Read lines from reader until the quote that value starts with is closed. Whitespace inside the quotes is kept
+// and the closing quote may be followed by a comment.
+func readQuotedValue(reader *bufio.Reader, value string) (string, error) {
+	quote := value[0]
+	value = value[1:]
+	end := closingQuote(value, quote)
+	for end < 0 {
+		line, err := reader.ReadString('\n')
+		if len(line) == 0 && err != nil {
+			if errors.Is(err, io.EOF) {
+				return "", errors.New("unterminated quoted value")
+			}
+			return "", err
+		}
+		value += "\n" + strings.TrimRight(line, "\r\n")
+		end = closingQuote(value, quote)
+	}
+	if rest := strings.TrimSpace(value[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected %q after quoted value", rest)
+	}
+	value = value[:end]
+	if quote == '"' {
+		value = doubleQuoteUnescaper.Replace(value)
+	}
+	return value, nil
+}
+
+// The index of the quote closing value, or -1. Quotes escaped with a backslash don't close double quoted values.
+func closingQuote(value string, quote byte) int {
+	if quote == '\'' {
+		return strings.IndexByte(value, quote)
+	}
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// Write the current values of the variables to path in dotenv format, quoting values where needed.
+// Variables that aren't set are written without a value, which is useful for scaffolding .env.example files.
+func SaveEnvironment(path string, keys ...string) error {
+	sb := strings.Builder{}
+	for _, key := range keys {
+		if !nameMatcher.MatchString(key) {
+			return fmt.Errorf("invalid environment variable name %q", key)
+		}
+		sb.WriteString(key)
+		sb.WriteRune('=')
+		sb.WriteString(formatEnvValue(os.Getenv(key)))
+		sb.WriteRune('\n')
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0o600)
+}
+
+func formatEnvValue(value string) string {
+	if value == "" || unquotedValueMatcher.MatchString(value) && !strings.ContainsAny(value, `"'$#\`) {
+		return value
+	}
+	return `"` + doubleQuoteEscaper.Replace(value) + `"`
 }
 
 func shouldSkipLine(line string) bool {
@@ -0,0 +1,50 @@
+package gyr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEnvironmentUnterminatedQuote(t *testing.T) {
+	_, err := parseEnvironment(strings.NewReader("KEY=\"never closed\nNEXT=1\n"), false)
+	if err == nil || !strings.Contains(err.Error(), "KEY") {
+		t.Logf("Expected unterminated error naming KEY. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestParseEnvironmentEscapedQuote(t *testing.T) {
+	variables, err := parseEnvironment(strings.NewReader(`KEY="say \"hi\""`+"\n"), false)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(variables) != 1 || variables[0].value != `say "hi"` {
+		t.Logf("Received %+v\n", variables)
+		t.FailNow()
+	}
+}
+
+func TestParseEnvironmentCommentAfterQuote(t *testing.T) {
+	variables, err := parseEnvironment(strings.NewReader("KEY=\"a\" # comment\nSINGLE='b'\t# comment\n"), false)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(variables) != 2 || variables[0].value != "a" || variables[1].value != "b" {
+		t.Logf("Received %+v\n", variables)
+		t.FailNow()
+	}
+}
+
+func TestParseEnvironmentQuotedWhitespace(t *testing.T) {
+	variables, err := parseEnvironment(strings.NewReader("KEY=\"padded  \"\nMULTI='first  \n  second\t'\n"), false)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(variables) != 2 || variables[0].value != "padded  " || variables[1].value != "first  \n  second\t" {
+		t.Logf("Received %q\n", variables)
+		t.FailNow()
+	}
+}
@@ -1,8 +1,14 @@
 package gyr_test
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/aigr20/gyr"
 )
@@ -13,6 +19,11 @@ func TestLoadEnvironment(t *testing.T) {
 		"VAR":            "32",
 		"COMMENTED_LINE": "",
 		"host":           "localhost",
+		"QUOTED":         "hello world",
+		"SINGLE_QUOTED":  "it is $literal",
+		"PRIVATE_KEY":    "-----BEGIN KEY-----\nabc123\n-----END KEY-----",
+		"DB_URL":         "postgres://localhost:5432",
+		"PRICE":          "$5 for hello world",
 	}
 	err := gyr.LoadEnvironment()
 	if err != nil {
@@ -40,3 +51,232 @@ func TestLoadEnvironmentDoesNotOverwrite(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestLoadEnvironmentFiles(t *testing.T) {
+	gyr.EnvFile = "test_files/env/.env"
+	defer func() { gyr.EnvFile = ".env" }()
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("LAYER_PRESET", "preset")
+
+	err := gyr.LoadEnvironmentFiles()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	expectations := map[string]string{
+		"LAYER_BASE":     "base",
+		"LAYER_OVERRIDE": "production",
+		"LAYER_PRESET":   "preset",
+	}
+	for name, expected := range expectations {
+		if v := os.Getenv(name); v != expected {
+			t.Logf("Expected %s to equal '%s'. Received '%s'\n", name, expected, v)
+			t.FailNow()
+		}
+	}
+}
+
+func TestLoadEnvironmentOverwrite(t *testing.T) {
+	gyr.EnvFile = "env_test_file"
+	t.Setenv("VAR", "exist")
+	t.Setenv("host", "remote")
+	report, err := gyr.LoadEnvironmentReport(gyr.EnvOverwrite())
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if v := os.Getenv("VAR"); v != "32" {
+		t.Logf("Expected VAR to be '32' but received '%s'\n", v)
+		t.FailNow()
+	}
+	if !slices.Contains(report.Set, "VAR") || len(report.Skipped) != 0 {
+		t.Logf("Received %+v\n", report)
+		t.FailNow()
+	}
+	if v := os.Getenv("DB_URL"); v != "postgres://localhost:5432" {
+		t.Logf("Expected DB_URL to use the file's host but received '%s'\n", v)
+		t.FailNow()
+	}
+}
+
+func TestLoadEnvironmentReportsSkipped(t *testing.T) {
+	gyr.EnvFile = "env_test_file"
+	t.Setenv("VAR", "exist")
+	report, err := gyr.LoadEnvironmentReport()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if !slices.Contains(report.Skipped, "VAR") || slices.Contains(report.Set, "VAR") {
+		t.Logf("Received %+v\n", report)
+		t.FailNow()
+	}
+}
+
+func TestLoadEnvironmentFrom(t *testing.T) {
+	t.Setenv("FROM_READER", "")
+	os.Unsetenv("FROM_READER")
+	report, err := gyr.LoadEnvironmentFrom(strings.NewReader("FROM_READER=yes\n"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if v := os.Getenv("FROM_READER"); v != "yes" || len(report.Set) != 1 {
+		t.Logf("Expected FROM_READER to be 'yes' but received '%s'\n", v)
+		t.FailNow()
+	}
+}
+
+func TestLoadEnvironmentFS(t *testing.T) {
+	t.Setenv("FROM_FS", "")
+	os.Unsetenv("FROM_FS")
+	fsys := fstest.MapFS{"secrets/app.env": &fstest.MapFile{Data: []byte("FROM_FS=mounted\n")}}
+	if _, err := gyr.LoadEnvironmentFS(fsys, "secrets/app.env"); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if v := os.Getenv("FROM_FS"); v != "mounted" {
+		t.Logf("Expected FROM_FS to be 'mounted' but received '%s'\n", v)
+		t.FailNow()
+	}
+}
+
+func TestRequireEnv(t *testing.T) {
+	t.Setenv("REQUIRED_SET", "yes")
+	t.Setenv("REQUIRED_EMPTY", "")
+	err := gyr.RequireEnv("REQUIRED_SET", "REQUIRED_EMPTY", "REQUIRED_UNSET")
+	var missing *gyr.MissingEnvError
+	if !errors.As(err, &missing) {
+		t.Logf("Expected MissingEnvError. Received %v\n", err)
+		t.FailNow()
+	}
+	if !slices.Equal(missing.Names, []string{"REQUIRED_EMPTY", "REQUIRED_UNSET"}) {
+		t.Logf("Received %+v\n", missing.Names)
+		t.FailNow()
+	}
+	if err := gyr.RequireEnv("REQUIRED_SET"); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+}
+
+func TestWatchEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(path, []byte("WATCHED_SECRET=old\nWATCHED_STABLE=same\n"), 0o644)
+	t.Setenv("WATCHED_SECRET", "")
+	os.Unsetenv("WATCHED_SECRET")
+	t.Setenv("WATCHED_STABLE", "")
+	os.Unsetenv("WATCHED_STABLE")
+
+	watcher, err := gyr.WatchEnvironment(gyr.EnvWatchFile(path), gyr.EnvWatchInterval(5*time.Millisecond))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	defer watcher.Stop()
+	changes := make(chan []string, 1)
+	watcher.OnChange(func(changed []string) {
+		changes <- changed
+	})
+
+	os.WriteFile(path, []byte("WATCHED_SECRET=rotated\nWATCHED_STABLE=same\n"), 0o644)
+	select {
+	case changed := <-changes:
+		if !slices.Equal(changed, []string{"WATCHED_SECRET"}) {
+			t.Logf("Received %+v\n", changed)
+			t.FailNow()
+		}
+	case <-time.After(2 * time.Second):
+		t.Log("No change detected")
+		t.FailNow()
+	}
+	if v := os.Getenv("WATCHED_SECRET"); v != "rotated" {
+		t.Logf("Expected WATCHED_SECRET to be 'rotated' but received '%s'\n", v)
+		t.FailNow()
+	}
+}
+
+func TestWatchEnvironmentLeavesProcessVariables(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(path, []byte("WATCHED_OWNED=a\nWATCHED_PRESET=file\n"), 0o644)
+	t.Setenv("WATCHED_OWNED", "")
+	os.Unsetenv("WATCHED_OWNED")
+	t.Setenv("WATCHED_PRESET", "process")
+	t.Setenv("WATCHED_OTHER", "")
+	os.Unsetenv("WATCHED_OTHER")
+
+	watcher, err := gyr.WatchEnvironment(gyr.EnvWatchFile(path), gyr.EnvWatchInterval(5*time.Millisecond))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	changes := make(chan []string, 1)
+	watcher.OnChange(func(changed []string) {
+		changes <- changed
+	})
+
+	os.WriteFile(path, []byte("WATCHED_OWNED=b\nWATCHED_PRESET=changed\n"), 0o644)
+	select {
+	case changed := <-changes:
+		if !slices.Equal(changed, []string{"WATCHED_OWNED"}) {
+			t.Logf("Received %+v\n", changed)
+			t.FailNow()
+		}
+	case <-time.After(2 * time.Second):
+		t.Log("No change detected")
+		t.FailNow()
+	}
+	os.WriteFile(path, []byte("WATCHED_OTHER=1\n"), 0o644)
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Log("No change detected")
+		t.FailNow()
+	}
+	watcher.Stop()
+	watcher.Stop()
+
+	if _, isSet := os.LookupEnv("WATCHED_OWNED"); isSet {
+		t.Log("Expected WATCHED_OWNED to be unset when removed from the file")
+		t.FailNow()
+	}
+	if v := os.Getenv("WATCHED_PRESET"); v != "process" {
+		t.Logf("Expected WATCHED_PRESET to keep the process value but received '%s'\n", v)
+		t.FailNow()
+	}
+}
+
+func TestSaveEnvironmentRoundTrip(t *testing.T) {
+	values := map[string]string{
+		"SAVE_PLAIN":     "localhost",
+		"SAVE_SPACES":    "hello world",
+		"SAVE_MULTILINE": "line1\nline2",
+		"SAVE_SPECIAL":   `price $5 "quoted" C:\path`,
+	}
+	keys := make([]string, 0)
+	for key, value := range values {
+		t.Setenv(key, value)
+		keys = append(keys, key)
+	}
+	path := filepath.Join(t.TempDir(), ".env.saved")
+	if err := gyr.SaveEnvironment(path, append(keys, "SAVE_UNSET")...); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	for key := range values {
+		os.Unsetenv(key)
+	}
+	gyr.EnvFile = path
+	defer func() { gyr.EnvFile = ".env" }()
+	if err := gyr.LoadEnvironment(); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	for key, expected := range values {
+		if v := os.Getenv(key); v != expected {
+			t.Logf("Expected %s to equal '%s'. Received '%s'\n", key, expected, v)
+			t.Fail()
+		}
+	}
+}
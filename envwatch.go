@@ -0,0 +1,175 @@
+package gyr
+
+import (
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+type EnvWatcherSettings struct {
+	// File to watch. Defaults to [EnvFile].
+	File string
+	// How often the file is checked for changes.
+	Interval time.Duration
+}
+
+func DefaultEnvWatcherSettings() EnvWatcherSettings {
+	return EnvWatcherSettings{
+		File:     EnvFile,
+		Interval: 2 * time.Second,
+	}
+}
+
+func EnvWatchFile(path string) func(*EnvWatcherSettings) {
+	return func(ews *EnvWatcherSettings) {
+		ews.File = path
+	}
+}
+
+func EnvWatchInterval(interval time.Duration) func(*EnvWatcherSettings) {
+	return func(ews *EnvWatcherSettings) {
+		ews.Interval = interval
+	}
+}
+
+// Re-reads an env file when it changes, so long running processes can pick up rotated credentials.
+// Changed values overwrite the variables set from the file and variables removed from the file are unset.
+// Variables that were set by other means, such as the environment of the process, are left alone.
+type EnvWatcher struct {
+	settings  EnvWatcherSettings
+	mx        sync.Mutex
+	callbacks []func(changed []string)
+	values    map[string]string
+	// Names of the variables set from the file.
+	owned    map[string]bool
+	modTime  time.Time
+	size     int64
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// Start watching the env file. The file is loaded once, without overwriting variables that are already set,
+// before watching begins.
+func WatchEnvironment(settings ...SettingsFunc[EnvWatcherSettings]) (*EnvWatcher, error) {
+	watcherSettings := DefaultEnvWatcherSettings()
+	for _, setting := range settings {
+		setting(&watcherSettings)
+	}
+
+	watcher := &EnvWatcher{
+		settings:  watcherSettings,
+		callbacks: make([]func([]string), 0),
+		owned:     make(map[string]bool),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if err := loadEnvironmentFile(watcherSettings.File, false, func(name string) bool {
+		if _, isSet := os.LookupEnv(name); isSet && !watcher.owned[name] {
+			return false
+		}
+		watcher.owned[name] = true
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := watcher.check(); err != nil {
+		return nil, err
+	}
+
+	go watcher.run()
+	return watcher, nil
+}
+
+// Register a callback receiving the names of the variables that changed on each reload.
+func (watcher *EnvWatcher) OnChange(callback func(changed []string)) {
+	watcher.mx.Lock()
+	defer watcher.mx.Unlock()
+	watcher.callbacks = append(watcher.callbacks, callback)
+}
+
+// Stop watching. Blocks until the watcher has stopped, and may be called more than once.
+func (watcher *EnvWatcher) Stop() {
+	watcher.stopOnce.Do(func() { close(watcher.stop) })
+	<-watcher.done
+}
+
+func (watcher *EnvWatcher) run() {
+	defer close(watcher.done)
+	ticker := time.NewTicker(watcher.settings.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-watcher.stop:
+			return
+		case <-ticker.C:
+			changed, err := watcher.check()
+			if err != nil || len(changed) == 0 {
+				continue
+			}
+			watcher.mx.Lock()
+			callbacks := slices.Clone(watcher.callbacks)
+			watcher.mx.Unlock()
+			for _, callback := range callbacks {
+				callback(changed)
+			}
+		}
+	}
+}
+
+// Reload the file if it has been modified since the last check and return the names of the changed variables.
+// The first check only records the current state.
+func (watcher *EnvWatcher) check() ([]string, error) {
+	info, err := os.Stat(watcher.settings.File)
+	if err != nil {
+		return nil, err
+	}
+	if watcher.values != nil && info.ModTime().Equal(watcher.modTime) && info.Size() == watcher.size {
+		return nil, nil
+	}
+
+	file, err := os.Open(watcher.settings.File)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	variables, err := parseEnvironment(file, true)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(variables))
+	for _, variable := range variables {
+		values[variable.name] = variable.value
+	}
+	previous := watcher.values
+	watcher.values = values
+	watcher.modTime = info.ModTime()
+	watcher.size = info.Size()
+	if previous == nil {
+		return nil, nil
+	}
+
+	changed := make([]string, 0)
+	for name, value := range values {
+		if previousValue, existed := previous[name]; existed && previousValue == value {
+			continue
+		}
+		if _, isSet := os.LookupEnv(name); isSet && !watcher.owned[name] {
+			continue
+		}
+		os.Setenv(name, value)
+		watcher.owned[name] = true
+		changed = append(changed, name)
+	}
+	for name := range previous {
+		if _, exists := values[name]; !exists && watcher.owned[name] {
+			os.Unsetenv(name)
+			delete(watcher.owned, name)
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+	return changed, nil
+}
@@ -0,0 +1,163 @@
+package gyr
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// An application error with a stable machine readable code. Errors are defined once with [DefineError] and
+// rendered by [Response.Error].
+//
+//	var ErrOrderClosed = gyr.DefineError("order_closed", http.StatusConflict, "The order is closed")
+//
+//	return ctx.Response().Error(ErrOrderClosed.With("order_id", id))
+type Error struct {
+	Code    string
+	Message string
+	Status  int
+	// Extra details sent to the client, such as the id of the missing resource.
+	Meta  map[string]any
+	cause error
+}
+
+var (
+	errorCatalogueMx sync.RWMutex
+	errorCatalogue   = make(map[string]*Error)
+)
+
+var (
+	ErrorBadRequest   = DefineError("bad_request", http.StatusBadRequest, "The request is invalid")
+	ErrorUnauthorized = DefineError("unauthorized", http.StatusUnauthorized, "Authentication is required")
+	ErrorForbidden    = DefineError("forbidden", http.StatusForbidden, "Access is denied")
+	ErrorNotFound     = DefineError("not_found", http.StatusNotFound, "The resource was not found")
+	ErrorConflict     = DefineError("conflict", http.StatusConflict, "The request conflicts with the current state")
+	ErrorInternal     = DefineError("internal_error", http.StatusInternalServerError, "Internal Server Error")
+)
+
+// Add an error to the catalogue. Panics if code is already defined, since codes must be unique.
+func DefineError(code string, status int, message string) *Error {
+	errorCatalogueMx.Lock()
+	defer errorCatalogueMx.Unlock()
+	if _, exists := errorCatalogue[code]; exists {
+		panic("error code already defined: " + code)
+	}
+	err := &Error{Code: code, Message: message, Status: status}
+	errorCatalogue[code] = err
+	return err
+}
+
+// The error defined with code, or nil.
+func LookupError(code string) *Error {
+	errorCatalogueMx.RLock()
+	defer errorCatalogueMx.RUnlock()
+	return errorCatalogue[code]
+}
+
+// Every defined error sorted by code, for documenting the codes of an API.
+func ErrorCatalogue() []*Error {
+	errorCatalogueMx.RLock()
+	defer errorCatalogueMx.RUnlock()
+	errs := slices.Collect(maps.Values(errorCatalogue))
+	slices.SortFunc(errs, func(a, b *Error) int { return strings.Compare(a.Code, b.Code) })
+	return errs
+}
+
+func (err *Error) Error() string {
+	if err.cause != nil {
+		return fmt.Sprintf("%s: %s: %v", err.Code, err.Message, err.cause)
+	}
+	return err.Code + ": " + err.Message
+}
+
+func (err *Error) Unwrap() error {
+	return err.cause
+}
+
+// Errors match when they have the same code, so errors.Is(err, ErrorNotFound) holds for copies made with
+// [Error.With] and [Error.Wrap].
+func (err *Error) Is(target error) bool {
+	var targetErr *Error
+	return errors.As(target, &targetErr) && targetErr.Code == err.Code
+}
+
+// A copy of the error with key added to its metadata.
+func (err *Error) With(key string, value any) *Error {
+	copied := *err
+	copied.Meta = maps.Clone(err.Meta)
+	if copied.Meta == nil {
+		copied.Meta = make(map[string]any)
+	}
+	copied.Meta[key] = value
+	return &copied
+}
+
+// A copy of the error caused by cause. The cause is logged but not sent to the client.
+func (err *Error) Wrap(cause error) *Error {
+	copied := *err
+	copied.cause = cause
+	return &copied
+}
+
+// A copy of the error with another message.
+func (err *Error) WithMessage(message string) *Error {
+	copied := *err
+	copied.Message = message
+	return &copied
+}
+
+// Body of the responses written by [Response.Error].
+type ErrorBody struct {
+	Error   string         `json:"error"`
+	Message string         `json:"message"`
+	Meta    map[string]any `json:"meta,omitempty"`
+}
+
+var errorPageTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{ .Status }} {{ .Message }}</title></head>
+<body>
+<h1>{{ .Status }} {{ .Message }}</h1>
+<p><code>{{ .Code }}</code></p>
+</body>
+</html>
+`))
+
+// Respond with err. An [Error] is sent with its status and code, any other error as [ErrorInternal] and
+// logged. The body is an [ErrorBody] in JSON, or an HTML page when the request accepts HTML but not JSON.
+func (r *Response) Error(err error) *Response {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		appErr = ErrorInternal.Wrap(err)
+	}
+	if appErr.Status >= http.StatusInternalServerError {
+		LoggerFrom(r.ctx.Request.Context()).Error("Request failed", "code", appErr.Code, "err", err)
+	}
+
+	r.Status(appErr.Status)
+	if prefersHTML(r.ctx.Request.Header.Get("Accept")) {
+		sb := strings.Builder{}
+		errorPageTemplate.Execute(&sb, appErr)
+		return r.Html(sb.String())
+	}
+	return r.Json(ErrorBody{Error: appErr.Code, Message: appErr.Message, Meta: appErr.Meta})
+}
+
+// Reports whether accept lists text/html before any JSON type, as browsers do.
+func prefersHTML(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(strings.TrimSpace(mediaRange), ";")
+		switch {
+		case mediaType == "text/html":
+			return true
+		case strings.HasSuffix(mediaType, "json"), mediaType == "*/*":
+			return false
+		}
+	}
+	return false
+}
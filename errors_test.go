@@ -0,0 +1,65 @@
+package gyr_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+var errOrderClosed = gyr.DefineError("order_closed", http.StatusConflict, "The order is closed")
+
+func TestErrorResponses(t *testing.T) {
+	router := defaultTestRouter()
+	router.Path("/orders/:id").Post(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Error(errOrderClosed.With("order_id", ctx.IntVariable("id")))
+	})
+	router.Path("/broken").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Error(errors.New("database is down"))
+	})
+
+	gyrtest.Post("/orders/7").Send(router).
+		AssertStatus(t, http.StatusConflict).
+		AssertJSON(t, map[string]any{"error": "order_closed", "message": "The order is closed", "meta": map[string]any{"order_id": 7}})
+
+	response := gyrtest.Get("/broken").Send(router).
+		AssertStatus(t, http.StatusInternalServerError).
+		AssertJSON(t, map[string]any{"error": "internal_error", "message": "Internal Server Error"})
+	if strings.Contains(response.Body.String(), "database") {
+		t.Log("Expected the cause not to be sent to the client")
+		t.FailNow()
+	}
+
+	page := gyrtest.Post("/orders/7").Header("Accept", "text/html,application/xhtml+xml,*/*;q=0.8").Send(router).
+		AssertHeader(t, "Content-Type", "text/html")
+	if !strings.Contains(page.Body.String(), "<code>order_closed</code>") {
+		t.Logf("Expected HTML error page. Received %s\n", page.Body.String())
+		t.FailNow()
+	}
+}
+
+func TestErrorCatalogue(t *testing.T) {
+	wrapped := gyr.ErrorNotFound.With("id", 3).Wrap(errors.New("no rows"))
+	if !errors.Is(wrapped, gyr.ErrorNotFound) || errors.Is(wrapped, gyr.ErrorConflict) {
+		t.Log("Expected errors to match by code")
+		t.FailNow()
+	}
+	if gyr.ErrorNotFound.Meta != nil {
+		t.Log("Expected With to leave the defined error unchanged")
+		t.FailNow()
+	}
+	if gyr.LookupError("order_closed") != errOrderClosed {
+		t.Log("Expected defined error to be looked up by code")
+		t.FailNow()
+	}
+	defer func() {
+		if recover() == nil {
+			t.Log("Expected redefining a code to panic")
+			t.FailNow()
+		}
+	}()
+	gyr.DefineError("not_found", http.StatusNotFound, "again")
+}
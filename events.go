@@ -0,0 +1,189 @@
+package gyr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Returned by Publish after [EventBus.Shutdown] has been called.
+var ErrEventBusClosed = errors.New("event bus is shut down")
+
+type EventBusSettings struct {
+	// Stop delivering an event to the remaining synchronous subscribers after the first one returns an error.
+	StopOnError bool
+	// Receives errors and panics from asynchronous subscribers. Defaults to logging them.
+	OnError func(event any, err error)
+}
+
+func DefaultEventBusSettings() EventBusSettings {
+	return EventBusSettings{
+		OnError: func(event any, err error) {
+			Logger().Error("Event subscriber failed", "component", "events", "event", reflect.TypeOf(event).String(), "error", err)
+		},
+	}
+}
+
+func EventStopOnError() func(*EventBusSettings) {
+	return func(ebs *EventBusSettings) {
+		ebs.StopOnError = true
+	}
+}
+
+func EventOnError(handler func(event any, err error)) func(*EventBusSettings) {
+	return func(ebs *EventBusSettings) {
+		ebs.OnError = handler
+	}
+}
+
+type SubscriptionSettings struct {
+	// Deliver events in a separate goroutine instead of during Publish.
+	Async bool
+}
+
+func EventAsync() func(*SubscriptionSettings) {
+	return func(ss *SubscriptionSettings) {
+		ss.Async = true
+	}
+}
+
+type subscription struct {
+	id      int
+	async   bool
+	handler func(context.Context, any) error
+}
+
+// In-process publish/subscribe of typed events. Subscribers are matched on the exact type of the event.
+type EventBus struct {
+	Settings    EventBusSettings
+	mx          sync.RWMutex
+	subscribers map[reflect.Type][]subscription
+	nextID      int
+	inFlight    sync.WaitGroup
+	closed      bool
+}
+
+// Bus used by [Subscribe] and [Publish].
+var DefaultEventBus = NewEventBus()
+
+func NewEventBus(settings ...SettingsFunc[EventBusSettings]) *EventBus {
+	busSettings := DefaultEventBusSettings()
+	for _, setting := range settings {
+		setting(&busSettings)
+	}
+	return &EventBus{
+		Settings:    busSettings,
+		subscribers: make(map[reflect.Type][]subscription),
+	}
+}
+
+// Subscribe to events of type T on the [DefaultEventBus]. Call the returned function to unsubscribe.
+func Subscribe[T any](handler func(context.Context, T) error, settings ...SettingsFunc[SubscriptionSettings]) func() {
+	return SubscribeTo(DefaultEventBus, handler, settings...)
+}
+
+// Subscribe to events of type T on bus. Call the returned function to unsubscribe.
+func SubscribeTo[T any](bus *EventBus, handler func(context.Context, T) error, settings ...SettingsFunc[SubscriptionSettings]) func() {
+	var subscriptionSettings SubscriptionSettings
+	for _, setting := range settings {
+		setting(&subscriptionSettings)
+	}
+
+	eventType := reflect.TypeFor[T]()
+	bus.mx.Lock()
+	defer bus.mx.Unlock()
+	bus.nextID++
+	id := bus.nextID
+	bus.subscribers[eventType] = append(bus.subscribers[eventType], subscription{
+		id:    id,
+		async: subscriptionSettings.Async,
+		handler: func(ctx context.Context, event any) error {
+			return handler(ctx, event.(T))
+		},
+	})
+
+	return func() {
+		bus.mx.Lock()
+		defer bus.mx.Unlock()
+		bus.subscribers[eventType] = slices.DeleteFunc(bus.subscribers[eventType], func(s subscription) bool {
+			return s.id == id
+		})
+	}
+}
+
+// Publish an event on the [DefaultEventBus].
+func Publish[T any](ctx context.Context, event T) error {
+	return PublishTo(ctx, DefaultEventBus, event)
+}
+
+// Publish an event on bus. Synchronous subscribers run before PublishTo returns and their errors are returned
+// joined together. Asynchronous subscribers report errors to [EventBusSettings.OnError].
+func PublishTo[T any](ctx context.Context, bus *EventBus, event T) error {
+	bus.mx.RLock()
+	if bus.closed {
+		bus.mx.RUnlock()
+		return ErrEventBusClosed
+	}
+	subscribers := slices.Clone(bus.subscribers[reflect.TypeFor[T]()])
+	for _, s := range subscribers {
+		if s.async {
+			bus.inFlight.Add(1)
+		}
+	}
+	bus.mx.RUnlock()
+
+	errs := make([]error, 0)
+	stopped := false
+	for _, s := range subscribers {
+		// Asynchronous subscribers are started even after a failure since they were counted in inFlight above.
+		if s.async {
+			go func() {
+				defer bus.inFlight.Done()
+				// The publisher's context may be cancelled as soon as Publish returns.
+				if err := deliverEvent(context.WithoutCancel(ctx), s, event); err != nil && bus.Settings.OnError != nil {
+					bus.Settings.OnError(event, err)
+				}
+			}()
+			continue
+		}
+		if stopped {
+			continue
+		}
+		if err := deliverEvent(ctx, s, event); err != nil {
+			errs = append(errs, err)
+			stopped = bus.Settings.StopOnError
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func deliverEvent(ctx context.Context, s subscription, event any) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("event subscriber panicked: %v", recovered)
+		}
+	}()
+	return s.handler(ctx, event)
+}
+
+// Stop accepting events and wait for asynchronous deliveries to finish, or until ctx is done.
+func (bus *EventBus) Shutdown(ctx context.Context) error {
+	bus.mx.Lock()
+	bus.closed = true
+	bus.mx.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		bus.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,155 @@
+package gyr_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+type orderCreated struct {
+	ID int
+}
+
+type orderCancelled struct {
+	ID int
+}
+
+func TestEventBusSyncDelivery(t *testing.T) {
+	bus := gyr.NewEventBus()
+	received := 0
+	gyr.SubscribeTo(bus, func(ctx context.Context, evt orderCreated) error {
+		received = evt.ID
+		return nil
+	})
+	gyr.SubscribeTo(bus, func(ctx context.Context, evt orderCancelled) error {
+		t.Log("Cancelled subscriber received created event")
+		t.Fail()
+		return nil
+	})
+	if err := gyr.PublishTo(context.Background(), bus, orderCreated{ID: 7}); err != nil || received != 7 {
+		t.Logf("Expected 7. Received %d (%v)\n", received, err)
+		t.FailNow()
+	}
+}
+
+func TestEventBusErrors(t *testing.T) {
+	failure := errors.New("failed")
+	t.Run("joined", func(t *testing.T) {
+		bus := gyr.NewEventBus()
+		calls := 0
+		for i := 0; i < 2; i++ {
+			gyr.SubscribeTo(bus, func(ctx context.Context, evt orderCreated) error {
+				calls++
+				return failure
+			})
+		}
+		if err := gyr.PublishTo(context.Background(), bus, orderCreated{}); !errors.Is(err, failure) || calls != 2 {
+			t.Logf("Calls %d, error %v\n", calls, err)
+			t.FailNow()
+		}
+	})
+	t.Run("stop on error", func(t *testing.T) {
+		bus := gyr.NewEventBus(gyr.EventStopOnError())
+		calls := 0
+		for i := 0; i < 2; i++ {
+			gyr.SubscribeTo(bus, func(ctx context.Context, evt orderCreated) error {
+				calls++
+				return failure
+			})
+		}
+		gyr.PublishTo(context.Background(), bus, orderCreated{})
+		if calls != 1 {
+			t.Logf("Expected 1 call. Received %d\n", calls)
+			t.FailNow()
+		}
+	})
+}
+
+func TestEventBusAsyncAndShutdown(t *testing.T) {
+	var asyncErrors atomic.Int32
+	bus := gyr.NewEventBus(gyr.EventOnError(func(event any, err error) {
+		asyncErrors.Add(1)
+	}))
+	var delivered atomic.Bool
+	gyr.SubscribeTo(bus, func(ctx context.Context, evt orderCreated) error {
+		time.Sleep(10 * time.Millisecond)
+		delivered.Store(true)
+		return errors.New("async failure")
+	}, gyr.EventAsync())
+
+	if err := gyr.PublishTo(context.Background(), bus, orderCreated{ID: 1}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := bus.Shutdown(context.Background()); err != nil || !delivered.Load() || asyncErrors.Load() != 1 {
+		t.Logf("Expected shutdown to drain async delivery (%v)\n", err)
+		t.FailNow()
+	}
+	if err := gyr.PublishTo(context.Background(), bus, orderCreated{}); !errors.Is(err, gyr.ErrEventBusClosed) {
+		t.Logf("Expected ErrEventBusClosed. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestEventBusLogsErrorsWithGyrLogger(t *testing.T) {
+	previous := gyr.Logger()
+	defer gyr.SetLogger(previous)
+	buffer := &bytes.Buffer{}
+	gyr.SetLogger(slog.New(slog.NewTextHandler(buffer, nil)))
+
+	bus := gyr.NewEventBus()
+	gyr.SubscribeTo(bus, func(ctx context.Context, evt orderCreated) error {
+		return errors.New("async failure")
+	}, gyr.EventAsync())
+	gyr.PublishTo(context.Background(), bus, orderCreated{ID: 1})
+	bus.Shutdown(context.Background())
+
+	if logged := buffer.String(); !strings.Contains(logged, "component=events") || !strings.Contains(logged, "async failure") {
+		t.Logf("Expected the failure to be logged by the gyr logger. Logged %q\n", logged)
+		t.FailNow()
+	}
+}
+
+func TestEventBusShutdownAfterSyncFailure(t *testing.T) {
+	bus := gyr.NewEventBus(gyr.EventStopOnError())
+	gyr.SubscribeTo(bus, func(ctx context.Context, evt orderCreated) error {
+		panic("sync failure")
+	})
+	var delivered atomic.Bool
+	gyr.SubscribeTo(bus, func(ctx context.Context, evt orderCreated) error {
+		delivered.Store(true)
+		return nil
+	}, gyr.EventAsync())
+
+	if err := gyr.PublishTo(context.Background(), bus, orderCreated{}); err == nil {
+		t.Log("Expected the panic of the sync subscriber as error")
+		t.FailNow()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := bus.Shutdown(ctx); err != nil || !delivered.Load() {
+		t.Logf("Expected shutdown to finish after delivering to the async subscriber (%v)\n", err)
+		t.FailNow()
+	}
+}
+
+func TestEventBusUnsubscribe(t *testing.T) {
+	bus := gyr.NewEventBus()
+	calls := 0
+	unsubscribe := gyr.SubscribeTo(bus, func(ctx context.Context, evt orderCreated) error {
+		calls++
+		return nil
+	})
+	unsubscribe()
+	gyr.PublishTo(context.Background(), bus, orderCreated{})
+	if calls != 0 {
+		t.FailNow()
+	}
+}
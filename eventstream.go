@@ -0,0 +1,80 @@
+package gyr
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidEventName = errors.New("event name contains a line break")
+
+// A response of server-sent events, written to the client as they are sent. Get one with
+// [Response.EventStream].
+//
+//	events := ctx.Response().EventStream()
+//	for {
+//		select {
+//		case <-events.Closing():
+//			return events.End()
+//		case <-events.Context().Done():
+//			return events.End()
+//		case message := <-messages:
+//			events.SendEvent("message", message)
+//		}
+//	}
+type EventStream struct {
+	stream *Stream
+}
+
+// Start a text/event-stream response. Headers set on r are sent with the first event.
+func (r *Response) EventStream() *EventStream {
+	header := r.w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	// Stops nginx from buffering the events.
+	header.Set("X-Accel-Buffering", "no")
+	return &EventStream{stream: r.ctx.Stream()}
+}
+
+// Send an event named name, or an unnamed message event when name is empty, and flush it to the client. data may
+// span several lines. Returns the error of the request context once the client has disconnected.
+func (events *EventStream) SendEvent(name string, data string) error {
+	if strings.ContainsAny(name, "\r\n") {
+		return ErrInvalidEventName
+	}
+	var event strings.Builder
+	if name != "" {
+		event.WriteString("event: " + name + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		event.WriteString("data: " + line + "\n")
+	}
+	event.WriteString("\n")
+	return events.stream.Write([]byte(event.String()))
+}
+
+// Send a comment, which clients ignore, to keep the connection open through idle timeouts of proxies.
+func (events *EventStream) KeepAlive() error {
+	return events.stream.Write([]byte(":\n\n"))
+}
+
+// Closed when the router starts shutting down, see [Stream.Closing].
+func (events *EventStream) Closing() <-chan struct{} {
+	return events.stream.Closing()
+}
+
+// Done when the client disconnects or the grace period of a shutdown has passed.
+func (events *EventStream) Context() context.Context {
+	return events.stream.Context()
+}
+
+// The response to return from the handler once the stream is finished. Sends a goaway event first if the router
+// is shutting down, so clients know to reconnect to another instance.
+func (events *EventStream) End() *Response {
+	select {
+	case <-events.stream.Closing():
+		events.SendEvent("goaway", "")
+	default:
+	}
+	return events.stream.End()
+}
@@ -0,0 +1,81 @@
+package gyr_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestEventStream(t *testing.T) {
+	router := defaultTestRouter()
+	disconnected := make(chan error, 1)
+	router.Path("/events").Get(func(ctx *gyr.Context) *gyr.Response {
+		events := ctx.Response().EventStream()
+		events.SendEvent("greeting", "hello\nworld")
+		events.SendEvent("", "plain")
+		<-events.Context().Done()
+		disconnected <- events.SendEvent("late", "")
+		return events.End()
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Logf("Request failed: %v", err)
+		t.FailNow()
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Logf("Expected text/event-stream. Received %q", contentType)
+		t.FailNow()
+	}
+	reader := bufio.NewReader(response.Body)
+	expected := "event: greeting\ndata: hello\ndata: world\n\ndata: plain\n\n"
+	received := make([]byte, len(expected))
+	if _, err := io.ReadFull(reader, received); err != nil || string(received) != expected {
+		t.Logf("Expected the events before the handler returned. Received %q %v", received, err)
+		t.FailNow()
+	}
+
+	response.Body.Close()
+	if err := <-disconnected; err == nil {
+		t.Log("Expected an error sending after the client disconnected")
+		t.FailNow()
+	}
+}
+
+func TestEventStreamGoaway(t *testing.T) {
+	router := defaultTestRouter()
+	router.Path("/events").Get(func(ctx *gyr.Context) *gyr.Response {
+		events := ctx.Response().EventStream()
+		events.SendEvent("", "hello")
+		<-events.Closing()
+		return events.End()
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Logf("Request failed: %v", err)
+		t.FailNow()
+	}
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+	reader.ReadString('\n')
+	reader.ReadString('\n')
+	if err := router.Shutdown(context.Background()); err != nil {
+		t.Logf("Shutdown failed: %v", err)
+		t.FailNow()
+	}
+	rest, _ := io.ReadAll(reader)
+	if string(rest) != "event: goaway\ndata: \n\n" {
+		t.Logf("Expected a goaway event. Received %q", rest)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,87 @@
+package gyr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Render every GET route without path variables and write the responses to dir as a static site. Routes
+// without a file extension are written as an index.html in a directory of their own when they respond with
+// HTML, and otherwise get the extension of their Content-Type. Routes that don't respond with 200 are skipped
+// and reported in the returned error.
+func (router *Router) Export(dir string) error {
+	var errs []error
+	for _, route := range router.Routes() {
+		if _, hasGet := route.Route.handlers[http.MethodGet]; !hasGet || len(route.Route.variables) > 0 {
+			continue
+		}
+		urlPath := path.Clean("/" + route.Path)
+		if strings.HasSuffix(route.Path, "/") && urlPath != "/" {
+			urlPath += "/"
+		}
+
+		writer := &exportWriter{header: make(http.Header), status: http.StatusOK}
+		request, err := http.NewRequest(http.MethodGet, urlPath, nil)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		router.ServeHTTP(writer, request)
+		if writer.status != http.StatusOK {
+			errs = append(errs, fmt.Errorf("%s responded with %d", urlPath, writer.status))
+			continue
+		}
+
+		file := filepath.Join(dir, filepath.FromSlash(exportFileName(urlPath, writer.header.Get("Content-Type"))))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, writer.body.Bytes(), 0o644); err != nil {
+			return err
+		}
+		router.logger.Info("Exported route", "path", urlPath, "file", file)
+	}
+	return errors.Join(errs...)
+}
+
+// The file an exported path is written to, relative to the export directory.
+func exportFileName(urlPath string, contentType string) string {
+	if path.Ext(urlPath) != "" && !strings.HasSuffix(urlPath, "/") {
+		return strings.TrimPrefix(urlPath, "/")
+	}
+	mimetype := parseContentType(contentType).mimetype
+	if mimetype == "text/html" || mimetype == "" {
+		return strings.TrimPrefix(path.Join(urlPath, "index.html"), "/")
+	}
+	extension := ""
+	if extensions, _ := mime.ExtensionsByType(mimetype); len(extensions) > 0 {
+		extension = extensions[0]
+	}
+	return strings.TrimPrefix(strings.TrimSuffix(urlPath, "/"), "/") + extension
+}
+
+// Collects the response of a route for Export.
+type exportWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *exportWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *exportWriter) Write(content []byte) (int, error) {
+	return w.body.Write(content)
+}
+
+func (w *exportWriter) WriteHeader(status int) {
+	w.status = status
+}
@@ -0,0 +1,42 @@
+package gyr_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestExport(t *testing.T) {
+	router := gyr.DefaultRouter()
+	router.Path("/").Get(func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Html("<h1>Home</h1>") })
+	router.Path("/docs/intro").Get(func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Html("<h1>Intro</h1>") })
+	router.Path("/feed").Get(func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Json([]string{"post"}) })
+	router.Path("/robots.txt").Get(func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Text("User-agent: *") })
+	router.Path("/posts/:id").Get(func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Html("post") })
+	router.Path("/contact").Post(func(ctx *gyr.Context) *gyr.Response { return ctx.Response() })
+
+	dir := t.TempDir()
+	if err := router.Export(dir); err != nil {
+		t.Logf("Export failed: %v\n", err)
+		t.FailNow()
+	}
+	expected := map[string]string{
+		"index.html":            "<h1>Home</h1>",
+		"docs/intro/index.html": "<h1>Intro</h1>",
+		"feed.json":             `["post"]`,
+		"robots.txt":            "User-agent: *",
+	}
+	for file, content := range expected {
+		written, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil || string(written) != content {
+			t.Logf("Expected %s to contain %q. Received %q (%v)\n", file, content, written, err)
+			t.FailNow()
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "posts")); !os.IsNotExist(err) {
+		t.Log("Expected routes with variables to be skipped")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,198 @@
+package gyr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// A database/sql driver that accepts every statement, recording the executed statements.
+// Queries return no rows unless rows have been set up with respond, and fail if set up with fail. Queries on
+// a database opened with the name "down" fail with a bad connection.
+type fakeDriver struct {
+	mx         sync.Mutex
+	statements []string
+	// Arguments of the executed statements, in the same order.
+	arguments [][]driver.Value
+	// Queries prefixed with the name the database was opened with.
+	queried   []string
+	responses map[string][][]driver.Value
+	columns   map[string][]string
+	failing   []string
+}
+
+var testDriver = &fakeDriver{}
+
+func init() {
+	sql.Register("gyr_fake", testDriver)
+}
+
+func openFakeDB() *sql.DB {
+	return openNamedFakeDB("")
+}
+
+func openNamedFakeDB(name string) *sql.DB {
+	testDriver.mx.Lock()
+	testDriver.statements = nil
+	testDriver.arguments = nil
+	testDriver.queried = nil
+	testDriver.failing = nil
+	testDriver.responses = make(map[string][][]driver.Value)
+	testDriver.columns = make(map[string][]string)
+	testDriver.mx.Unlock()
+	db, _ := sql.Open("gyr_fake", name)
+	return db
+}
+
+// Return rows for queries starting with queryPrefix.
+func (d *fakeDriver) respond(queryPrefix string, rows ...[]driver.Value) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.responses[queryPrefix] = rows
+}
+
+// Like respond but with named columns.
+func (d *fakeDriver) respondColumns(queryPrefix string, columns []string, rows ...[]driver.Value) {
+	d.respond(queryPrefix, rows...)
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.columns[queryPrefix] = columns
+}
+
+// Make queries starting with queryPrefix fail.
+func (d *fakeDriver) fail(queryPrefix string) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.failing = append(d.failing, queryPrefix)
+}
+
+// Arguments of the executed statements starting with prefix.
+func (d *fakeDriver) executedWith(prefix string) [][]driver.Value {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	arguments := make([][]driver.Value, 0)
+	for i, statement := range d.statements {
+		if strings.HasPrefix(statement, prefix) {
+			arguments = append(arguments, d.arguments[i])
+		}
+	}
+	return arguments
+}
+
+func (d *fakeDriver) executed() []string {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	return append([]string{}, d.statements...)
+}
+
+func (d *fakeDriver) queries() []string {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	return append([]string{}, d.queried...)
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{driver: d, name: name}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+	name   string
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.driver.mx.Lock()
+	defer s.conn.driver.mx.Unlock()
+	s.conn.driver.statements = append(s.conn.driver.statements, s.query)
+	s.conn.driver.arguments = append(s.conn.driver.arguments, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if s.conn.name == "down" {
+		return nil, driver.ErrBadConn
+	}
+	s.conn.driver.mx.Lock()
+	defer s.conn.driver.mx.Unlock()
+	s.conn.driver.queried = append(s.conn.driver.queried, s.conn.name+": "+s.query)
+	for _, prefix := range s.conn.driver.failing {
+		if strings.HasPrefix(s.query, prefix) {
+			return nil, errors.New("fake query failure")
+		}
+	}
+	for prefix, rows := range s.conn.driver.responses {
+		if strings.HasPrefix(s.query, prefix) {
+			return &fakeRows{rows: rows, columns: s.conn.driver.columns[prefix]}, nil
+		}
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct {
+	rows    [][]driver.Value
+	columns []string
+}
+
+func (r *fakeRows) Columns() []string {
+	if r.columns != nil {
+		return r.columns
+	}
+	if len(r.rows) == 0 {
+		return []string{"value"}
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
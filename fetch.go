@@ -0,0 +1,121 @@
+package gyr
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+)
+
+// Timeout for queries run with [Fetch], [FetchOne] and [Execute] unless overridden with [WithQueryTimeout].
+// The deadline of the context passed in, such as that of the HTTP request, applies when it is earlier.
+var DefaultQueryTimeout = 30 * time.Second
+
+type queryTimeoutKey struct{}
+
+// Use timeout instead of [DefaultQueryTimeout] for queries run with ctx. 0 disables the timeout, leaving only
+// the deadline of ctx itself.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
+
+func queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout, isSet := ctx.Value(queryTimeoutKey{}).(time.Duration)
+	if !isSet {
+		timeout = DefaultQueryTimeout
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Run query and scan every row into an EntityType, matching the result columns with the gyr_column tags
+// of the entity. Columns without a matching field are ignored.
+func Fetch[EntityType any](ctx context.Context, db DBTX, query string, args ...any) ([]EntityType, error) {
+	queryCtx, cancel := queryContext(ctx)
+	defer cancel()
+
+	logQuery(ctx, query)
+	rows, err := db.QueryContext(queryCtx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	fieldIndexes := columnFieldIndexes(reflect.TypeFor[EntityType](), columns)
+	entities := make([]EntityType, 0)
+	for rows.Next() {
+		var entity EntityType
+		value := reflect.ValueOf(&entity).Elem()
+		targets := make([]any, len(columns))
+		for i, fieldIndex := range fieldIndexes {
+			if fieldIndex == -1 {
+				targets[i] = new(any)
+			} else {
+				targets[i] = value.Field(fieldIndex).Addr().Interface()
+			}
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+		entities = append(entities, entity)
+	}
+	return entities, rows.Err()
+}
+
+// Like [Fetch] but returns only the first row, or [sql.ErrNoRows] if there is none.
+func FetchOne[EntityType any](ctx context.Context, db DBTX, query string, args ...any) (EntityType, error) {
+	var entity EntityType
+	entities, err := Fetch[EntityType](ctx, db, query, args...)
+	if err != nil {
+		return entity, err
+	}
+	if len(entities) == 0 {
+		return entity, sql.ErrNoRows
+	}
+	return entities[0], nil
+}
+
+// Run a statement that doesn't return rows, with the same timeout as [Fetch].
+func Execute(ctx context.Context, db DBTX, query string, args ...any) (sql.Result, error) {
+	queryCtx, cancel := queryContext(ctx)
+	defer cancel()
+	logQuery(ctx, query)
+	return db.ExecContext(queryCtx, query, args...)
+}
+
+// Log query at debug level with the attributes of ctx, such as the request ID.
+func logQuery(ctx context.Context, query string) {
+	if Logger().Enabled(ctx, slog.LevelDebug) {
+		LoggerFrom(ctx).Debug("Running query", "component", "sql", "query", query)
+	}
+}
+
+// The index of the field tagged with each column, or -1 for columns without a field.
+func columnFieldIndexes(entityType reflect.Type, columns []string) []int {
+	if entityType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("can only fetch into structs, received %s", entityType))
+	}
+	fields := make(map[string]int)
+	for i := 0; i < entityType.NumField(); i++ {
+		if column, hasTag := entityType.Field(i).Tag.Lookup(gyr_column_tag); hasTag {
+			fields[column] = i
+		}
+	}
+	indexes := make([]int, len(columns))
+	for i, column := range columns {
+		index, exists := fields[column]
+		if !exists {
+			index = -1
+		}
+		indexes[i] = index
+	}
+	return indexes
+}
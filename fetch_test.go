@@ -0,0 +1,71 @@
+package gyr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fetchedUser struct {
+	ID   int64  `gyr_column:"id"`
+	Name string `gyr_column:"name"`
+}
+
+func TestFetch(t *testing.T) {
+	db := openFakeDB()
+	testDriver.respondColumns("select id, name, age", []string{"id", "name", "age"},
+		[]driver.Value{int64(1), "kalle", int64(30)},
+		[]driver.Value{int64(2), "lisa", int64(25)},
+	)
+
+	users, err := Fetch[fetchedUser](context.Background(), db, "select id, name, age from users")
+	if err != nil || len(users) != 2 || users[1] != (fetchedUser{ID: 2, Name: "lisa"}) {
+		t.Logf("Received %+v (%v)\n", users, err)
+		t.FailNow()
+	}
+
+	if _, err := FetchOne[fetchedUser](context.Background(), db, "select id from nothing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Logf("Expected sql.ErrNoRows. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestFetchIsCancelledWithContext(t *testing.T) {
+	db := openFakeDB()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Fetch[fetchedUser](ctx, db, "select id, name from users"); !errors.Is(err, context.Canceled) {
+		t.Logf("Expected context.Canceled. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestQueryContextTimeouts(t *testing.T) {
+	deadlineIn := func(ctx context.Context) time.Duration {
+		queryCtx, cancel := queryContext(ctx)
+		defer cancel()
+		deadline, hasDeadline := queryCtx.Deadline()
+		if !hasDeadline {
+			return 0
+		}
+		return time.Until(deadline).Round(time.Second)
+	}
+
+	if received := deadlineIn(context.Background()); received != DefaultQueryTimeout {
+		t.Logf("Expected default timeout. Received %v\n", received)
+		t.FailNow()
+	}
+	if received := deadlineIn(WithQueryTimeout(context.Background(), 2*time.Second)); received != 2*time.Second {
+		t.Logf("Expected per-query timeout. Received %v\n", received)
+		t.FailNow()
+	}
+	requestCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if received := deadlineIn(WithQueryTimeout(requestCtx, time.Minute)); received != time.Second {
+		t.Logf("Expected the earlier request deadline. Received %v\n", received)
+		t.FailNow()
+	}
+}
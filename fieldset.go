@@ -0,0 +1,142 @@
+package gyr
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const fieldsMetaKey = "gyr.fields"
+
+// Restrict the fields a request may select with ?fields=id,name to allowed. Requests selecting other fields
+// get 400 Bad Request. Without an allowlist every field can be selected.
+func (route *Route) Fields(allowed ...string) *Route {
+	return route.Set(fieldsMetaKey, allowed)
+}
+
+// The fields selected with the fields query parameter, or nil when the request doesn't select any.
+func (r *Response) requestedFields() ([]string, error) {
+	if r.ctx == nil || r.ctx.Request == nil || r.status < 200 || r.status > 299 {
+		return nil, nil
+	}
+	param := r.ctx.Request.URL.Query().Get("fields")
+	if param == "" {
+		return nil, nil
+	}
+	allowed, hasAllowlist := RouteMeta[[]string](r.ctx, fieldsMetaKey)
+	fields := make([]string, 0)
+	errs := make(ValidationErrors, 0)
+	for _, field := range strings.Split(param, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if hasAllowlist && !slices.Contains(allowed, field) {
+			errs = append(errs, ValidationError{Field: "fields", Rule: "fields", Param: field, Message: "can not select " + field, Code: validationCode("fields")})
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return fields, nil
+}
+
+// Keep only fields of value, by their JSON names. Slices are filtered element by element. Struct fields tagged
+// `fields:"always"` are always kept. The elements of a field tagged `fields:"items"` are filtered instead of
+// the struct itself, as for the items of a [Page]. Values with their own MarshalJSON are kept whole.
+func sparseFieldset(value reflect.Value, fields []string) any {
+	if !value.IsValid() {
+		return nil
+	}
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Type().Implements(reflect.TypeFor[json.Marshaler]()) {
+		return value.Interface()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return value.Interface()
+		}
+		elements := make([]any, value.Len())
+		for i := range elements {
+			elements[i] = sparseFieldset(value.Index(i), fields)
+		}
+		return elements
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return value.Interface()
+		}
+		filtered := make(map[string]any)
+		for _, key := range value.MapKeys() {
+			if slices.Contains(fields, key.String()) {
+				filtered[key.String()] = value.MapIndex(key).Interface()
+			}
+		}
+		return filtered
+	case reflect.Struct:
+		filtered := make(map[string]any)
+		sparseStruct(value, fields, hasItemsField(value.Type()), filtered)
+		return filtered
+	}
+	return value.Interface()
+}
+
+// Whether the struct wraps a list of items, in which case its other fields are kept whole.
+func hasItemsField(structType reflect.Type) bool {
+	for i := range structType.NumField() {
+		if structType.Field(i).Tag.Get("fields") == "items" {
+			return true
+		}
+	}
+	return false
+}
+
+func sparseStruct(value reflect.Value, fields []string, keepAll bool, filtered map[string]any) {
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		fieldValue := value.Field(i)
+		if field.Anonymous && name == "" {
+			for fieldValue.Kind() == reflect.Pointer && !fieldValue.IsNil() {
+				fieldValue = fieldValue.Elem()
+			}
+			if fieldValue.Kind() == reflect.Struct {
+				sparseStruct(fieldValue, fields, keepAll, filtered)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if slices.Contains(strings.Split(options, ","), "omitempty") && fieldValue.IsZero() {
+			continue
+		}
+
+		switch field.Tag.Get("fields") {
+		case "items":
+			filtered[name] = sparseFieldset(fieldValue, fields)
+		case "always":
+			filtered[name] = fieldValue.Interface()
+		default:
+			if keepAll || slices.Contains(fields, name) {
+				filtered[name] = fieldValue.Interface()
+			}
+		}
+	}
+}
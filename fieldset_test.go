@@ -0,0 +1,40 @@
+package gyr_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+type fieldsetUser struct {
+	ID    int    `json:"id" fields:"always"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func TestSparseFieldset(t *testing.T) {
+	router := defaultTestRouter()
+	users := []fieldsetUser{{ID: 1, Name: "kalle", Email: "kalle@example.com"}}
+	router.Path("/users").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Json(users)
+	})
+	router.Path("/page").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Json(gyr.Page[fieldsetUser]{Items: users, Page: 1, PageSize: 20})
+	})
+	router.Path("/restricted").Fields("name").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Json(users[0])
+	})
+
+	gyrtest.Get("/users?fields=name").Send(router).
+		AssertStatus(t, http.StatusOK).
+		AssertJSON(t, []map[string]any{{"id": 1, "name": "kalle"}})
+	gyrtest.Get("/users").Send(router).
+		AssertJSON(t, []map[string]any{{"id": 1, "name": "kalle", "email": "kalle@example.com"}})
+	gyrtest.Get("/page?fields=email").Send(router).
+		AssertJSON(t, map[string]any{"items": []map[string]any{{"id": 1, "email": "kalle@example.com"}}, "page": 1, "page_size": 20})
+	gyrtest.Get("/restricted?fields=name").Send(router).
+		AssertJSON(t, map[string]any{"id": 1, "name": "kalle"})
+	gyrtest.Get("/restricted?fields=email").Send(router).AssertStatus(t, http.StatusBadRequest)
+}
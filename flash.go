@@ -0,0 +1,119 @@
+package gyr
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+const (
+	flashSessionKey = "_flash"
+	formSessionKey  = "_form"
+)
+
+// A message shown once on the next page, such as "Your changes were saved".
+type FlashMessage struct {
+	// Kind of message, e.g. "success" or "error", for styling it.
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// Submitted form values and the validation errors of each field, kept for the page the form was redirected to.
+type FormState struct {
+	Values url.Values        `json:"values"`
+	Errors map[string]string `json:"errors"`
+}
+
+// The submitted value of field, for filling in the form again.
+func (form FormState) Value(field string) string {
+	return form.Values.Get(field)
+}
+
+// The validation error of field, or an empty string.
+func (form FormState) Error(field string) string {
+	return form.Errors[field]
+}
+
+// Add a message to show on the next page. Messages are kept in the [Session] until read with [Context.Flashes].
+func (ctx *Context) Flash(kind string, message string) {
+	messages := ctx.peekFlashes()
+	messages = append(messages, FlashMessage{Kind: kind, Message: message})
+	encoded, _ := json.Marshal(messages)
+	ctx.Session().Set(flashSessionKey, string(encoded))
+}
+
+// The messages added with [Context.Flash], removing them from the session. Calls in the same request return
+// the same messages.
+func (ctx *Context) Flashes() []FlashMessage {
+	if messages, read := ctx.Variable(flashSessionKey).([]FlashMessage); read {
+		return messages
+	}
+	messages := ctx.peekFlashes()
+	ctx.Session().Delete(flashSessionKey)
+	ctx.SetVariable(flashSessionKey, messages)
+	return messages
+}
+
+func (ctx *Context) peekFlashes() []FlashMessage {
+	messages := make([]FlashMessage, 0)
+	if encoded := ctx.Session().Get(flashSessionKey); encoded != "" {
+		json.Unmarshal([]byte(encoded), &messages)
+	}
+	return messages
+}
+
+// Keep the submitted form values and the field errors of err, when it is [ValidationErrors], for the page the
+// request redirects to. Password fields are never kept. Read them back with [Context.OldForm].
+//
+//	if err := gyr.Validate(signup); err != nil {
+//		ctx.FlashForm(ctx.Request.PostForm, err)
+//		return ctx.Response().Redirect("/signup")
+//	}
+func (ctx *Context) FlashForm(values url.Values, err error) {
+	form := FormState{Values: url.Values{}, Errors: make(map[string]string)}
+	for field, fieldValues := range values {
+		if !isPasswordField(field) {
+			form.Values[field] = fieldValues
+		}
+	}
+	var errs ValidationErrors
+	if errors.As(err, &errs) {
+		for _, fieldErr := range errs {
+			if _, exists := form.Errors[fieldErr.Field]; !exists {
+				form.Errors[fieldErr.Field] = fieldErr.Message
+			}
+		}
+	}
+	encoded, _ := json.Marshal(form)
+	ctx.Session().Set(formSessionKey, string(encoded))
+}
+
+// The form state kept with [Context.FlashForm], removing it from the session. Empty when there is none.
+func (ctx *Context) OldForm() FormState {
+	if form, read := ctx.Variable(formSessionKey).(FormState); read {
+		return form
+	}
+	form := FormState{Values: url.Values{}, Errors: make(map[string]string)}
+	if encoded := ctx.Session().Get(formSessionKey); encoded != "" {
+		json.Unmarshal([]byte(encoded), &form)
+		ctx.Session().Delete(formSessionKey)
+	}
+	ctx.SetVariable(formSessionKey, form)
+	return form
+}
+
+// Template functions for the flash messages and old form of the request: flashes, old "field" and
+// fieldError "field".
+func FlashFuncs(ctx *Context) template.FuncMap {
+	return template.FuncMap{
+		"flashes":    ctx.Flashes,
+		"old":        func(field string) string { return ctx.OldForm().Value(field) },
+		"fieldError": func(field string) string { return ctx.OldForm().Error(field) },
+	}
+}
+
+func isPasswordField(field string) bool {
+	return strings.Contains(strings.ToLower(field), "password")
+}
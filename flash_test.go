@@ -0,0 +1,75 @@
+package gyr_test
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+type flashSignup struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+func TestFlashAndOldForm(t *testing.T) {
+	page := template.Must(template.New("signup").Funcs(gyr.FlashFuncs(nil)).Parse(
+		`{{ range flashes }}{{ .Kind }}: {{ .Message }};{{ end }}email={{ old "email" }};password={{ old "password" }};error={{ fieldError "email" }}`))
+	router := defaultTestRouter()
+	router.Middleware(gyr.Sessions(gyr.SessionKey([]byte("0123456789abcdef0123456789abcdef")), gyr.SessionInsecure()))
+	router.Path("/signup").Get(func(ctx *gyr.Context) *gyr.Response {
+		var out bytes.Buffer
+		if err := template.Must(page.Clone()).Funcs(gyr.FlashFuncs(ctx)).Execute(&out, nil); err != nil {
+			return ctx.Response().InternalError().Text(err.Error())
+		}
+		return ctx.Response().Text(out.String())
+	}).Post(func(ctx *gyr.Context) *gyr.Response {
+		ctx.Request.ParseForm()
+		signup := flashSignup{Email: ctx.Request.PostForm.Get("email"), Password: ctx.Request.PostForm.Get("password")}
+		if err := gyr.Validate(signup); err != nil {
+			ctx.FlashForm(ctx.Request.PostForm, err)
+			ctx.Flash("error", "Please fix the errors")
+			return ctx.Response().Redirect("/signup")
+		}
+		return ctx.Response().Redirect("/")
+	})
+
+	form := url.Values{"email": {"not-an-email"}, "password": {"secret"}}
+	request := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	posted := gyrtest.Send(router, request)
+	if posted.Code != http.StatusSeeOther || posted.Header().Get("Location") != "/signup" {
+		t.Logf("Expected redirect to /signup. Received %d %s\n", posted.Code, posted.Header().Get("Location"))
+		t.FailNow()
+	}
+	cookies := posted.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Logf("Expected a single session cookie. Received %v\n", cookies)
+		t.FailNow()
+	}
+
+	response := gyrtest.Get("/signup").Header("Cookie", cookies[0].Name+"="+cookies[0].Value).Send(router)
+	expected := "error: Please fix the errors;email=not-an-email;password=;error=must be a valid email address"
+	if response.Body.String() != expected {
+		t.Logf("%s\n", gyrtest.Diff(expected, response.Body.String()))
+		t.FailNow()
+	}
+	if cleared := response.Result().Cookies(); len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Logf("Expected the session cookie to be cleared. Received %v\n", cleared)
+		t.FailNow()
+	}
+
+	t.Run("tampered cookie", func(t *testing.T) {
+		response := gyrtest.Get("/signup").Header("Cookie", cookies[0].Name+"="+cookies[0].Value+"x").Send(router)
+		if response.Body.String() != "email=;password=;error=" {
+			t.Logf("Expected tampered cookie to be ignored. Received %s\n", response.Body.String())
+			t.FailNow()
+		}
+	})
+}
@@ -1,3 +1,3 @@
 module github.com/aigr20/gyr
 
-go 1.22.4
+go 1.24
@@ -0,0 +1,153 @@
+package gyr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// A GraphQL operation as sent by clients, in a POST body or the query parameters of a GET.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
+	// Set for GET requests, which must not run mutations.
+	ReadOnly bool `json:"-"`
+}
+
+// The response to a GraphQL operation.
+type GraphQLResult struct {
+	Data       any            `json:"data,omitempty"`
+	Errors     []GraphQLError `json:"errors,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Runs an operation against the schema of the application, typically by calling a GraphQL library.
+type GraphQLExecutor func(ctx context.Context, request GraphQLRequest) GraphQLResult
+
+type GraphQLSettings struct {
+	// Largest accepted request body in bytes.
+	MaxBodySize int64
+	// Accept queries in the query parameters of GET requests.
+	AllowGet bool
+}
+
+func DefaultGraphQLSettings() GraphQLSettings {
+	return GraphQLSettings{
+		MaxBodySize: 1 << 20,
+		AllowGet:    true,
+	}
+}
+
+func GraphQLMaxBodySize(size int64) func(*GraphQLSettings) {
+	return func(gs *GraphQLSettings) {
+		gs.MaxBodySize = size
+	}
+}
+
+func GraphQLDisableGet() func(*GraphQLSettings) {
+	return func(gs *GraphQLSettings) {
+		gs.AllowGet = false
+	}
+}
+
+type graphQLContextKey struct{}
+
+// The gyr context of the request a GraphQL operation is run for, for resolvers reading the principal, session
+// or route. Nil outside of [MountGraphQL].
+func ContextFrom(ctx context.Context) *Context {
+	gyrCtx, _ := ctx.Value(graphQLContextKey{}).(*Context)
+	return gyrCtx
+}
+
+// Register a GraphQL endpoint at path running operations with executor. The context passed to executor is the
+// request context, carrying the request id and logger, and [ContextFrom] returns the gyr context. Requests
+// that aren't valid GraphQL get 400 Bad Request, and larger bodies than MaxBodySize get 413, both with the
+// errors in a [GraphQLResult].
+//
+//	gyr.MountGraphQL(router, "/graphql", func(ctx context.Context, req gyr.GraphQLRequest) gyr.GraphQLResult {
+//		result := graphql.Do(graphql.Params{Schema: schema, RequestString: req.Query, VariableValues: req.Variables, Context: ctx})
+//		return gyr.GraphQLResult{Data: result.Data, Errors: toGyrErrors(result.Errors)}
+//	})
+func MountGraphQL(router RouteRegistrar, path string, executor GraphQLExecutor, settings ...SettingsFunc[GraphQLSettings]) *Route {
+	graphQLSettings := DefaultGraphQLSettings()
+	for _, setting := range settings {
+		setting(&graphQLSettings)
+	}
+
+	handler := func(ctx *Context) *Response {
+		request, status, err := readGraphQLRequest(ctx, graphQLSettings)
+		if err != nil {
+			return writeGraphQLResult(ctx.Response().Status(status), GraphQLResult{Errors: []GraphQLError{GraphQLErrorFrom(err)}})
+		}
+		execCtx := context.WithValue(ctx.Request.Context(), graphQLContextKey{}, ctx)
+		return writeGraphQLResult(ctx.Response(), executor(execCtx, request))
+	}
+	route := router.Path(path).Post(handler)
+	if graphQLSettings.AllowGet {
+		route.Get(handler)
+	}
+	return route
+}
+
+func readGraphQLRequest(ctx *Context, settings GraphQLSettings) (GraphQLRequest, int, error) {
+	var request GraphQLRequest
+	if ctx.Request.Method == http.MethodGet {
+		query := ctx.Request.URL.Query()
+		request = GraphQLRequest{Query: query.Get("query"), OperationName: query.Get("operationName"), ReadOnly: true}
+		for param, target := range map[string]*map[string]any{"variables": &request.Variables, "extensions": &request.Extensions} {
+			if encoded := query.Get(param); encoded != "" {
+				if err := json.Unmarshal([]byte(encoded), target); err != nil {
+					return request, http.StatusBadRequest, ErrorBadRequest.WithMessage("The " + param + " parameter is not valid JSON")
+				}
+			}
+		}
+	} else {
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, settings.MaxBodySize+1))
+		if err != nil {
+			return request, http.StatusBadRequest, ErrorBadRequest.Wrap(err)
+		}
+		if int64(len(body)) > settings.MaxBodySize {
+			return request, http.StatusRequestEntityTooLarge, ErrorBadRequest.WithMessage("The request body is too large")
+		}
+		if err := json.Unmarshal(body, &request); err != nil {
+			return request, http.StatusBadRequest, ErrorBadRequest.WithMessage("The request body is not a valid GraphQL request")
+		}
+	}
+	if request.Query == "" {
+		return request, http.StatusBadRequest, ErrorBadRequest.WithMessage("The request has no query")
+	}
+	return request, 0, nil
+}
+
+// Encode result without the processing of [Response.Json], such as sparse fieldsets.
+func writeGraphQLResult(response *Response, result GraphQLResult) *Response {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return response.Error(err)
+	}
+	return response.Header("Content-Type", "application/json").Raw(string(encoded))
+}
+
+// A GraphQL error for err, with the code of an [Error] in the extensions. Other errors are reported as
+// [ErrorInternal] so their details don't reach the client, and should be logged by the executor.
+func GraphQLErrorFrom(err error) GraphQLError {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		appErr = ErrorInternal
+	}
+	extensions := map[string]any{"code": appErr.Code}
+	for key, value := range appErr.Meta {
+		extensions[key] = value
+	}
+	return GraphQLError{Message: appErr.Message, Extensions: extensions}
+}
@@ -0,0 +1,35 @@
+package gyr_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestMountGraphQL(t *testing.T) {
+	router := defaultTestRouter()
+	gyr.MountGraphQL(router, "/graphql", func(ctx context.Context, request gyr.GraphQLRequest) gyr.GraphQLResult {
+		if gyr.ContextFrom(ctx) == nil {
+			return gyr.GraphQLResult{Errors: []gyr.GraphQLError{{Message: "missing context"}}}
+		}
+		if strings.HasPrefix(request.Query, "mutation") && request.ReadOnly {
+			return gyr.GraphQLResult{Errors: []gyr.GraphQLError{gyr.GraphQLErrorFrom(gyr.ErrorBadRequest)}}
+		}
+		return gyr.GraphQLResult{Data: map[string]any{"hello": request.Variables["name"]}}
+	}, gyr.GraphQLMaxBodySize(100))
+
+	gyrtest.Post("/graphql").JSON(gyr.GraphQLRequest{Query: "{ hello }", Variables: map[string]any{"name": "kalle"}}).Send(router).
+		AssertStatus(t, http.StatusOK).
+		AssertJSON(t, map[string]any{"data": map[string]any{"hello": "kalle"}})
+	gyrtest.Get("/graphql").Query("query", "{ hello }").Query("variables", `{"name":"kalle"}`).Send(router).
+		AssertJSON(t, map[string]any{"data": map[string]any{"hello": "kalle"}})
+	gyrtest.Get("/graphql").Query("query", "mutation { delete }").Send(router).
+		AssertJSON(t, map[string]any{"errors": []any{map[string]any{"message": "The request is invalid", "extensions": map[string]any{"code": "bad_request"}}}})
+	gyrtest.Post("/graphql").JSON(map[string]string{"query": ""}).Send(router).AssertStatus(t, http.StatusBadRequest)
+	gyrtest.Post("/graphql").JSON(gyr.GraphQLRequest{Query: strings.Repeat("a", 200)}).Send(router).
+		AssertStatus(t, http.StatusRequestEntityTooLarge)
+}
@@ -2,15 +2,13 @@
 package gyr
 
 import (
-	"os"
 	"regexp"
 )
 
 type SettingsFunc[SettingsStruct any] func(*SettingsStruct)
 
 func isGyrDebug() bool {
-	_, isSet := os.LookupEnv("GYR_DEBUG")
-	return isSet
+	return CurrentProfile().Debug
 }
 
 // Get the named matches from a regexp.
@@ -0,0 +1,175 @@
+package gyrtest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"text/tabwriter"
+	"time"
+)
+
+// Latency and allocations of the requests to one route in a [Bench] run.
+type RouteStats struct {
+	// Method and path of the request as given to [NewRequest], e.g. "GET /users/:id".
+	Route    string
+	Requests int
+	// Number of responses with each status code.
+	Statuses map[int]int
+	P50      time.Duration
+	P90      time.Duration
+	P99      time.Duration
+	Max      time.Duration
+	// Average number of heap allocations and allocated bytes per request.
+	Allocs float64
+	Bytes  float64
+}
+
+type BenchReport struct {
+	// Stats of each route in the order they were first sent.
+	Routes []RouteStats
+	Total  time.Duration
+}
+
+// Send n requests to handler, cycling through requests in order, and report latency percentiles and
+// allocations per route. Repeat a request to send it more often. Requests are sent one at a time so the
+// allocations of each request can be counted. Request bodies are read once and sent with every request.
+//
+//	report := gyrtest.Bench(router, 10000, gyrtest.Get("/users"), gyrtest.Get("/users/:id").PathVar("id", "1"))
+//	report.AssertP99(t, "GET /users", time.Millisecond)
+func Bench(handler http.Handler, n int, requests ...*Request) *BenchReport {
+	if len(requests) == 0 {
+		panic("gyrtest: Bench requires at least one request")
+	}
+	bodies := make([][]byte, len(requests))
+	for i, request := range requests {
+		if request.body != nil {
+			body, err := io.ReadAll(request.body)
+			if err != nil {
+				panic(err)
+			}
+			bodies[i] = body
+		}
+	}
+
+	type routeSamples struct {
+		durations []time.Duration
+		statuses  map[int]int
+		allocs    uint64
+		bytes     uint64
+	}
+	samples := make(map[string]*routeSamples)
+	order := make([]string, 0)
+	var before, after runtime.MemStats
+	start := time.Now()
+	for i := range n {
+		index := i % len(requests)
+		request := requests[index]
+		if bodies[index] != nil {
+			request.body = bytes.NewReader(bodies[index])
+		}
+		req := request.Build()
+		recorder := httptest.NewRecorder()
+
+		runtime.ReadMemStats(&before)
+		requestStart := time.Now()
+		handler.ServeHTTP(recorder, req)
+		duration := time.Since(requestStart)
+		runtime.ReadMemStats(&after)
+
+		route := request.method + " " + request.path
+		routeSample, exists := samples[route]
+		if !exists {
+			routeSample = &routeSamples{statuses: make(map[int]int)}
+			samples[route] = routeSample
+			order = append(order, route)
+		}
+		routeSample.durations = append(routeSample.durations, duration)
+		routeSample.statuses[recorder.Code]++
+		routeSample.allocs += after.Mallocs - before.Mallocs
+		routeSample.bytes += after.TotalAlloc - before.TotalAlloc
+	}
+
+	report := &BenchReport{Routes: make([]RouteStats, 0, len(order)), Total: time.Since(start)}
+	for _, route := range order {
+		routeSample := samples[route]
+		slices.Sort(routeSample.durations)
+		count := len(routeSample.durations)
+		report.Routes = append(report.Routes, RouteStats{
+			Route:    route,
+			Requests: count,
+			Statuses: routeSample.statuses,
+			P50:      percentile(routeSample.durations, 50),
+			P90:      percentile(routeSample.durations, 90),
+			P99:      percentile(routeSample.durations, 99),
+			Max:      routeSample.durations[count-1],
+			Allocs:   float64(routeSample.allocs) / float64(count),
+			Bytes:    float64(routeSample.bytes) / float64(count),
+		})
+	}
+	return report
+}
+
+// The pth percentile of sorted durations, using the nearest rank.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// The stats of route, given as method and path like "GET /users/:id".
+func (report *BenchReport) Route(route string) (RouteStats, bool) {
+	index := slices.IndexFunc(report.Routes, func(stats RouteStats) bool { return stats.Route == route })
+	if index == -1 {
+		return RouteStats{}, false
+	}
+	return report.Routes[index], true
+}
+
+// Fail the test if the 99th percentile latency of route is above limit.
+func (report *BenchReport) AssertP99(t testing.TB, route string, limit time.Duration) *BenchReport {
+	t.Helper()
+	stats := report.mustRoute(t, route)
+	if stats.P99 > limit {
+		t.Logf("Expected p99 of %s to be at most %s. Received %s\n", route, limit, stats.P99)
+		t.FailNow()
+	}
+	return report
+}
+
+// Fail the test if route allocates more than limit times per request on average.
+func (report *BenchReport) AssertAllocs(t testing.TB, route string, limit float64) *BenchReport {
+	t.Helper()
+	stats := report.mustRoute(t, route)
+	if stats.Allocs > limit {
+		t.Logf("Expected %s to allocate at most %.1f times per request. Received %.1f\n", route, limit, stats.Allocs)
+		t.FailNow()
+	}
+	return report
+}
+
+func (report *BenchReport) mustRoute(t testing.TB, route string) RouteStats {
+	t.Helper()
+	stats, exists := report.Route(route)
+	if !exists {
+		t.Logf("No requests were sent to %s\n", route)
+		t.FailNow()
+	}
+	return stats
+}
+
+// A table of the stats of every route.
+func (report *BenchReport) String() string {
+	var builder strings.Builder
+	writer := tabwriter.NewWriter(&builder, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ROUTE\tREQUESTS\tP50\tP90\tP99\tMAX\tALLOCS/OP\tB/OP")
+	for _, stats := range report.Routes {
+		fmt.Fprintf(writer, "%s\t%d\t%s\t%s\t%s\t%s\t%.1f\t%.0f\n", stats.Route, stats.Requests, stats.P50, stats.P90, stats.P99, stats.Max, stats.Allocs, stats.Bytes)
+	}
+	writer.Flush()
+	return builder.String()
+}
@@ -0,0 +1,38 @@
+package gyrtest_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestBench(t *testing.T) {
+	router := testRouter()
+	report := gyrtest.Bench(router, 30,
+		gyrtest.Put("/items/:id").PathVar("id", "1").JSON(item{Name: "lamp"}),
+		gyrtest.Put("/items/:id").PathVar("id", "2").JSON(item{Name: "chair"}),
+		gyrtest.Get("/missing"),
+	)
+
+	stats, exists := report.Route("PUT /items/:id")
+	if !exists || stats.Requests != 20 || stats.Statuses[http.StatusOK] != 20 {
+		t.Logf("Expected 20 successful requests to PUT /items/:id. Received %+v\n", stats)
+		t.FailNow()
+	}
+	if stats.P50 > stats.P99 || stats.P99 > stats.Max || stats.Allocs == 0 {
+		t.Logf("Unexpected stats %+v\n", stats)
+		t.FailNow()
+	}
+	if missing, _ := report.Route("GET /missing"); missing.Statuses[http.StatusNotFound] != 10 {
+		t.Logf("Expected 10 not found responses. Received %+v\n", missing)
+		t.FailNow()
+	}
+	report.AssertP99(t, "GET /missing", time.Second).AssertAllocs(t, "GET /missing", 10000)
+	if !strings.HasPrefix(report.String(), "ROUTE") {
+		t.Logf("Unexpected report\n%s\n", report)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,99 @@
+package gyrtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+var fuzzPaths = []string{
+	"/",
+	"//",
+	"/%2e%2e/%2e%2e/etc/passwd",
+	"/static/../../etc/passwd",
+	"/static/%2e%2e%2fsecret",
+	"/..%5c..%5cwindows",
+	"/%00",
+	"/a%00b",
+	"/?q=%zz",
+	"/" + strings.Repeat("a", 64*1024),
+	"/" + strings.Repeat("x/", 1024),
+	"/%ff%fe",
+}
+
+var fuzzBodies = []struct {
+	contentType string
+	body        string
+}{
+	{"application/json", `{"a":`},
+	{"application/json", `[`},
+	{"application/json", "\xff\xfe{}"},
+	{"application/json", `{"a":1}{"b":2}`},
+	{"multipart/form-data", "--x\r\n"},
+	{"application/x-www-form-urlencoded", "a=%zz&&="},
+	{"text/plain; charset=", ""},
+	{"garbage/;;;", "\x00\x01"},
+}
+
+// Fuzz handler, usually a [gyr.Router], with malformed paths, headers and bodies, failing if it panics or
+// responds with an invalid or 5xx status. paths are added to the seed corpus, such as the routes of the router.
+//
+//	func FuzzRouter(f *testing.F) {
+//		gyrtest.FuzzHandler(f, newRouter(), "/users/1", "/static/app.js")
+//	}
+func FuzzHandler(f *testing.F, handler http.Handler, paths ...string) {
+	for _, path := range append(fuzzPaths, paths...) {
+		for _, method := range []string{http.MethodGet, http.MethodPost} {
+			for _, seed := range fuzzBodies {
+				f.Add(method, path, seed.contentType, "", seed.body)
+			}
+		}
+		f.Add(http.MethodOptions, path, "", "Origin: null", "")
+	}
+
+	f.Fuzz(func(t *testing.T, method string, target string, contentType string, header string, body string) {
+		req := fuzzRequest(method, target, contentType, header, body)
+		if req == nil {
+			t.Skip("not a valid HTTP request")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code < 100 || w.Code > 599 {
+			t.Fatalf("%s %q: invalid status %d", method, target, w.Code)
+		}
+		if w.Code >= 500 && w.Code != http.StatusNotImplemented && w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s %q: server error %d: %s", method, target, w.Code, w.Body.String())
+		}
+	})
+}
+
+// Build the request a server would hand to a handler, or nil if net/http would reject it before that.
+func fuzzRequest(method string, target string, contentType string, header string, body string) *http.Request {
+	if method == "" || strings.ContainsAny(method, " \t\r\n\x00/") || !strings.HasPrefix(target, "/") {
+		return nil
+	}
+	parsed, err := url.ParseRequestURI(target)
+	if err != nil {
+		return nil
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(body))
+	req.Method = method
+	req.URL = parsed
+	req.RequestURI = target
+	if contentType != "" {
+		if strings.ContainsAny(contentType, "\r\n\x00") {
+			return nil
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	if name, value, found := strings.Cut(header, ":"); found {
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if name == "" || strings.ContainsAny(name, " \t\r\n\x00:") || strings.ContainsAny(value, "\r\n\x00") {
+			return nil
+		}
+		req.Header.Set(name, value)
+	}
+	return req
+}
@@ -0,0 +1,13 @@
+package gyrtest_test
+
+import (
+	"testing"
+
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func FuzzRouter(f *testing.F) {
+	router := testRouter()
+	router.StaticDir("../test_files/staticdir")
+	gyrtest.FuzzHandler(f, router, "/items/1", "/test_files/staticdir/text.html", "/test_files/staticdir/%2e%2e/%2e%2e/go.mod")
+}
@@ -0,0 +1,232 @@
+// Package gyrtest has helpers for testing handlers registered on a [gyr.Router] without starting a server.
+package gyrtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Send req to handler, usually a [gyr.Router], and record the response.
+func Send(handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// Marshal object into a JSON body. Panics if object can't be marshaled since that is a mistake in the test.
+func Payload(object any) *bytes.Reader {
+	marshaledPayload, err := json.Marshal(object)
+	if err != nil {
+		panic(err)
+	}
+	return bytes.NewReader(marshaledPayload)
+}
+
+// Fluent builder for requests to send to a router.
+type Request struct {
+	method   string
+	path     string
+	body     io.Reader
+	header   http.Header
+	query    url.Values
+	pathVars map[string]string
+}
+
+// Start building a request. path may contain :name variables that are filled in with [Request.PathVar].
+func NewRequest(method string, path string) *Request {
+	return &Request{
+		method:   method,
+		path:     path,
+		header:   make(http.Header),
+		query:    make(url.Values),
+		pathVars: make(map[string]string),
+	}
+}
+
+func Get(path string) *Request {
+	return NewRequest(http.MethodGet, path)
+}
+
+func Post(path string) *Request {
+	return NewRequest(http.MethodPost, path)
+}
+
+func Put(path string) *Request {
+	return NewRequest(http.MethodPut, path)
+}
+
+func Patch(path string) *Request {
+	return NewRequest(http.MethodPatch, path)
+}
+
+func Delete(path string) *Request {
+	return NewRequest(http.MethodDelete, path)
+}
+
+// Use object marshaled as JSON as body and set the Content-Type header accordingly.
+func (r *Request) JSON(object any) *Request {
+	r.body = Payload(object)
+	r.header.Set("Content-Type", "application/json")
+	return r
+}
+
+func (r *Request) Body(body io.Reader) *Request {
+	r.body = body
+	return r
+}
+
+func (r *Request) Header(name string, value string) *Request {
+	r.header.Add(name, value)
+	return r
+}
+
+func (r *Request) Query(name string, value string) *Request {
+	r.query.Add(name, value)
+	return r
+}
+
+// Replace the :name segment of the path with value.
+func (r *Request) PathVar(name string, value string) *Request {
+	r.pathVars[name] = value
+	return r
+}
+
+// Create the [http.Request]. Panics if the method or path are invalid.
+func (r *Request) Build() *http.Request {
+	parts := strings.Split(r.path, "/")
+	for i, part := range parts {
+		if value, exists := r.pathVars[strings.TrimPrefix(part, ":")]; exists && strings.HasPrefix(part, ":") {
+			parts[i] = url.PathEscape(value)
+		}
+	}
+	target := strings.Join(parts, "/")
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+
+	request := httptest.NewRequest(r.method, target, r.body)
+	for name, values := range r.header {
+		request.Header[name] = values
+	}
+	return request
+}
+
+// Build the request and send it to handler.
+func (r *Request) Send(handler http.Handler) *Response {
+	return &Response{ResponseRecorder: Send(handler, r.Build())}
+}
+
+// Recorded response with assertions that fail the test with a description of the mismatch.
+type Response struct {
+	*httptest.ResponseRecorder
+}
+
+func (r *Response) AssertStatus(t testing.TB, status int) *Response {
+	t.Helper()
+	if r.Code != status {
+		t.Logf("Expected status %d. Received %d with body %s\n", status, r.Code, r.Body.String())
+		t.FailNow()
+	}
+	return r
+}
+
+func (r *Response) AssertHeader(t testing.TB, name string, value string) *Response {
+	t.Helper()
+	if received := r.Header().Get(name); received != value {
+		t.Logf("Expected header %s to be %q. Received %q\n", name, value, received)
+		t.FailNow()
+	}
+	return r
+}
+
+func (r *Response) AssertBody(t testing.TB, body string) *Response {
+	t.Helper()
+	if received := r.Body.String(); received != body {
+		t.Logf("Body mismatch (-expected +received):\n%s", Diff(body, received))
+		t.FailNow()
+	}
+	return r
+}
+
+// Compare the JSON body to expected marshaled as JSON. Formatting and object key order are ignored.
+func (r *Response) AssertJSON(t testing.TB, expected any) *Response {
+	t.Helper()
+	var receivedValue any
+	if err := json.Unmarshal(r.Body.Bytes(), &receivedValue); err != nil {
+		t.Logf("Body is not valid JSON: %v\n%s\n", err, r.Body.String())
+		t.FailNow()
+	}
+	var expectedValue any
+	expectedContent, err := json.Marshal(expected)
+	if err == nil {
+		err = json.Unmarshal(expectedContent, &expectedValue)
+	}
+	if err != nil {
+		t.Logf("Expected value can not be compared as JSON: %v\n", err)
+		t.FailNow()
+	}
+
+	expectedJSON, _ := json.MarshalIndent(expectedValue, "", "  ")
+	receivedJSON, _ := json.MarshalIndent(receivedValue, "", "  ")
+	if !bytes.Equal(expectedJSON, receivedJSON) {
+		t.Logf("JSON mismatch (-expected +received):\n%s", Diff(string(expectedJSON), string(receivedJSON)))
+		t.FailNow()
+	}
+	return r
+}
+
+// Decode the JSON body into T, failing the test if that isn't possible.
+func DecodeJSON[T any](t testing.TB, r *Response) T {
+	t.Helper()
+	var target T
+	if err := json.Unmarshal(r.Body.Bytes(), &target); err != nil {
+		t.Logf("Could not decode body into %T: %v\n%s\n", target, err, r.Body.String())
+		t.FailNow()
+	}
+	return target
+}
+
+// Line by line diff of expected and received, prefixing removed lines with - and added lines with +.
+func Diff(expected string, received string) string {
+	a := strings.Split(expected, "\n")
+	b := strings.Split(received, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	sb := strings.Builder{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			sb.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return sb.String()
+}
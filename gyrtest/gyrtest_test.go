@@ -0,0 +1,57 @@
+package gyrtest_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+type item struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func testRouter() *gyr.Router {
+	router := gyr.DefaultRouter()
+	router.Path("/items/:id").Put(func(ctx *gyr.Context) *gyr.Response {
+		body, err := gyr.ReadBody[item](ctx)
+		if err != nil {
+			return ctx.Response().Status(http.StatusBadRequest)
+		}
+		body.ID = ctx.IntVariable("id")
+		if ctx.Request.URL.Query().Get("upper") == "true" {
+			body.Name = "UPPER"
+		}
+		return ctx.Response().Header("X-Item", ctx.Request.Header.Get("X-Trace")).Json(body)
+	})
+	return router
+}
+
+func TestRequestBuilder(t *testing.T) {
+	response := gyrtest.Put("/items/:id").
+		PathVar("id", "7").
+		Query("upper", "true").
+		Header("X-Trace", "abc").
+		JSON(item{Name: "lamp"}).
+		Send(testRouter())
+
+	response.AssertStatus(t, http.StatusOK).
+		AssertHeader(t, "X-Item", "abc").
+		AssertJSON(t, map[string]any{"name": "UPPER", "id": 7})
+
+	if received := gyrtest.DecodeJSON[item](t, response); received != (item{ID: 7, Name: "UPPER"}) {
+		t.Logf("Received %+v\n", received)
+		t.FailNow()
+	}
+}
+
+func TestDiff(t *testing.T) {
+	diff := gyrtest.Diff("a\nb\nc", "a\nx\nc")
+	expected := "  a\n- b\n+ x\n  c\n"
+	if diff != expected {
+		t.Logf("Expected\n%s\nReceived\n%s\n", expected, diff)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,70 @@
+package gyr
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// How long [HealthHandler] waits for each check.
+var HealthCheckTimeout = 5 * time.Second
+
+var (
+	healthChecksMx sync.RWMutex
+	healthChecks   = make(map[string]func(context.Context) error)
+)
+
+// Result of the checks run by [HealthHandler]. Status is "ok" or "unavailable" and Checks has "ok" or the
+// error of each check.
+type HealthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Add a check run by [HealthHandler], replacing any check with the same name.
+func RegisterHealthCheck(name string, check func(context.Context) error) {
+	healthChecksMx.Lock()
+	defer healthChecksMx.Unlock()
+	healthChecks[name] = check
+}
+
+// Remove the check registered with name.
+func UnregisterHealthCheck(name string) {
+	healthChecksMx.Lock()
+	defer healthChecksMx.Unlock()
+	delete(healthChecks, name)
+}
+
+// Run every registered check and report the result as a [HealthReport], with 503 Service Unavailable if a
+// check fails.
+//
+//	router.Path("/health").Get(gyr.HealthHandler())
+func HealthHandler() Handler {
+	return func(ctx *Context) *Response {
+		healthChecksMx.RLock()
+		checks := maps.Clone(healthChecks)
+		healthChecksMx.RUnlock()
+
+		report := HealthReport{Status: "ok", Checks: make(map[string]string)}
+		for _, name := range slices.Sorted(maps.Keys(checks)) {
+			checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), HealthCheckTimeout)
+			err := checks[name](checkCtx)
+			cancel()
+			if err != nil {
+				report.Status = "unavailable"
+				report.Checks[name] = err.Error()
+				continue
+			}
+			report.Checks[name] = "ok"
+		}
+
+		response := ctx.Response()
+		if report.Status != "ok" {
+			response.Status(http.StatusServiceUnavailable)
+		}
+		return response.Json(report)
+	}
+}
@@ -0,0 +1,299 @@
+package gyr
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type TranslatorSettings struct {
+	// Directory containing one catalog per locale, named after the locale, e.g. en.json and sv-SE.json.
+	Directory     string
+	DefaultLocale string
+	// Query parameter and cookie checked for a locale before the Accept-Language header.
+	QueryParam string
+	CookieName string
+	// Catalog decoders by file extension. .json is supported by default.
+	Decoders map[string]ConfigDecoder
+}
+
+func DefaultTranslatorSettings() TranslatorSettings {
+	return TranslatorSettings{
+		Directory:     "locales",
+		DefaultLocale: "en",
+		QueryParam:    "lang",
+		CookieName:    "lang",
+		Decoders: map[string]ConfigDecoder{
+			".json": func(content []byte) (map[string]any, error) {
+				values := make(map[string]any)
+				err := json.Unmarshal(content, &values)
+				return values, err
+			},
+		},
+	}
+}
+
+func TranslationDirectory(dir string) func(*TranslatorSettings) {
+	return func(ts *TranslatorSettings) {
+		ts.Directory = dir
+	}
+}
+
+func TranslationDefaultLocale(locale string) func(*TranslatorSettings) {
+	return func(ts *TranslatorSettings) {
+		ts.DefaultLocale = locale
+	}
+}
+
+func TranslationQueryParam(name string) func(*TranslatorSettings) {
+	return func(ts *TranslatorSettings) {
+		ts.QueryParam = name
+	}
+}
+
+func TranslationCookie(name string) func(*TranslatorSettings) {
+	return func(ts *TranslatorSettings) {
+		ts.CookieName = name
+	}
+}
+
+// Register a decoder for catalogs with the file extension, for example ".toml".
+func TranslationFormat(extension string, decoder ConfigDecoder) func(*TranslatorSettings) {
+	return func(ts *TranslatorSettings) {
+		ts.Decoders[extension] = decoder
+	}
+}
+
+// Message catalogs for a set of locales. A message is either a string or an object of plural forms keyed by
+// zero, one and other. Messages can contain {name} placeholders that are replaced by the arguments.
+type Translator struct {
+	catalogs map[string]map[string]any
+	Settings TranslatorSettings
+}
+
+// Load every catalog in the translation directory.
+func NewTranslator(settings ...SettingsFunc[TranslatorSettings]) (*Translator, error) {
+	translatorSettings := DefaultTranslatorSettings()
+	for _, setting := range settings {
+		setting(&translatorSettings)
+	}
+
+	entries, err := os.ReadDir(translatorSettings.Directory)
+	if err != nil {
+		return nil, err
+	}
+	translator := &Translator{catalogs: make(map[string]map[string]any), Settings: translatorSettings}
+	for _, entry := range entries {
+		extension := filepath.Ext(entry.Name())
+		decoder, exists := translatorSettings.Decoders[extension]
+		if entry.IsDir() || !exists {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(translatorSettings.Directory, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		values, err := decoder(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		catalog := make(map[string]any)
+		flattenMessages("", values, catalog)
+		translator.catalogs[strings.TrimSuffix(entry.Name(), extension)] = catalog
+	}
+	if _, exists := translator.catalogs[translatorSettings.DefaultLocale]; !exists {
+		return nil, fmt.Errorf("no catalog for default locale %q in %s", translatorSettings.DefaultLocale, translatorSettings.Directory)
+	}
+	return translator, nil
+}
+
+// Nested objects become dotted keys, except objects of plural forms which are kept as messages.
+func flattenMessages(prefix string, values map[string]any, catalog map[string]any) {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, isMap := value.(map[string]any); isMap && !isPluralMessage(nested) {
+			flattenMessages(key, nested, catalog)
+			continue
+		}
+		catalog[key] = value
+	}
+}
+
+func isPluralMessage(values map[string]any) bool {
+	_, hasOther := values["other"]
+	return hasOther
+}
+
+// The locales tried for locale, from most to least specific and ending with the default locale.
+// sv-SE gives sv-SE, sv and then the default.
+func (translator *Translator) fallbackChain(locale string) []string {
+	chain := make([]string, 0, 3)
+	for locale != "" {
+		chain = append(chain, locale)
+		index := strings.LastIndexAny(locale, "-_")
+		if index == -1 {
+			break
+		}
+		locale = locale[:index]
+	}
+	if !slices.Contains(chain, translator.Settings.DefaultLocale) {
+		chain = append(chain, translator.Settings.DefaultLocale)
+	}
+	return chain
+}
+
+// Whether there is a catalog for locale or a less specific version of it.
+func (translator *Translator) Supports(locale string) bool {
+	for _, candidate := range translator.fallbackChain(locale) {
+		if _, exists := translator.catalogs[candidate]; exists && candidate != translator.Settings.DefaultLocale {
+			return true
+		}
+	}
+	return locale == translator.Settings.DefaultLocale
+}
+
+// Get the message for key in locale, falling back to less specific locales and then the default locale.
+// When args has a count, the plural form is chosen from it. The key is returned if no catalog has the message.
+func (translator *Translator) Translate(locale string, key string, args map[string]any) string {
+	for _, candidate := range translator.fallbackChain(locale) {
+		message, exists := translator.catalogs[candidate][key]
+		if !exists {
+			continue
+		}
+		if forms, isPlural := message.(map[string]any); isPlural {
+			message = forms[pluralForm(forms, args["count"])]
+		}
+		return formatMessage(fmt.Sprint(message), args)
+	}
+	return key
+}
+
+func pluralForm(forms map[string]any, count any) string {
+	n, err := strconv.ParseFloat(fmt.Sprint(count), 64)
+	if err != nil {
+		return "other"
+	}
+	if _, exists := forms["zero"]; exists && n == 0 {
+		return "zero"
+	}
+	if _, exists := forms["one"]; exists && n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func formatMessage(message string, args map[string]any) string {
+	if len(args) == 0 || !strings.Contains(message, "{") {
+		return message
+	}
+	replacements := make([]string, 0, len(args)*2)
+	for name, value := range args {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(message)
+}
+
+// Functions for html/template, currently t which works like [Translator.Translate] for locale, e.g.
+// {{ t "cart.items" .Args }} where .Args is a map[string]any.
+func (translator *Translator) FuncMap(locale string) template.FuncMap {
+	return template.FuncMap{
+		"t": func(key string, args ...map[string]any) string {
+			var messageArgs map[string]any
+			if len(args) > 0 {
+				messageArgs = args[0]
+			}
+			return translator.Translate(locale, key, messageArgs)
+		},
+	}
+}
+
+// Middleware resolving the locale of the request, from the query parameter, the cookie and then the
+// Accept-Language header, and making it available to [T] and [Context.Locale].
+func (translator *Translator) Middleware() Handler {
+	return func(ctx *Context) *Response {
+		ctx.translator = translator
+		ctx.locale = translator.resolveLocale(ctx)
+		return nil
+	}
+}
+
+func (translator *Translator) resolveLocale(ctx *Context) string {
+	if locale := ctx.Request.URL.Query().Get(translator.Settings.QueryParam); locale != "" && translator.Supports(locale) {
+		return locale
+	}
+	if cookie, err := ctx.Request.Cookie(translator.Settings.CookieName); err == nil && translator.Supports(cookie.Value) {
+		return cookie.Value
+	}
+	for _, locale := range parseAcceptLanguage(ctx.Request.Header.Get("Accept-Language")) {
+		if translator.Supports(locale) {
+			return locale
+		}
+	}
+	return translator.Settings.DefaultLocale
+}
+
+// The languages in an Accept-Language header ordered by their quality, highest first. Languages with a quality
+// of 0 are not acceptable and left out.
+func parseAcceptLanguage(header string) []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+	languages := make([]language, 0)
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if value, hasQuality := strings.CutPrefix(strings.TrimSpace(params), "q="); hasQuality {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		languages = append(languages, language{tag: tag, quality: quality})
+	}
+	slices.SortStableFunc(languages, func(a, b language) int {
+		switch {
+		case a.quality > b.quality:
+			return -1
+		case a.quality < b.quality:
+			return 1
+		}
+		return 0
+	})
+
+	tags := make([]string, len(languages))
+	for i, language := range languages {
+		tags[i] = language.tag
+	}
+	return tags
+}
+
+// The locale resolved by the [Translator] middleware, or an empty string if it hasn't run.
+func (ctx *Context) Locale() string {
+	return ctx.locale
+}
+
+// Translate key into the locale of the request. args may be nil. Requires the [Translator] middleware.
+func T(ctx *Context, key string, args map[string]any) string {
+	if ctx.translator == nil {
+		return key
+	}
+	return ctx.translator.Translate(ctx.locale, key, args)
+}
+
+// The [Translator] whose middleware ran for the request, or nil.
+func (ctx *Context) Translator() *Translator {
+	return ctx.translator
+}
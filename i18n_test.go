@@ -0,0 +1,93 @@
+package gyr_test
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func testTranslator(t *testing.T) *gyr.Translator {
+	translator, err := gyr.NewTranslator(gyr.TranslationDirectory("test_files/locales"))
+	if err != nil {
+		t.Logf("Could not load catalogs: %v\n", err)
+		t.FailNow()
+	}
+	return translator
+}
+
+func TestTranslate(t *testing.T) {
+	translator := testTranslator(t)
+	tests := []struct {
+		locale   string
+		key      string
+		args     map[string]any
+		expected string
+	}{
+		{"en", "welcome", map[string]any{"name": "Ada"}, "Welcome, Ada!"},
+		{"sv", "welcome", map[string]any{"name": "Ada"}, "Välkommen, Ada!"},
+		{"sv-FI", "welcome", map[string]any{"name": "Ada"}, "Välkommen till Finland, Ada!"},
+		{"sv-FI", "cart.items", map[string]any{"count": 1}, "Du har en vara"},
+		{"sv", "cart.items", map[string]any{"count": 0}, "Du har 0 varor"},
+		{"en", "cart.items", map[string]any{"count": 0}, "Your cart is empty"},
+		{"en", "cart.items", map[string]any{"count": 3}, "You have 3 items"},
+		{"sv", "goodbye", nil, "Goodbye"},
+		{"de", "missing", nil, "missing"},
+	}
+	for _, test := range tests {
+		t.Run(test.locale+" "+test.key, func(t *testing.T) {
+			if received := translator.Translate(test.locale, test.key, test.args); received != test.expected {
+				t.Logf("Expected %q. Received %q\n", test.expected, received)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestTranslateLocaleResolution(t *testing.T) {
+	translator := testTranslator(t)
+	router := gyr.DefaultRouter()
+	router.Middleware(translator.Middleware())
+	router.Path("/").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text(ctx.Locale() + ": " + gyr.T(ctx, "welcome", map[string]any{"name": "Ada"}))
+	})
+
+	tests := []struct {
+		name     string
+		target   string
+		cookie   string
+		header   string
+		expected string
+	}{
+		{"Default", "/", "", "", "en: Welcome, Ada!"},
+		{"Accept-Language", "/", "", "de;q=0.9, sv-FI;q=0.8, en;q=0.5", "sv-FI: Välkommen till Finland, Ada!"},
+		{"Not acceptable", "/", "", "sv-FI;q=0, sv;q=0.0", "en: Welcome, Ada!"},
+		{"Cookie", "/", "sv", "en", "sv: Välkommen, Ada!"},
+		{"Query", "/?lang=en", "sv", "sv", "en: Welcome, Ada!"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodGet, test.target, nil)
+			if test.cookie != "" {
+				request.AddCookie(&http.Cookie{Name: "lang", Value: test.cookie})
+			}
+			request.Header.Set("Accept-Language", test.header)
+			if received := sendRequest(router, request).Body.String(); received != test.expected {
+				t.Logf("Expected %q. Received %q\n", test.expected, received)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestTranslatorFuncMap(t *testing.T) {
+	translator := testTranslator(t)
+	tmpl := template.Must(template.New("").Funcs(translator.FuncMap("sv")).Parse(`{{ t "cart.items" . }}`))
+	sb := strings.Builder{}
+	if err := tmpl.Execute(&sb, map[string]any{"count": 2}); err != nil || sb.String() != "Du har 2 varor" {
+		t.Logf("Received %q (%v)\n", sb.String(), err)
+		t.FailNow()
+	}
+}
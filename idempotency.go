@@ -0,0 +1,217 @@
+package gyr
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Returned by [IdempotencyStore.Claim] when another request with the same key is being handled.
+var ErrIdempotencyInProgress = errors.New("request with idempotency key in progress")
+
+// A response stored for replaying to retries.
+type StoredResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// Hash of the request the response was for, used to detect keys reused for different requests.
+	Fingerprint string
+}
+
+// Storage of responses for [Idempotency]. Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Claim key for a new request. Returns the stored response if the key has one, or
+	// [ErrIdempotencyInProgress] if the key is claimed by a request that hasn't finished.
+	Claim(key string, ttl time.Duration) (*StoredResponse, error)
+	// Store the response for a claimed key, to be replayed until ttl has passed.
+	Save(key string, response StoredResponse, ttl time.Duration) error
+	// Release a claimed key without storing a response, allowing it to be retried.
+	Release(key string) error
+}
+
+type IdempotencySettings struct {
+	Store  IdempotencyStore
+	TTL    time.Duration
+	Header string
+	// Methods the header is honored for.
+	Methods []string
+	// Identifies the caller, so that keys sent by different callers never share a response. Defaults to the
+	// subject of the [Principal], or a hash of the Authorization and Cookie headers without one.
+	Scope func(*Context) string
+}
+
+func DefaultIdempotencySettings() IdempotencySettings {
+	return IdempotencySettings{
+		TTL:     24 * time.Hour,
+		Header:  "Idempotency-Key",
+		Methods: []string{http.MethodPost, http.MethodPatch},
+		Scope:   callerScope,
+	}
+}
+
+func callerScope(ctx *Context) string {
+	if principal := ctx.Principal(); principal != nil {
+		return "principal:" + principal.Subject
+	}
+	hash := sha256.New()
+	io.WriteString(hash, strings.Join(ctx.Request.Header.Values("Authorization"), ",")+"\x00")
+	io.WriteString(hash, strings.Join(ctx.Request.Header.Values("Cookie"), ";"))
+	return "credentials:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+func IdempotencyScope(scope func(*Context) string) func(*IdempotencySettings) {
+	return func(is *IdempotencySettings) {
+		is.Scope = scope
+	}
+}
+
+func IdempotencyStorage(store IdempotencyStore) func(*IdempotencySettings) {
+	return func(is *IdempotencySettings) {
+		is.Store = store
+	}
+}
+
+func IdempotencyTTL(ttl time.Duration) func(*IdempotencySettings) {
+	return func(is *IdempotencySettings) {
+		is.TTL = ttl
+	}
+}
+
+// Middleware honoring the Idempotency-Key header. The response to the first request with a key is stored and
+// replayed to later requests with the same key until the TTL has passed. Requests arriving while the first is
+// being handled get 409 Conflict, and reusing a key for a different request gets 422 Unprocessable Entity.
+// Server errors are not stored so the request can be retried. Uses an in-memory store by default. Keys are scoped
+// to the caller, see [IdempotencySettings.Scope], so register it after the authentication middleware.
+//
+//	router.Middleware(gyr.JWTAuth(options), gyr.Idempotency())
+func Idempotency(settings ...SettingsFunc[IdempotencySettings]) Handler {
+	idempotencySettings := DefaultIdempotencySettings()
+	for _, setting := range settings {
+		setting(&idempotencySettings)
+	}
+	if idempotencySettings.Store == nil {
+		idempotencySettings.Store = NewMemoryIdempotencyStore()
+	}
+	store := idempotencySettings.Store
+
+	return func(ctx *Context) *Response {
+		key := ctx.Request.Header.Get(idempotencySettings.Header)
+		if key == "" || !slices.Contains(idempotencySettings.Methods, ctx.Request.Method) {
+			return nil
+		}
+		fingerprint, err := requestFingerprint(ctx.Request)
+		if err != nil {
+			return ctx.Response().Status(http.StatusBadRequest).Text("400 - Bad Request")
+		}
+
+		key = idempotencySettings.Scope(ctx) + " " + ctx.Request.Method + " " + ctx.Request.URL.Path + " " + key
+		stored, err := store.Claim(key, idempotencySettings.TTL)
+		if errors.Is(err, ErrIdempotencyInProgress) {
+			return ctx.Response().Status(http.StatusConflict).Text("409 - Conflict")
+		} else if err != nil {
+			ctx.Logger().Error("Failed claiming idempotency key", "err", err)
+			return ctx.Response().InternalError().Text("Internal Server Error")
+		}
+		if stored != nil {
+			if stored.Fingerprint != fingerprint {
+				return ctx.Response().Status(http.StatusUnprocessableEntity).Text("422 - Idempotency-Key reused for a different request")
+			}
+			for name, values := range stored.Header {
+				ctx.writer.Header()[name] = slices.Clone(values)
+			}
+			response := ctx.Response().Status(stored.Status).Header("Idempotent-Replayed", "true")
+			response.toWrite = stored.Body
+			return response
+		}
+
+		ctx.onDone(func() {
+			response := ctx.response
+			if response == nil || response.status >= http.StatusInternalServerError {
+				store.Release(key)
+				return
+			}
+			err := store.Save(key, StoredResponse{
+				Status:      response.status,
+				Header:      response.w.Header().Clone(),
+				Body:        response.toWrite,
+				Fingerprint: fingerprint,
+			}, idempotencySettings.TTL)
+			if err != nil {
+				ctx.Logger().Error("Failed storing idempotent response", "err", err)
+			}
+		})
+		return nil
+	}
+}
+
+// Hash of the method, path and body of req. The body is put back so it can be read again.
+func requestFingerprint(req *http.Request) (string, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	hash := sha256.New()
+	io.WriteString(hash, req.Method+" "+req.URL.Path+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+type idempotencyEntry struct {
+	response  *StoredResponse
+	expiresAt time.Time
+}
+
+// [IdempotencyStore] keeping responses in memory. Expired keys are swept at most once a minute, when a key
+// is claimed.
+type MemoryIdempotencyStore struct {
+	mx        sync.Mutex
+	entries   map[string]idempotencyEntry
+	lastSweep time.Time
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: make(map[string]idempotencyEntry)}
+}
+
+func (store *MemoryIdempotencyStore) Claim(key string, ttl time.Duration) (*StoredResponse, error) {
+	store.mx.Lock()
+	defer store.mx.Unlock()
+	if now := CurrentClock().Now(); now.Sub(store.lastSweep) > time.Minute {
+		for candidate, entry := range store.entries {
+			if now.After(entry.expiresAt) {
+				delete(store.entries, candidate)
+			}
+		}
+		store.lastSweep = now
+	}
+	if entry, exists := store.entries[key]; exists && CurrentClock().Now().Before(entry.expiresAt) {
+		if entry.response == nil {
+			return nil, ErrIdempotencyInProgress
+		}
+		return entry.response, nil
+	}
+	store.entries[key] = idempotencyEntry{expiresAt: CurrentClock().Now().Add(ttl)}
+	return nil, nil
+}
+
+func (store *MemoryIdempotencyStore) Save(key string, response StoredResponse, ttl time.Duration) error {
+	store.mx.Lock()
+	defer store.mx.Unlock()
+	store.entries[key] = idempotencyEntry{response: &response, expiresAt: CurrentClock().Now().Add(ttl)}
+	return nil
+}
+
+func (store *MemoryIdempotencyStore) Release(key string) error {
+	store.mx.Lock()
+	defer store.mx.Unlock()
+	delete(store.entries, key)
+	return nil
+}
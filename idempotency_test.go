@@ -0,0 +1,83 @@
+package gyr_test
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestIdempotency(t *testing.T) {
+	var created atomic.Int32
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	router := defaultTestRouter()
+	router.Middleware(gyr.Idempotency())
+	router.Path("/orders").Post(func(ctx *gyr.Context) *gyr.Response {
+		if ctx.Request.Header.Get("X-Block") != "" {
+			close(entered)
+			<-release
+		}
+		return ctx.Response().Status(http.StatusCreated).Json(map[string]int32{"id": created.Add(1)})
+	})
+	router.Path("/fail").Post(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().InternalError().Text("failed")
+	})
+
+	order := func(key string) *gyrtest.Response {
+		return gyrtest.Post("/orders").Header("Idempotency-Key", key).JSON(map[string]string{"item": "lamp"}).Send(router)
+	}
+
+	order("a").AssertStatus(t, http.StatusCreated).AssertJSON(t, map[string]int{"id": 1})
+	order("a").AssertStatus(t, http.StatusCreated).AssertJSON(t, map[string]int{"id": 1}).AssertHeader(t, "Idempotent-Replayed", "true")
+	order("b").AssertJSON(t, map[string]int{"id": 2})
+	gyrtest.Post("/orders").JSON(map[string]string{"item": "lamp"}).Send(router).AssertJSON(t, map[string]int{"id": 3})
+
+	t.Run("different request", func(t *testing.T) {
+		gyrtest.Post("/orders").Header("Idempotency-Key", "a").JSON(map[string]string{"item": "chair"}).Send(router).
+			AssertStatus(t, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("concurrent duplicate", func(t *testing.T) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			gyrtest.Post("/orders").Header("Idempotency-Key", "c").Header("X-Block", "1").Send(router)
+		}()
+		<-entered
+		gyrtest.Post("/orders").Header("Idempotency-Key", "c").Header("X-Block", "1").Send(router).AssertStatus(t, http.StatusConflict)
+		close(release)
+		<-done
+	})
+
+	t.Run("server errors are not stored", func(t *testing.T) {
+		gyrtest.Post("/fail").Header("Idempotency-Key", "d").Send(router).AssertStatus(t, http.StatusInternalServerError)
+		response := gyrtest.Post("/fail").Header("Idempotency-Key", "d").Send(router)
+		if response.Header().Get("Idempotent-Replayed") != "" {
+			t.Log("Expected failed response not to be replayed")
+			t.FailNow()
+		}
+	})
+}
+
+func TestIdempotencyScopedToPrincipal(t *testing.T) {
+	var created atomic.Int32
+	router := defaultTestRouter()
+	router.Middleware(func(ctx *gyr.Context) *gyr.Response {
+		ctx.SetPrincipal(&gyr.Principal{Subject: ctx.Request.Header.Get("X-User")})
+		return nil
+	}, gyr.Idempotency())
+	router.Path("/orders").Post(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Status(http.StatusCreated).Json(map[string]any{"id": created.Add(1), "user": ctx.Principal().Subject})
+	})
+
+	order := func(user string) *gyrtest.Response {
+		return gyrtest.Post("/orders").Header("X-User", user).Header("Idempotency-Key", "same").
+			JSON(map[string]string{"item": "lamp"}).Send(router)
+	}
+	order("alice").AssertJSON(t, map[string]any{"id": 1, "user": "alice"})
+	order("bob").AssertJSON(t, map[string]any{"id": 2, "user": "bob"}).AssertHeader(t, "Idempotent-Replayed", "")
+	order("alice").AssertJSON(t, map[string]any{"id": 1, "user": "alice"}).AssertHeader(t, "Idempotent-Replayed", "true")
+}
@@ -0,0 +1,431 @@
+package gyr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// A job as stored in a [JobStore]. The payload is the JSON encoded job value.
+type Job struct {
+	ID          UUID
+	Type        string
+	Payload     []byte
+	Attempts    int
+	MaxAttempts int
+	RunAt       time.Time
+	UniqueKey   string
+	LastError   string
+	// Request ID of the context the job was enqueued with, see [WithRequestID]. Not persisted by [SQLJobStore].
+	RequestID string
+}
+
+// Returned by [Enqueue] when a job with the same unique key is already pending or running.
+var ErrDuplicateJob = errors.New("job with the same unique key is already queued")
+
+// Storage for queued jobs. [NewMemoryJobStore] keeps jobs in memory, [NewSQLJobStore] persists them so
+// they survive restarts.
+type JobStore interface {
+	// Add a job. Returns [ErrDuplicateJob] if the job has a unique key that is already pending or running.
+	Push(ctx context.Context, job Job) error
+	// Claim the next job due at now. Returns nil when there is no such job.
+	Pop(ctx context.Context, now time.Time) (*Job, error)
+	// Remove a successfully handled job.
+	Complete(ctx context.Context, job Job) error
+	// Put the job back to be run again at job.RunAt.
+	Retry(ctx context.Context, job Job) error
+	// Mark the job as failed after its final attempt.
+	Fail(ctx context.Context, job Job) error
+}
+
+type JobQueueSettings struct {
+	Store JobStore
+	// Number of jobs run concurrently.
+	Concurrency int
+	// Attempts before a job is marked as failed, unless set when enqueueing.
+	MaxAttempts int
+	// Delay before the first retry. Doubled for each following attempt up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// How often the store is checked for due jobs when no job has been enqueued in the process.
+	PollInterval time.Duration
+	// Write logs to a logger of its own instead of the process logger from [Logger].
+	LogWriter io.Writer
+}
+
+func DefaultJobQueueSettings() JobQueueSettings {
+	return JobQueueSettings{
+		Store:        NewMemoryJobStore(),
+		Concurrency:  4,
+		MaxAttempts:  5,
+		Backoff:      time.Second,
+		MaxBackoff:   5 * time.Minute,
+		PollInterval: time.Second,
+	}
+}
+
+func JobStorage(store JobStore) func(*JobQueueSettings) {
+	return func(jqs *JobQueueSettings) {
+		jqs.Store = store
+	}
+}
+
+func JobConcurrency(workers int) func(*JobQueueSettings) {
+	return func(jqs *JobQueueSettings) {
+		jqs.Concurrency = workers
+	}
+}
+
+func JobMaxAttempts(attempts int) func(*JobQueueSettings) {
+	return func(jqs *JobQueueSettings) {
+		jqs.MaxAttempts = attempts
+	}
+}
+
+func JobBackoff(initial time.Duration, max time.Duration) func(*JobQueueSettings) {
+	return func(jqs *JobQueueSettings) {
+		jqs.Backoff = initial
+		jqs.MaxBackoff = max
+	}
+}
+
+func JobPollInterval(interval time.Duration) func(*JobQueueSettings) {
+	return func(jqs *JobQueueSettings) {
+		jqs.PollInterval = interval
+	}
+}
+
+func JobLogOutput(writer io.Writer) func(*JobQueueSettings) {
+	return func(jqs *JobQueueSettings) {
+		jqs.LogWriter = writer
+	}
+}
+
+// Runs jobs registered with [RegisterJob] on a pool of workers. Pass the queue to [Run] after the server so that
+// it is drained on shutdown once the server has stopped taking requests and the in-flight ones have finished.
+//
+//	err := gyr.Run(ctx, gyr.ServerComponent(server), queue)
+type JobQueue struct {
+	Settings JobQueueSettings
+	logger   *slog.Logger
+	mx       sync.Mutex
+	handlers map[string]func(context.Context, []byte) error
+	wake     chan struct{}
+	stop     chan struct{}
+	workers  sync.WaitGroup
+	started  bool
+}
+
+func NewJobQueue(settings ...SettingsFunc[JobQueueSettings]) *JobQueue {
+	queueSettings := DefaultJobQueueSettings()
+	for _, setting := range settings {
+		setting(&queueSettings)
+	}
+
+	logger := Logger().With("component", "jobs")
+	if queueSettings.LogWriter != nil {
+		logLevel := slog.LevelInfo
+		if isGyrDebug() {
+			logLevel = slog.LevelDebug
+		}
+		logger = slog.New(slog.NewTextHandler(queueSettings.LogWriter, &slog.HandlerOptions{Level: logLevel}))
+	}
+	return &JobQueue{
+		Settings: queueSettings,
+		logger:   logger,
+		handlers: make(map[string]func(context.Context, []byte) error),
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+}
+
+func jobType[T any]() string {
+	return reflect.TypeFor[T]().String()
+}
+
+// Register the handler for jobs of type T.
+func RegisterJob[T any](queue *JobQueue, handler func(context.Context, T) error) {
+	queue.mx.Lock()
+	defer queue.mx.Unlock()
+	queue.handlers[jobType[T]()] = func(ctx context.Context, payload []byte) error {
+		var job T
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return err
+		}
+		return handler(ctx, job)
+	}
+}
+
+type EnqueueSettings struct {
+	// Only one job with the key can be pending or running at a time.
+	UniqueKey string
+	// Run the job after the delay instead of immediately.
+	Delay       time.Duration
+	MaxAttempts int
+}
+
+func JobUniqueKey(key string) func(*EnqueueSettings) {
+	return func(es *EnqueueSettings) {
+		es.UniqueKey = key
+	}
+}
+
+func JobDelay(delay time.Duration) func(*EnqueueSettings) {
+	return func(es *EnqueueSettings) {
+		es.Delay = delay
+	}
+}
+
+func JobAttempts(attempts int) func(*EnqueueSettings) {
+	return func(es *EnqueueSettings) {
+		es.MaxAttempts = attempts
+	}
+}
+
+// Queue a job. It is handled by the handler registered for T with [RegisterJob].
+func Enqueue[T any](ctx context.Context, queue *JobQueue, job T, settings ...SettingsFunc[EnqueueSettings]) error {
+	enqueueSettings := EnqueueSettings{MaxAttempts: queue.Settings.MaxAttempts}
+	for _, setting := range settings {
+		setting(&enqueueSettings)
+	}
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	err = queue.Settings.Store.Push(ctx, Job{
+		ID:          NewUUID(),
+		Type:        jobType[T](),
+		Payload:     payload,
+		MaxAttempts: enqueueSettings.MaxAttempts,
+		RunAt:       CurrentClock().Now().Add(enqueueSettings.Delay),
+		UniqueKey:   enqueueSettings.UniqueKey,
+		RequestID:   RequestID(ctx),
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case queue.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start the workers. Jobs can be enqueued before the queue is started.
+func (queue *JobQueue) Start() {
+	queue.mx.Lock()
+	defer queue.mx.Unlock()
+	if queue.started {
+		return
+	}
+	queue.started = true
+	for i := 0; i < queue.Settings.Concurrency; i++ {
+		queue.workers.Add(1)
+		go queue.work()
+	}
+	queue.logger.Info("Started job queue", "workers", queue.Settings.Concurrency)
+}
+
+// Stop taking new jobs and wait for running jobs to finish, or until ctx is done.
+func (queue *JobQueue) Shutdown(ctx context.Context) error {
+	queue.mx.Lock()
+	if !queue.started {
+		queue.mx.Unlock()
+		return nil
+	}
+	queue.started = false
+	close(queue.stop)
+	queue.mx.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		queue.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		queue.logger.Info("Job queue drained")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (queue *JobQueue) work() {
+	defer queue.workers.Done()
+	ticker := time.NewTicker(queue.Settings.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-queue.stop:
+			return
+		default:
+		}
+
+		job, err := queue.Settings.Store.Pop(context.Background(), CurrentClock().Now())
+		if err != nil {
+			queue.logger.Error("Failed to fetch job", "error", err)
+		}
+		if job != nil {
+			queue.run(*job)
+			continue
+		}
+
+		select {
+		case <-queue.stop:
+			return
+		case <-queue.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (queue *JobQueue) run(job Job) {
+	ctx := context.Background()
+	queue.mx.Lock()
+	handler := queue.handlers[job.Type]
+	queue.mx.Unlock()
+
+	job.Attempts++
+	var err error
+	if handler == nil {
+		err = fmt.Errorf("no handler registered for job type %s", job.Type)
+	} else {
+		handlerCtx := WithLogAttrs(ctx, "job_id", job.ID, "job_type", job.Type)
+		if job.RequestID != "" {
+			handlerCtx = WithRequestID(handlerCtx, job.RequestID)
+		}
+		err = runJobHandler(handlerCtx, handler, job.Payload)
+	}
+
+	if err == nil {
+		queue.logger.Debug("Job completed", "id", job.ID, "type", job.Type)
+		if err := queue.Settings.Store.Complete(ctx, job); err != nil {
+			queue.logger.Error("Failed to complete job", "id", job.ID, "error", err)
+		}
+		return
+	}
+
+	job.LastError = err.Error()
+	if job.Attempts >= job.MaxAttempts {
+		queue.logger.Error("Job failed", "id", job.ID, "type", job.Type, "attempts", job.Attempts, "error", err)
+		if err := queue.Settings.Store.Fail(ctx, job); err != nil {
+			queue.logger.Error("Failed to mark job as failed", "id", job.ID, "error", err)
+		}
+		return
+	}
+
+	job.RunAt = CurrentClock().Now().Add(queue.retryPolicy().Delay(job.Attempts))
+	queue.logger.Warn("Job failed, retrying", "id", job.ID, "type", job.Type, "attempts", job.Attempts, "retry_at", job.RunAt, "error", err)
+	if err := queue.Settings.Store.Retry(ctx, job); err != nil {
+		queue.logger.Error("Failed to retry job", "id", job.ID, "error", err)
+	}
+}
+
+// Panics in handlers fail the attempt instead of crashing the worker.
+func runJobHandler(ctx context.Context, handler func(context.Context, []byte) error, payload []byte) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return handler(ctx, payload)
+}
+
+func (queue *JobQueue) retryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: queue.Settings.MaxAttempts,
+		Backoff:     queue.Settings.Backoff,
+		MaxBackoff:  queue.Settings.MaxBackoff,
+	}
+}
+
+// Keeps jobs in memory. Jobs are lost when the process exits.
+type MemoryJobStore struct {
+	mx      sync.Mutex
+	pending []Job
+	running map[UUID]Job
+	failed  []Job
+}
+
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{
+		pending: make([]Job, 0),
+		running: make(map[UUID]Job),
+		failed:  make([]Job, 0),
+	}
+}
+
+func (store *MemoryJobStore) Push(ctx context.Context, job Job) error {
+	store.mx.Lock()
+	defer store.mx.Unlock()
+	if job.UniqueKey != "" {
+		sameKey := func(other Job) bool { return other.UniqueKey == job.UniqueKey }
+		if slices.ContainsFunc(store.pending, sameKey) {
+			return ErrDuplicateJob
+		}
+		for _, other := range store.running {
+			if sameKey(other) {
+				return ErrDuplicateJob
+			}
+		}
+	}
+	store.pending = append(store.pending, job)
+	return nil
+}
+
+func (store *MemoryJobStore) Pop(ctx context.Context, now time.Time) (*Job, error) {
+	store.mx.Lock()
+	defer store.mx.Unlock()
+	next := -1
+	for i, job := range store.pending {
+		if !job.RunAt.After(now) && (next == -1 || job.RunAt.Before(store.pending[next].RunAt)) {
+			next = i
+		}
+	}
+	if next == -1 {
+		return nil, nil
+	}
+	job := store.pending[next]
+	store.pending = slices.Delete(store.pending, next, next+1)
+	store.running[job.ID] = job
+	return &job, nil
+}
+
+func (store *MemoryJobStore) Complete(ctx context.Context, job Job) error {
+	store.mx.Lock()
+	defer store.mx.Unlock()
+	delete(store.running, job.ID)
+	return nil
+}
+
+func (store *MemoryJobStore) Retry(ctx context.Context, job Job) error {
+	store.mx.Lock()
+	defer store.mx.Unlock()
+	delete(store.running, job.ID)
+	store.pending = append(store.pending, job)
+	return nil
+}
+
+func (store *MemoryJobStore) Fail(ctx context.Context, job Job) error {
+	store.mx.Lock()
+	defer store.mx.Unlock()
+	delete(store.running, job.ID)
+	store.failed = append(store.failed, job)
+	return nil
+}
+
+// Get the jobs that ran out of attempts.
+func (store *MemoryJobStore) FailedJobs() []Job {
+	store.mx.Lock()
+	defer store.mx.Unlock()
+	return slices.Clone(store.failed)
+}
@@ -0,0 +1,224 @@
+package gyr_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+type sendEmail struct {
+	To string `json:"to"`
+}
+
+func newTestQueue(settings ...gyr.SettingsFunc[gyr.JobQueueSettings]) *gyr.JobQueue {
+	settings = append([]gyr.SettingsFunc[gyr.JobQueueSettings]{
+		gyr.JobLogOutput(io.Discard),
+		gyr.JobPollInterval(time.Millisecond),
+		gyr.JobBackoff(time.Millisecond, 5*time.Millisecond),
+	}, settings...)
+	return gyr.NewJobQueue(settings...)
+}
+
+func TestJobQueueRunsTypedJobs(t *testing.T) {
+	queue := newTestQueue()
+	received := make(chan string, 1)
+	gyr.RegisterJob(queue, func(ctx context.Context, job sendEmail) error {
+		received <- job.To
+		return nil
+	})
+	queue.Start()
+	defer queue.Shutdown(context.Background())
+
+	if err := gyr.Enqueue(context.Background(), queue, sendEmail{To: "kalle@example.com"}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	select {
+	case to := <-received:
+		if to != "kalle@example.com" {
+			t.Logf("Received %s\n", to)
+			t.FailNow()
+		}
+	case <-time.After(time.Second):
+		t.Log("Job was not run")
+		t.FailNow()
+	}
+}
+
+func TestJobQueueRetries(t *testing.T) {
+	store := gyr.NewMemoryJobStore()
+	queue := newTestQueue(gyr.JobStorage(store), gyr.JobMaxAttempts(3))
+	var attempts atomic.Int32
+	gyr.RegisterJob(queue, func(ctx context.Context, job sendEmail) error {
+		attempts.Add(1)
+		return errors.New("smtp down")
+	})
+	gyr.Enqueue(context.Background(), queue, sendEmail{To: "a"})
+	queue.Start()
+
+	deadline := time.Now().Add(time.Second)
+	for len(store.FailedJobs()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	queue.Shutdown(context.Background())
+	failed := store.FailedJobs()
+	if len(failed) != 1 || attempts.Load() != 3 || failed[0].LastError != "smtp down" {
+		t.Logf("Attempts %d, failed %+v\n", attempts.Load(), failed)
+		t.FailNow()
+	}
+}
+
+func TestJobQueueUniqueness(t *testing.T) {
+	queue := newTestQueue()
+	ctx := context.Background()
+	if err := gyr.Enqueue(ctx, queue, sendEmail{To: "a"}, gyr.JobUniqueKey("welcome-a")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := gyr.Enqueue(ctx, queue, sendEmail{To: "a"}, gyr.JobUniqueKey("welcome-a")); !errors.Is(err, gyr.ErrDuplicateJob) {
+		t.Logf("Expected ErrDuplicateJob. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestJobQueueShutdownDrains(t *testing.T) {
+	queue := newTestQueue()
+	var finished atomic.Bool
+	started := make(chan struct{})
+	gyr.RegisterJob(queue, func(ctx context.Context, job sendEmail) error {
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	})
+	queue.Start()
+	gyr.Enqueue(context.Background(), queue, sendEmail{To: "a"})
+	<-started
+	if err := queue.Shutdown(context.Background()); err != nil || !finished.Load() {
+		t.Logf("Expected running job to finish before shutdown returned (%v)\n", err)
+		t.FailNow()
+	}
+}
+
+func TestRunDrainsJobQueueWithServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("can't listen:", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	queue := newTestQueue()
+	var finished atomic.Bool
+	started := make(chan struct{})
+	gyr.RegisterJob(queue, func(ctx context.Context, job sendEmail) error {
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	})
+	router := defaultTestRouter()
+	router.Path("/signup").Post(func(ctx *gyr.Context) *gyr.Response {
+		if err := gyr.Enqueue(ctx.Context(), queue, sendEmail{To: "a"}); err != nil {
+			return ctx.Response().InternalError()
+		}
+		return ctx.Response().Status(http.StatusAccepted)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- gyr.Run(ctx, gyr.ServerComponent(&http.Server{Addr: addr, Handler: router}), queue)
+	}()
+	for range 100 {
+		var response *http.Response
+		if response, err = http.Post("http://"+addr+"/signup", "", nil); err == nil {
+			response.Body.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Logf("Request failed: %v\n", err)
+		t.FailNow()
+	}
+	<-started
+	cancel()
+
+	if err := <-runErr; err != nil || !finished.Load() {
+		t.Logf("Expected the running job to finish before Run returned (%v)\n", err)
+		t.FailNow()
+	}
+}
+
+func TestRunDrainsJobsEnqueuedDuringShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("can't listen:", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	queue := newTestQueue()
+	var finished atomic.Bool
+	gyr.RegisterJob(queue, func(ctx context.Context, job sendEmail) error {
+		finished.Store(true)
+		return nil
+	})
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	router := defaultTestRouter()
+	router.Path("/signup").Post(func(ctx *gyr.Context) *gyr.Response {
+		close(entered)
+		<-release
+		if err := gyr.Enqueue(context.Background(), queue, sendEmail{To: "a"}); err != nil {
+			return ctx.Response().InternalError()
+		}
+		return ctx.Response().Status(http.StatusAccepted)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- gyr.Run(ctx, gyr.ServerComponent(&http.Server{Addr: addr, Handler: router}), queue)
+	}()
+	requestErr := make(chan error, 1)
+	go func() {
+		for range 100 {
+			response, err := http.Post("http://"+addr+"/signup", "", nil)
+			if err == nil {
+				response.Body.Close()
+				requestErr <- nil
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		requestErr <- errors.New("server never started")
+	}()
+	select {
+	case <-entered:
+	case err := <-requestErr:
+		t.Logf("Request failed: %v\n", err)
+		t.FailNow()
+	}
+	// The job is enqueued while the server is shutting down.
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if err := <-requestErr; err != nil {
+		t.Logf("Request failed: %v\n", err)
+		t.FailNow()
+	}
+	if err := <-runErr; err != nil || !finished.Load() {
+		t.Logf("Expected the job enqueued during shutdown to run before Run returned (%v)\n", err)
+		t.FailNow()
+	}
+}
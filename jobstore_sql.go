@@ -0,0 +1,99 @@
+package gyr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	jobStatusPending = "pending"
+	jobStatusRunning = "running"
+	jobStatusFailed  = "failed"
+)
+
+// Persists jobs in the gyr_jobs table so they survive restarts. Create the table with [MigrateStores] or
+// [SQLJobStore.Setup].
+type SQLJobStore struct {
+	connection DBTX
+	dialect    Dialect
+}
+
+func NewSQLJobStore(connection DBTX, dialect Dialect) *SQLJobStore {
+	return &SQLJobStore{connection: connection, dialect: dialect}
+}
+
+// Create the gyr_jobs table if it does not exist.
+func (store *SQLJobStore) Setup(ctx context.Context) error {
+	_, err := store.connection.ExecContext(ctx, StoreJobs.ddl(store.dialect))
+	return err
+}
+
+func (store *SQLJobStore) Push(ctx context.Context, job Job) error {
+	if job.UniqueKey != "" {
+		const query = "select id from gyr_jobs where unique_key = ? and status in (?, ?)"
+		var id string
+		err := store.connection.QueryRowContext(ctx, store.dialect.Rebind(query), job.UniqueKey, jobStatusPending, jobStatusRunning).Scan(&id)
+		if err == nil {
+			return ErrDuplicateJob
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+
+	const query = "insert into gyr_jobs (id, type, payload, attempts, max_attempts, run_at, unique_key, status, last_error) values (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	_, err := store.connection.ExecContext(ctx, store.dialect.Rebind(query), job.ID.String(), job.Type, string(job.Payload), job.Attempts, job.MaxAttempts, job.RunAt, job.UniqueKey, jobStatusPending, job.LastError)
+	return err
+}
+
+// Claims the job by moving it from pending to running. If another worker claims it first the next job is tried.
+func (store *SQLJobStore) Pop(ctx context.Context, now time.Time) (*Job, error) {
+	for {
+		const query = "select id, type, payload, attempts, max_attempts, run_at, unique_key, last_error from gyr_jobs where status = ? and run_at <= ? order by run_at limit 1"
+		var job Job
+		var id, payload string
+		var uniqueKey, lastError sql.NullString
+		err := store.connection.QueryRowContext(ctx, store.dialect.Rebind(query), jobStatusPending, now).
+			Scan(&id, &job.Type, &payload, &job.Attempts, &job.MaxAttempts, &job.RunAt, &uniqueKey, &lastError)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		job.ID, err = ParseUUID(id)
+		if err != nil {
+			return nil, err
+		}
+		job.Payload = []byte(payload)
+		job.UniqueKey = uniqueKey.String
+		job.LastError = lastError.String
+
+		const claim = "update gyr_jobs set status = ? where id = ? and status = ?"
+		result, err := store.connection.ExecContext(ctx, store.dialect.Rebind(claim), jobStatusRunning, id, jobStatusPending)
+		if err != nil {
+			return nil, err
+		}
+		if claimed, err := result.RowsAffected(); err != nil || claimed == 1 {
+			return &job, err
+		}
+	}
+}
+
+func (store *SQLJobStore) Complete(ctx context.Context, job Job) error {
+	const query = "delete from gyr_jobs where id = ?"
+	_, err := store.connection.ExecContext(ctx, store.dialect.Rebind(query), job.ID.String())
+	return err
+}
+
+func (store *SQLJobStore) Retry(ctx context.Context, job Job) error {
+	const query = "update gyr_jobs set status = ?, attempts = ?, run_at = ?, last_error = ? where id = ?"
+	_, err := store.connection.ExecContext(ctx, store.dialect.Rebind(query), jobStatusPending, job.Attempts, job.RunAt, job.LastError, job.ID.String())
+	return err
+}
+
+func (store *SQLJobStore) Fail(ctx context.Context, job Job) error {
+	const query = "update gyr_jobs set status = ?, attempts = ?, last_error = ? where id = ?"
+	_, err := store.connection.ExecContext(ctx, store.dialect.Rebind(query), jobStatusFailed, job.Attempts, job.LastError, job.ID.String())
+	return err
+}
@@ -0,0 +1,211 @@
+package gyr
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+var ErrTokenExpired = errors.New("token expired")
+
+// Claims of a JSON Web Token. Numeric claims such as exp are float64 after parsing.
+type JWTClaims map[string]any
+
+type JWTOptions struct {
+	// Key of HS256 tokens. Tokens signed with HS256 are rejected when nil.
+	Secret []byte
+	// Key of RS256 tokens. Tokens signed with RS256 are rejected when nil.
+	PublicKey *rsa.PublicKey
+	// Required iss claim. Empty accepts any issuer.
+	Issuer string
+	// Required value of the aud claim, which may hold several audiences. Empty accepts any audience.
+	Audience string
+	// Clock skew allowed when checking exp and nbf.
+	Leeway time.Duration
+}
+
+var jwtEncoding = base64.RawURLEncoding
+
+// Sign claims into a token with key, which is a []byte for HS256 or an *rsa.PrivateKey for RS256.
+func SignJWT(claims JWTClaims, key any) (string, error) {
+	var algorithm string
+	switch key.(type) {
+	case []byte:
+		algorithm = "HS256"
+	case *rsa.PrivateKey:
+		algorithm = "RS256"
+	default:
+		return "", fmt.Errorf("unsupported JWT key type %T", key)
+	}
+	header, err := json.Marshal(map[string]string{"alg": algorithm, "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := jwtEncoding.EncodeToString(header) + "." + jwtEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			return "", err
+		}
+	}
+	return signed + "." + jwtEncoding.EncodeToString(signature), nil
+}
+
+// Verify the signature of token with the key of its algorithm in options and check its exp, nbf, iss and aud
+// claims. Errors wrap [ErrInvalidToken] or [ErrTokenExpired].
+func VerifyJWT(token string, options JWTOptions) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	signature, err := jwtEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	signed := parts[0] + "." + parts[1]
+	switch {
+	case header.Algorithm == "HS256" && options.Secret != nil:
+		mac := hmac.New(sha256.New, options.Secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	case header.Algorithm == "RS256" && options.PublicKey != nil:
+		digest := sha256.Sum256([]byte(signed))
+		if rsa.VerifyPKCS1v15(options.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+			return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unexpected algorithm %q", ErrInvalidToken, header.Algorithm)
+	}
+
+	var claims JWTClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := claims.validate(options); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeJWTPart(part string, v any) error {
+	decoded, err := jwtEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(decoded, v); err != nil {
+		return fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+	return nil
+}
+
+func (claims JWTClaims) validate(options JWTOptions) error {
+	now := CurrentClock().Now()
+	if exp, ok := claims["exp"].(float64); ok && !now.Before(time.Unix(int64(exp), 0).Add(options.Leeway)) {
+		return ErrTokenExpired
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(options.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+	if options.Issuer != "" && claims.String("iss") != options.Issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if options.Audience != "" && !slices.Contains(claims.Strings("aud"), options.Audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return nil
+}
+
+// The claim name as a string, or an empty string if it isn't one.
+func (claims JWTClaims) String(name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// The claim name as a list of strings. A single string is a list of one, and a string claim named scope is
+// split on spaces as in OAuth 2.0.
+func (claims JWTClaims) Strings(name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		if name == "scope" {
+			return strings.Fields(value)
+		}
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// Authenticate requests with a Bearer token verified by [VerifyJWT]. The sub claim becomes the Subject of the
+// [Principal], scope or scp its Scopes, roles its Roles and every claim its Claims, so [Authorize] can check
+// them. Requests without a valid token get 401 Unauthorized.
+//
+//	router.Middleware(gyr.JWTAuth(gyr.JWTOptions{Secret: secret, Issuer: "https://auth.example.com"}), gyr.Authorize())
+func JWTAuth(options JWTOptions) Handler {
+	return func(ctx *Context) *Response {
+		token, isBearer := strings.CutPrefix(ctx.Request.Header.Get("Authorization"), "Bearer ")
+		if !isBearer || token == "" {
+			return ctx.Response().
+				Status(http.StatusUnauthorized).
+				Header("WWW-Authenticate", "Bearer").
+				Text("401 - Unauthorized")
+		}
+		claims, err := VerifyJWT(strings.TrimSpace(token), options)
+		if err != nil {
+			ctx.Logger().Info("Rejected token", "err", err)
+			return ctx.Response().
+				Status(http.StatusUnauthorized).
+				Header("WWW-Authenticate", `Bearer error="invalid_token"`).
+				Text("401 - Unauthorized")
+		}
+		scopes := claims.Strings("scope")
+		if scopes == nil {
+			scopes = claims.Strings("scp")
+		}
+		ctx.SetPrincipal(&Principal{
+			Subject: claims.String("sub"),
+			Scopes:  scopes,
+			Roles:   claims.Strings("roles"),
+			Claims:  claims,
+		})
+		return nil
+	}
+}
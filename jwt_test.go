@@ -0,0 +1,100 @@
+package gyr_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestJWT(t *testing.T) {
+	clock := gyr.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	gyr.SetClock(clock)
+	defer gyr.SetClock(nil)
+
+	secret := []byte("secret")
+	claims := gyr.JWTClaims{"sub": "42", "iss": "auth", "aud": []string{"api", "web"}, "exp": clock.Now().Add(time.Hour).Unix()}
+	options := gyr.JWTOptions{Secret: secret, Issuer: "auth", Audience: "api"}
+
+	t.Run("HS256", func(t *testing.T) {
+		token, err := gyr.SignJWT(claims, secret)
+		if err != nil {
+			t.Logf("Failed signing token: %v", err)
+			t.FailNow()
+		}
+		verified, err := gyr.VerifyJWT(token, options)
+		if err != nil || verified.String("sub") != "42" {
+			t.Logf("Expected valid token with sub 42. Received %v %v", verified, err)
+			t.FailNow()
+		}
+		if _, err := gyr.VerifyJWT(token, gyr.JWTOptions{Secret: []byte("other")}); !errors.Is(err, gyr.ErrInvalidToken) {
+			t.Logf("Expected ErrInvalidToken for the wrong secret. Received %v", err)
+			t.FailNow()
+		}
+		if _, err := gyr.VerifyJWT(token, gyr.JWTOptions{Secret: secret, Audience: "admin"}); !errors.Is(err, gyr.ErrInvalidToken) {
+			t.Logf("Expected ErrInvalidToken for the wrong audience. Received %v", err)
+			t.FailNow()
+		}
+	})
+
+	t.Run("RS256", func(t *testing.T) {
+		key, _ := rsa.GenerateKey(rand.Reader, 2048)
+		token, err := gyr.SignJWT(claims, key)
+		if err != nil {
+			t.Logf("Failed signing token: %v", err)
+			t.FailNow()
+		}
+		if _, err := gyr.VerifyJWT(token, gyr.JWTOptions{PublicKey: &key.PublicKey}); err != nil {
+			t.Logf("Expected valid token. Received %v", err)
+			t.FailNow()
+		}
+		// A token signed with HS256 must not be accepted when only an RSA key is configured.
+		hmacToken, _ := gyr.SignJWT(claims, []byte("public key bytes"))
+		if _, err := gyr.VerifyJWT(hmacToken, gyr.JWTOptions{PublicKey: &key.PublicKey}); !errors.Is(err, gyr.ErrInvalidToken) {
+			t.Logf("Expected ErrInvalidToken for an unexpected algorithm. Received %v", err)
+			t.FailNow()
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		token, _ := gyr.SignJWT(claims, secret)
+		clock.Advance(2 * time.Hour)
+		defer clock.Advance(-2 * time.Hour)
+		if _, err := gyr.VerifyJWT(token, options); !errors.Is(err, gyr.ErrTokenExpired) {
+			t.Logf("Expected ErrTokenExpired. Received %v", err)
+			t.FailNow()
+		}
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		router := defaultTestRouter()
+		router.Middleware(gyr.JWTAuth(options), gyr.Authorize())
+		router.Path("/orders").Post(func(ctx *gyr.Context) *gyr.Response {
+			return ctx.Response().Text(ctx.Principal().Subject)
+		}).RequireScopes("orders:write")
+
+		gyrtest.Post("/orders").Send(router).
+			AssertStatus(t, http.StatusUnauthorized).
+			AssertHeader(t, "WWW-Authenticate", "Bearer")
+		gyrtest.Post("/orders").Header("Authorization", "Bearer nonsense").Send(router).
+			AssertStatus(t, http.StatusUnauthorized).
+			AssertHeader(t, "WWW-Authenticate", `Bearer error="invalid_token"`)
+
+		readOnly, _ := gyr.SignJWT(gyr.JWTClaims{"sub": "42", "iss": "auth", "aud": "api", "scope": "orders:read"}, secret)
+		gyrtest.Post("/orders").Header("Authorization", "Bearer "+readOnly).Send(router).
+			AssertStatus(t, http.StatusForbidden)
+
+		writer, _ := gyr.SignJWT(gyr.JWTClaims{"sub": "42", "iss": "auth", "aud": "api", "scope": "orders:read orders:write"}, secret)
+		response := gyrtest.Post("/orders").Header("Authorization", "Bearer "+writer).Send(router).
+			AssertStatus(t, http.StatusOK)
+		if response.Body.String() != "42" {
+			t.Logf("Expected the subject as principal. Received %q", response.Body.String())
+			t.FailNow()
+		}
+	})
+}
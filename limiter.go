@@ -0,0 +1,195 @@
+package gyr
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Token bucket allowing rate events per second on average, with bursts of up to burst events.
+// Safe for concurrent use.
+type Limiter struct {
+	mx       sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+	lastUsed time.Time
+}
+
+// Create a limiter that starts with a full bucket.
+func NewLimiter(rate float64, burst int) *Limiter {
+	now := CurrentClock().Now()
+	return &Limiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: now, lastUsed: now}
+}
+
+// Must be called with the lock held.
+func (limiter *Limiter) refill(now time.Time) {
+	if elapsed := now.Sub(limiter.last).Seconds(); elapsed > 0 {
+		limiter.tokens = min(limiter.burst, limiter.tokens+elapsed*limiter.rate)
+		limiter.last = now
+	}
+	limiter.lastUsed = now
+}
+
+// Take a token if one is available.
+func (limiter *Limiter) Allow() bool {
+	return limiter.AllowN(1)
+}
+
+// Take n tokens if that many are available.
+func (limiter *Limiter) AllowN(n int) bool {
+	limiter.mx.Lock()
+	defer limiter.mx.Unlock()
+	limiter.refill(CurrentClock().Now())
+	if limiter.tokens < float64(n) {
+		return false
+	}
+	limiter.tokens -= float64(n)
+	return true
+}
+
+// How long until a token is available. 0 if one is available now.
+func (limiter *Limiter) Delay() time.Duration {
+	limiter.mx.Lock()
+	defer limiter.mx.Unlock()
+	limiter.refill(CurrentClock().Now())
+	return limiter.delay(1)
+}
+
+// Must be called with the lock held.
+func (limiter *Limiter) delay(n float64) time.Duration {
+	if limiter.tokens >= n {
+		return 0
+	}
+	if limiter.rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration((n - limiter.tokens) / limiter.rate * float64(time.Second))
+}
+
+// Block until a token is available and take it, or until ctx is done. Meant for staying within quotas of
+// outbound APIs and throttling jobs.
+func (limiter *Limiter) Wait(ctx context.Context) error {
+	for {
+		limiter.mx.Lock()
+		limiter.refill(CurrentClock().Now())
+		delay := limiter.delay(1)
+		if delay == 0 {
+			limiter.tokens--
+			limiter.mx.Unlock()
+			return nil
+		}
+		limiter.mx.Unlock()
+
+		timer := CurrentClock().NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+type KeyedLimiterSettings struct {
+	// Limiters unused for this long are removed. Their key starts over with a full bucket when used again.
+	IdleTTL time.Duration
+}
+
+func DefaultKeyedLimiterSettings() KeyedLimiterSettings {
+	return KeyedLimiterSettings{IdleTTL: 10 * time.Minute}
+}
+
+func LimiterIdleTTL(ttl time.Duration) func(*KeyedLimiterSettings) {
+	return func(kls *KeyedLimiterSettings) {
+		kls.IdleTTL = ttl
+	}
+}
+
+// A separate [Limiter] per key, for example per client IP or per API token.
+type KeyedLimiter[K comparable] struct {
+	mx        sync.Mutex
+	rate      float64
+	burst     int
+	limiters  map[K]*Limiter
+	lastSweep time.Time
+	Settings  KeyedLimiterSettings
+}
+
+func NewKeyedLimiter[K comparable](rate float64, burst int, settings ...SettingsFunc[KeyedLimiterSettings]) *KeyedLimiter[K] {
+	limiterSettings := DefaultKeyedLimiterSettings()
+	for _, setting := range settings {
+		setting(&limiterSettings)
+	}
+	return &KeyedLimiter[K]{
+		rate:      rate,
+		burst:     burst,
+		limiters:  make(map[K]*Limiter),
+		lastSweep: CurrentClock().Now(),
+		Settings:  limiterSettings,
+	}
+}
+
+// The limiter for key, created if needed. Idle limiters are swept at most once per IdleTTL.
+func (keyed *KeyedLimiter[K]) Get(key K) *Limiter {
+	keyed.mx.Lock()
+	defer keyed.mx.Unlock()
+	now := CurrentClock().Now()
+	if keyed.Settings.IdleTTL > 0 && now.Sub(keyed.lastSweep) >= keyed.Settings.IdleTTL {
+		keyed.sweep(now)
+	}
+	limiter, exists := keyed.limiters[key]
+	if !exists {
+		limiter = NewLimiter(keyed.rate, keyed.burst)
+		keyed.limiters[key] = limiter
+	}
+	return limiter
+}
+
+// Must be called with the lock held.
+func (keyed *KeyedLimiter[K]) sweep(now time.Time) {
+	for key, limiter := range keyed.limiters {
+		limiter.mx.Lock()
+		idle := now.Sub(limiter.lastUsed) >= keyed.Settings.IdleTTL
+		limiter.mx.Unlock()
+		if idle {
+			delete(keyed.limiters, key)
+		}
+	}
+	keyed.lastSweep = now
+}
+
+func (keyed *KeyedLimiter[K]) Allow(key K) bool {
+	return keyed.Get(key).Allow()
+}
+
+func (keyed *KeyedLimiter[K]) Wait(ctx context.Context, key K) error {
+	return keyed.Get(key).Wait(ctx)
+}
+
+// Number of keys currently tracked.
+func (keyed *KeyedLimiter[K]) Len() int {
+	keyed.mx.Lock()
+	defer keyed.mx.Unlock()
+	return len(keyed.limiters)
+}
+
+// Middleware responding with 429 Too Many Requests and a Retry-After header when the limiter for the key
+// of the request has no tokens left.
+func RateLimit(limiter *KeyedLimiter[string], key func(*Context) string) Handler {
+	return func(ctx *Context) *Response {
+		requestLimiter := limiter.Get(key(ctx))
+		if requestLimiter.Allow() {
+			return nil
+		}
+		retryAfter := int(math.Ceil(requestLimiter.Delay().Seconds()))
+		return ctx.Response().
+			Status(http.StatusTooManyRequests).
+			Header("Retry-After", strconv.Itoa(max(retryAfter, 1))).
+			Text("429 - Too Many Requests")
+	}
+}
@@ -0,0 +1,68 @@
+package gyr
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func fakeLimiterTime(t *testing.T) *FakeClock {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	SetClock(clock)
+	t.Cleanup(func() { SetClock(nil) })
+	return clock
+}
+
+func TestLimiterRefills(t *testing.T) {
+	clock := fakeLimiterTime(t)
+	limiter := NewLimiter(2, 3)
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow() {
+			t.Logf("Expected burst of 3, denied at %d\n", i)
+			t.FailNow()
+		}
+	}
+	if limiter.Allow() {
+		t.Log("Expected empty bucket")
+		t.FailNow()
+	}
+	if delay := limiter.Delay(); delay != 500*time.Millisecond {
+		t.Logf("Expected 500ms until next token. Received %v\n", delay)
+		t.FailNow()
+	}
+
+	clock.Advance(time.Second)
+	if !limiter.AllowN(2) || limiter.Allow() {
+		t.Log("Expected exactly 2 tokens after a second")
+		t.FailNow()
+	}
+}
+
+func TestLimiterWaitStopsOnContext(t *testing.T) {
+	fakeLimiterTime(t)
+	limiter := NewLimiter(0.001, 1)
+	limiter.Allow()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Logf("Expected deadline exceeded. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestKeyedLimiterSweepsIdleKeys(t *testing.T) {
+	clock := fakeLimiterTime(t)
+	keyed := NewKeyedLimiter[string](1, 1, LimiterIdleTTL(time.Minute))
+	keyed.Allow("a")
+	if keyed.Allow("a") || !keyed.Allow("b") {
+		t.Log("Expected keys to be limited separately")
+		t.FailNow()
+	}
+
+	clock.Advance(2 * time.Minute)
+	keyed.Allow("c")
+	if keyed.Len() != 1 {
+		t.Logf("Expected idle keys to be swept. %d keys left\n", keyed.Len())
+		t.FailNow()
+	}
+}
@@ -0,0 +1,31 @@
+package gyr_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	router := defaultTestRouter()
+	limiter := gyr.NewKeyedLimiter[string](0.5, 1)
+	router.Middleware(gyr.RateLimit(limiter, func(ctx *gyr.Context) string {
+		return ctx.Request.Header.Get("X-Client")
+	}))
+
+	send := func(client string) *http.Response {
+		request, _ := http.NewRequest(http.MethodGet, "/test", nil)
+		request.Header.Set("X-Client", client)
+		return sendRequest(router, request).Result()
+	}
+	if send("a").StatusCode != http.StatusOK || send("b").StatusCode != http.StatusOK {
+		t.Log("Expected first request of each client to pass")
+		t.FailNow()
+	}
+	limited := send("a")
+	if limited.StatusCode != http.StatusTooManyRequests || limited.Header.Get("Retry-After") != "2" {
+		t.Logf("Expected 429 with Retry-After 2. Received %d %q\n", limited.StatusCode, limited.Header.Get("Retry-After"))
+		t.FailNow()
+	}
+}
@@ -0,0 +1,180 @@
+package gyr
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Filter operators accepted in filter parameters and the SQL operators they map to.
+var filterOperators = map[string]string{
+	"eq":   "=",
+	"ne":   "<>",
+	"lt":   "<",
+	"lte":  "<=",
+	"gt":   ">",
+	"gte":  ">=",
+	"like": "like",
+}
+
+type Filter struct {
+	Column   string
+	Operator string
+	Value    string
+}
+
+type Sort struct {
+	Column     string
+	Descending bool
+}
+
+// Filtering, sorting and pagination parsed from a query string such as
+// ?filter[name]=eq:kalle&sort=-created_at,name&page=2&page_size=50.
+type ListQuery struct {
+	Filters  []Filter
+	Sort     []Sort
+	Page     int
+	PageSize int
+}
+
+type ListQuerySettings struct {
+	// Columns that may be filtered on. Filters on other columns are rejected.
+	Filterable []string
+	// Columns that may be sorted by. Sorting by other columns is rejected.
+	Sortable        []string
+	DefaultPageSize int
+	MaxPageSize     int
+}
+
+func DefaultListQuerySettings() ListQuerySettings {
+	return ListQuerySettings{
+		Filterable:      make([]string, 0),
+		Sortable:        make([]string, 0),
+		DefaultPageSize: 20,
+		MaxPageSize:     100,
+	}
+}
+
+func ListFilterable(columns ...string) func(*ListQuerySettings) {
+	return func(lqs *ListQuerySettings) {
+		lqs.Filterable = columns
+	}
+}
+
+func ListSortable(columns ...string) func(*ListQuerySettings) {
+	return func(lqs *ListQuerySettings) {
+		lqs.Sortable = columns
+	}
+}
+
+func ListPageSize(defaultSize int, maxSize int) func(*ListQuerySettings) {
+	return func(lqs *ListQuerySettings) {
+		lqs.DefaultPageSize = defaultSize
+		lqs.MaxPageSize = maxSize
+	}
+}
+
+// Parse the filter, sort, page and page_size parameters of values. A filter without an operator, like
+// filter[name]=kalle, means eq. Every invalid parameter is reported in the returned [ValidationErrors].
+func ParseListQuery(values url.Values, settings ...SettingsFunc[ListQuerySettings]) (ListQuery, error) {
+	listSettings := DefaultListQuerySettings()
+	for _, setting := range settings {
+		setting(&listSettings)
+	}
+
+	listQuery := ListQuery{Filters: make([]Filter, 0), Sort: make([]Sort, 0), Page: 1, PageSize: listSettings.DefaultPageSize}
+	errs := make(ValidationErrors, 0)
+	for key, keyValues := range values {
+		column, isFilter := strings.CutPrefix(key, "filter[")
+		if !isFilter || !strings.HasSuffix(column, "]") {
+			continue
+		}
+		column = strings.TrimSuffix(column, "]")
+		if !slices.Contains(listSettings.Filterable, column) {
+			errs = append(errs, ValidationError{Field: key, Rule: "filterable", Message: "can not be filtered on", Code: validationCode("filterable")})
+			continue
+		}
+		for _, value := range keyValues {
+			operator, operand, hasOperator := strings.Cut(value, ":")
+			if _, isOperator := filterOperators[operator]; !hasOperator || !isOperator {
+				operator, operand = "eq", value
+			}
+			listQuery.Filters = append(listQuery.Filters, Filter{Column: column, Operator: operator, Value: operand})
+		}
+	}
+	// Map iteration order is random, keep the generated SQL stable.
+	slices.SortStableFunc(listQuery.Filters, func(a, b Filter) int {
+		return strings.Compare(a.Column, b.Column)
+	})
+
+	if sortParam := values.Get("sort"); sortParam != "" {
+		for _, field := range strings.Split(sortParam, ",") {
+			column, descending := strings.CutPrefix(strings.TrimSpace(field), "-")
+			if !slices.Contains(listSettings.Sortable, column) {
+				errs = append(errs, ValidationError{Field: "sort", Rule: "sortable", Param: column, Message: "can not sort by " + column, Code: validationCode("sortable")})
+				continue
+			}
+			listQuery.Sort = append(listQuery.Sort, Sort{Column: column, Descending: descending})
+		}
+	}
+
+	if pageParam := values.Get("page"); pageParam != "" {
+		page, err := strconv.Atoi(pageParam)
+		if err != nil || page < 1 {
+			errs = append(errs, ValidationError{Field: "page", Rule: "min", Param: "1", Message: "must be a number of at least 1", Code: validationCode("min")})
+		} else {
+			listQuery.Page = page
+		}
+	}
+	if sizeParam := values.Get("page_size"); sizeParam != "" {
+		size, err := strconv.Atoi(sizeParam)
+		if err != nil || size < 1 || size > listSettings.MaxPageSize {
+			maxSize := strconv.Itoa(listSettings.MaxPageSize)
+			errs = append(errs, ValidationError{Field: "page_size", Rule: "max", Param: maxSize, Message: "must be a number between 1 and " + maxSize, Code: validationCode("max")})
+		} else {
+			listQuery.PageSize = size
+		}
+	}
+
+	if len(errs) > 0 {
+		return listQuery, errs
+	}
+	return listQuery, nil
+}
+
+// Parse the list query of the request, see [ParseListQuery].
+func ReadListQuery(ctx *Context, settings ...SettingsFunc[ListQuerySettings]) (ListQuery, error) {
+	return ParseListQuery(ctx.Request.URL.Query(), settings...)
+}
+
+// Offset of the first row on the requested page.
+func (listQuery ListQuery) Offset() int {
+	return (listQuery.Page - 1) * listQuery.PageSize
+}
+
+// Create a select query for the entity with the filters, sorting and pagination of listQuery, and the
+// arguments for its placeholders. The entity must be registered using [RegisterEntity].
+func ApplyListQuery[EntityType any](qb *QueryBuilder[EntityType], listQuery ListQuery) (string, []any) {
+	var builder ListBuilder = qb.SelectAll()
+	args := make([]any, 0, len(listQuery.Filters))
+	for i, filter := range listQuery.Filters {
+		var where WhereBuilder
+		if i == 0 {
+			where = qb.Where(filter.Column)
+		} else {
+			where = qb.And(filter.Column)
+		}
+		builder = where.CompareVar(filterOperators[filter.Operator])
+
+		value := filter.Value
+		if filter.Operator == "like" {
+			value = "%" + value + "%"
+		}
+		args = append(args, value)
+	}
+	for _, sort := range listQuery.Sort {
+		builder = builder.OrderBy(sort.Column, sort.Descending)
+	}
+	return builder.Paginate(listQuery.PageSize, listQuery.Offset()).Query(), args
+}
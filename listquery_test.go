@@ -0,0 +1,60 @@
+package gyr_test
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+type listedUser struct {
+	Name      string `gyr_column:"name"`
+	Age       int    `gyr_column:"age"`
+	CreatedAt string `gyr_column:"created_at"`
+}
+
+func listSettings() []gyr.SettingsFunc[gyr.ListQuerySettings] {
+	return []gyr.SettingsFunc[gyr.ListQuerySettings]{
+		gyr.ListFilterable("name", "age"),
+		gyr.ListSortable("name", "created_at"),
+		gyr.ListPageSize(10, 50),
+	}
+}
+
+func TestApplyListQuery(t *testing.T) {
+	gyr.RegisterEntity[listedUser](gyr.EntityMetadata{Table: "users"})
+	values, _ := url.ParseQuery("filter[name]=like:kal&filter[age]=gte:18&sort=-created_at,name&page=3")
+	listQuery, err := gyr.ParseListQuery(values, listSettings()...)
+	if err != nil {
+		t.Logf("Unexpected error %v\n", err)
+		t.FailNow()
+	}
+
+	query, args := gyr.ApplyListQuery(gyr.NewQuery[listedUser](), listQuery)
+	expected := "select name, age, created_at from users where age >= ? and name like ? order by created_at desc, name limit 10 offset 20"
+	if query != expected || !slices.Equal(args, []any{"18", "%kal%"}) {
+		t.Logf("Expected %q %v. Received %q %v\n", expected, []any{"18", "%kal%"}, query, args)
+		t.FailNow()
+	}
+}
+
+func TestParseListQueryDefaults(t *testing.T) {
+	values, _ := url.ParseQuery("filter[name]=kalle")
+	listQuery, err := gyr.ParseListQuery(values, listSettings()...)
+	if err != nil || listQuery.Page != 1 || listQuery.PageSize != 10 || listQuery.Filters[0] != (gyr.Filter{Column: "name", Operator: "eq", Value: "kalle"}) {
+		t.Logf("Received %+v (%v)\n", listQuery, err)
+		t.FailNow()
+	}
+}
+
+func TestParseListQueryRejectsUnlistedColumns(t *testing.T) {
+	values, _ := url.ParseQuery("filter[password]=eq:x&sort=age&page=0&page_size=500")
+	_, err := gyr.ParseListQuery(values, listSettings()...)
+	var errs gyr.ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 4 {
+		t.Logf("Expected 4 validation errors. Received %v\n", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,108 @@
+package gyr
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+type LogSettings struct {
+	// Defaults to debug when the [Profile] has Debug, such as when GYR_DEBUG is set, and info otherwise.
+	Level  slog.Leveler
+	JSON   bool
+	Output io.Writer
+}
+
+func DefaultLogSettings() LogSettings {
+	level := slog.LevelInfo
+	if isGyrDebug() {
+		level = slog.LevelDebug
+	}
+	return LogSettings{
+		Level:  level,
+		Output: os.Stdout,
+	}
+}
+
+func LogLevel(level slog.Leveler) func(*LogSettings) {
+	return func(ls *LogSettings) {
+		ls.Level = level
+	}
+}
+
+func LogJSON() func(*LogSettings) {
+	return func(ls *LogSettings) {
+		ls.JSON = true
+	}
+}
+
+func LogOutput(output io.Writer) func(*LogSettings) {
+	return func(ls *LogSettings) {
+		ls.Output = output
+	}
+}
+
+var processLogger atomic.Pointer[slog.Logger]
+
+// Configure the logger used by gyr components that aren't given one of their own. It also becomes the
+// [slog.Default] logger so logs from the application and gyr end up in the same place and format.
+func ConfigureLogging(settings ...SettingsFunc[LogSettings]) *slog.Logger {
+	logSettings := DefaultLogSettings()
+	for _, setting := range settings {
+		setting(&logSettings)
+	}
+	logger := newLogger(logSettings)
+	processLogger.Store(logger)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// Use an existing logger as the logger for gyr components, without changing [slog.Default].
+func SetLogger(logger *slog.Logger) {
+	processLogger.Store(logger)
+}
+
+// The logger configured with [ConfigureLogging] or [SetLogger], or a text logger writing to stdout
+// if neither has been called.
+func Logger() *slog.Logger {
+	if logger := processLogger.Load(); logger != nil {
+		return logger
+	}
+	processLogger.CompareAndSwap(nil, newLogger(DefaultLogSettings()))
+	return processLogger.Load()
+}
+
+func newLogger(settings LogSettings) *slog.Logger {
+	handlerOptions := &slog.HandlerOptions{Level: settings.Level}
+	if settings.JSON {
+		return slog.New(slog.NewJSONHandler(settings.Output, handlerOptions))
+	}
+	return slog.New(slog.NewTextHandler(settings.Output, handlerOptions))
+}
+
+type logAttrsKey struct{}
+
+// Add attributes, as key-value pairs or [slog.Attr], that [LoggerFrom] includes in every log written
+// with ctx. The router adds the request method and path and the job queue the job ID and type.
+func WithLogAttrs(ctx context.Context, args ...any) context.Context {
+	existing, _ := ctx.Value(logAttrsKey{}).([]any)
+	attrs := make([]any, 0, len(existing)+len(args))
+	attrs = append(attrs, existing...)
+	attrs = append(attrs, args...)
+	return context.WithValue(ctx, logAttrsKey{}, attrs)
+}
+
+// The process logger with the attributes added to ctx with [WithLogAttrs].
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	logger := Logger()
+	if recording, isRecorded := ctx.Value(logCaptureKey{}).(*debugRecording); isRecorded {
+		logger = slog.New(&captureHandler{Handler: logger.Handler(), recording: recording})
+	}
+	attrs, _ := ctx.Value(logAttrsKey{}).([]any)
+	if len(attrs) == 0 {
+		return logger
+	}
+	return logger.With(attrs...)
+}
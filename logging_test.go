@@ -0,0 +1,48 @@
+package gyr_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	buffer := &bytes.Buffer{}
+	previous := gyr.Logger()
+	gyr.SetLogger(slog.New(slog.NewTextHandler(buffer, nil)))
+	t.Cleanup(func() { gyr.SetLogger(previous) })
+	return buffer
+}
+
+func TestLoggerFrom(t *testing.T) {
+	buffer := captureLogs(t)
+	ctx := gyr.WithLogAttrs(context.Background(), "user", 7)
+	ctx = gyr.WithLogAttrs(ctx, "tenant", "acme")
+	gyr.LoggerFrom(ctx).Info("hello")
+
+	if !strings.Contains(buffer.String(), "msg=hello user=7 tenant=acme") {
+		t.Logf("Expected attributes from context. Received %s\n", buffer.String())
+		t.FailNow()
+	}
+}
+
+func TestRouterLogsWithRequestAttributes(t *testing.T) {
+	buffer := captureLogs(t)
+	router := gyr.DefaultRouter()
+	router.Path("/logged").Get(func(ctx *gyr.Context) *gyr.Response {
+		ctx.Logger().Info("in handler")
+		return ctx.Response().NoContent()
+	})
+
+	request, _ := http.NewRequest(http.MethodGet, "/logged", nil)
+	sendRequest(router, request)
+	if !strings.Contains(buffer.String(), `msg="in handler" method=GET path=/logged`) {
+		t.Logf("Expected handler log with request attributes. Received %s\n", buffer.String())
+		t.FailNow()
+	}
+}
@@ -0,0 +1,40 @@
+package gyr
+
+import (
+	"strings"
+	"time"
+)
+
+// Retry-After sent with the 503 responses of [Router.MaintenanceMode].
+var MaintenanceRetryAfter = 5 * time.Minute
+
+type maintenance struct {
+	allowlist []string
+}
+
+// Respond with 503 Service Unavailable to every request except those to a path in the allowlist. Allowlisted
+// paths also allow everything below them, so "/admin" allows "/admin/users". Can be toggled while serving.
+func (router *Router) MaintenanceMode(enabled bool, allowlist ...string) {
+	if !enabled {
+		router.maintenance.Store(nil)
+		router.logger.Info("Maintenance mode disabled")
+		return
+	}
+	router.maintenance.Store(&maintenance{allowlist: allowlist})
+	router.logger.Info("Maintenance mode enabled", "allowlist", allowlist)
+}
+
+// Reports whether requests to path are currently rejected by maintenance mode.
+func (router *Router) inMaintenance(path string) bool {
+	state := router.maintenance.Load()
+	if state == nil {
+		return false
+	}
+	for _, allowed := range state.allowlist {
+		allowed = strings.TrimSuffix(allowed, "/")
+		if path == allowed || strings.HasPrefix(path, allowed+"/") {
+			return false
+		}
+	}
+	return true
+}
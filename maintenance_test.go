@@ -0,0 +1,39 @@
+package gyr_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	router := defaultTestRouter()
+	ok := func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Text("ok") }
+	router.Path("/items").Get(ok)
+	router.Path("/health").Get(ok)
+	router.Path("/admin/users").Get(ok)
+
+	status := func(path string) *http.Response {
+		request, _ := http.NewRequest(http.MethodGet, path, nil)
+		return sendRequest(router, request).Result()
+	}
+
+	router.MaintenanceMode(true, "/health", "/admin")
+	if response := status("/items"); response.StatusCode != http.StatusServiceUnavailable || response.Header.Get("Retry-After") != "300" {
+		t.Logf("Expected 503 with Retry-After during maintenance. Received %d %v\n", response.StatusCode, response.Header)
+		t.FailNow()
+	}
+	for _, path := range []string{"/health", "/admin/users"} {
+		if response := status(path); response.StatusCode != http.StatusOK {
+			t.Logf("Expected allowlisted %s to be served. Received %d\n", path, response.StatusCode)
+			t.FailNow()
+		}
+	}
+
+	router.MaintenanceMode(false)
+	if response := status("/items"); response.StatusCode != http.StatusOK {
+		t.Logf("Expected requests to be served after maintenance. Received %d\n", response.StatusCode)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,150 @@
+package gyr
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	gyr_map_tag = "gyr_map"
+)
+
+// Create a Dst with the fields of src copied into it. Fields are matched by name, or by the gyr_map tag on
+// either side naming the field on the other side. Nested structs, pointers, slices and maps are mapped
+// recursively, so a []UserEntity field can fill a []UserResponse field. Fields without a counterpart are left
+// alone, and `gyr_map:"-"` excludes a field.
+func Map[Dst any](src any) (Dst, error) {
+	var dst Dst
+	err := MapInto(&dst, src)
+	return dst, err
+}
+
+// Like [Map] but copies into the struct pointed to by dst, leaving fields without a counterpart unchanged.
+func MapInto(dst any, src any) error {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("can only map into a non-nil pointer, received %T", dst)
+	}
+	return mapValue(target.Elem(), reflect.ValueOf(src), "")
+}
+
+func mapValue(dst reflect.Value, src reflect.Value, path string) error {
+	if !src.IsValid() {
+		return nil
+	}
+	if src.Kind() == reflect.Pointer || src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			dst.SetZero()
+			return nil
+		}
+		return mapValue(dst, src.Elem(), path)
+	}
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return mapValue(dst.Elem(), src, path)
+	}
+
+	switch {
+	case src.Type().AssignableTo(dst.Type()) && !needsDeepMap(src.Type()):
+		dst.Set(src)
+	case src.Kind() == reflect.Struct && dst.Kind() == reflect.Struct:
+		return mapStruct(dst, src, path)
+	case src.Kind() == reflect.Slice && dst.Kind() == reflect.Slice:
+		if src.IsNil() {
+			dst.SetZero()
+			return nil
+		}
+		slice := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := mapValue(slice.Index(i), src.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+	case src.Kind() == reflect.Map && dst.Kind() == reflect.Map && src.Type().Key().ConvertibleTo(dst.Type().Key()):
+		if src.IsNil() {
+			dst.SetZero()
+			return nil
+		}
+		mapped := reflect.MakeMapWithSize(dst.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			value := reflect.New(dst.Type().Elem()).Elem()
+			if err := mapValue(value, iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key())); err != nil {
+				return err
+			}
+			mapped.SetMapIndex(iter.Key().Convert(dst.Type().Key()), value)
+		}
+		dst.Set(mapped)
+	case src.Type().ConvertibleTo(dst.Type()) && (src.Kind() == dst.Kind() || isNumericKind(src.Kind()) && isNumericKind(dst.Kind())):
+		dst.Set(src.Convert(dst.Type()))
+	default:
+		return fmt.Errorf("can not map %s from %s to %s", path, src.Type(), dst.Type())
+	}
+	return nil
+}
+
+// Structs, slices and maps of structs are copied field by field even when the types match, so the
+// result doesn't share slices and maps with the source.
+func needsDeepMap(valueType reflect.Type) bool {
+	switch valueType.Kind() {
+	case reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// Numbers can be mapped between sizes, but not to strings since Go converts integers to strings as runes.
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func mapStruct(dst reflect.Value, src reflect.Value, path string) error {
+	if dst.Type() == reflect.TypeFor[time.Time]() || src.Type() == reflect.TypeFor[time.Time]() {
+		return fmt.Errorf("can not map %s from %s to %s", path, src.Type(), dst.Type())
+	}
+	sourceFields := mappedFields(src.Type())
+	for name, dstIndex := range mappedFields(dst.Type()) {
+		srcIndex, exists := sourceFields[name]
+		if !exists {
+			continue
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		if err := mapValue(dst.Field(dstIndex), src.Field(srcIndex), fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The exported fields of structType by the name they are matched with.
+func mappedFields(structType reflect.Type) map[string]int {
+	fields := make(map[string]int)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, hasTag := field.Tag.Lookup(gyr_map_tag); hasTag {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		fields[name] = i
+	}
+	return fields
+}
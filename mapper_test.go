@@ -0,0 +1,95 @@
+package gyr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+type addressEntity struct {
+	Street string
+	City   string
+}
+
+type orderEntity struct {
+	ID    int
+	Total float64
+}
+
+type customerEntity struct {
+	ID           int
+	Name         string `gyr_map:"FullName"`
+	PasswordHash string
+	Created      time.Time
+	Address      *addressEntity
+	Orders       []orderEntity
+	Tags         map[string]int32
+}
+
+type addressResponse struct {
+	City string
+}
+
+type orderResponse struct {
+	ID    int64
+	Total float64
+}
+
+type customerResponse struct {
+	ID           int64
+	FullName     string
+	PasswordHash string `gyr_map:"-"`
+	Created      time.Time
+	Address      addressResponse
+	Orders       []orderResponse
+	Tags         map[string]int
+}
+
+func TestMap(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entity := customerEntity{
+		ID:           7,
+		Name:         "Kalle Karlsson",
+		PasswordHash: "secret",
+		Created:      created,
+		Address:      &addressEntity{Street: "Storgatan 1", City: "Umeå"},
+		Orders:       []orderEntity{{ID: 1, Total: 9.5}, {ID: 2, Total: 20}},
+		Tags:         map[string]int32{"vip": 1},
+	}
+
+	response, err := gyr.Map[customerResponse](&entity)
+	if err != nil {
+		t.Logf("Unexpected error %v\n", err)
+		t.FailNow()
+	}
+	if response.ID != 7 || response.FullName != "Kalle Karlsson" || response.PasswordHash != "" || !response.Created.Equal(created) {
+		t.Logf("Unexpected fields %+v\n", response)
+		t.FailNow()
+	}
+	if response.Address.City != "Umeå" || len(response.Orders) != 2 || response.Orders[1] != (orderResponse{ID: 2, Total: 20}) || response.Tags["vip"] != 1 {
+		t.Logf("Unexpected nested fields %+v\n", response)
+		t.FailNow()
+	}
+
+	back, err := gyr.Map[customerEntity](response)
+	if err != nil || back.Name != "Kalle Karlsson" || back.Address.City != "Umeå" {
+		t.Logf("Expected mapping back using the tag. Received %+v (%v)\n", back, err)
+		t.FailNow()
+	}
+}
+
+func TestMapIncompatibleField(t *testing.T) {
+	type source struct{ ID string }
+	type target struct{ ID []int }
+	if _, err := gyr.Map[target](source{ID: "x"}); err == nil {
+		t.Log("Expected error for incompatible field types")
+		t.FailNow()
+	}
+
+	type numeric struct{ ID int }
+	if _, err := gyr.Map[source](numeric{ID: 65}); err == nil {
+		t.Log("Expected error mapping a number to a string")
+		t.FailNow()
+	}
+}
@@ -2,29 +2,69 @@ package gyr
 
 import (
 	"bufio"
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type MigratorSettings struct {
 	Directory string
 	Context   context.Context
-	LogWriter *os.File
+	// Write logs to a logger of its own instead of the process logger from [Logger].
+	LogWriter io.Writer
+	// Write logs as JSON instead of text. Ignored when Logger is set.
+	JSONLogs bool
+	// Used instead of creating a logger writing to LogWriter.
+	Logger *slog.Logger
+	// Detected from the driver of the connection when nil.
+	Dialect *Dialect
+	// Directory searched by [Migrator.Seed]. Files directly in the directory are seeded in every environment,
+	// files in a subdirectory named after the environment only in that environment.
+	SeedDirectory string
+	// Defaults to the APP_ENV environment variable, or "development" if it is not set.
+	SeedEnvironment string
+	// Run migrations that are older than the last applied version but missing from the history,
+	// instead of failing with [ErrOutOfOrderMigration].
+	AllowOutOfOrder bool
+	// Called before each migration is run. Returning an error aborts the migration run.
+	BeforeEach func(MigrationEvent) error
+	// Called after each successful migration. Returning an error aborts the migration run.
+	AfterEach func(MigrationEvent) error
+	// Called when a migration fails.
+	OnError func(MigrationEvent)
+	// Tables of the SQL stores created before migrating, see [MigrateStores].
+	Stores []StoreTable
+}
+
+// Passed to the migration hooks. Duration and Err are not set for BeforeEach.
+type MigrationEvent struct {
+	Version  string
+	Path     string
+	Duration time.Duration
+	Err      error
 }
 
 func DefaultMigratorSettings() MigratorSettings {
+	environment, isSet := os.LookupEnv("APP_ENV")
+	if !isSet {
+		environment = "development"
+	}
 	return MigratorSettings{
-		Context:   context.Background(),
-		Directory: "migrations",
-		LogWriter: os.Stdout,
+		Context:         context.Background(),
+		Directory:       "migrations",
+		SeedDirectory:   "seeds",
+		SeedEnvironment: environment,
 	}
 }
 
@@ -40,34 +80,134 @@ func MigrationContext(context context.Context) func(*MigratorSettings) {
 	}
 }
 
-func MigrationLogOutput(file *os.File) func(*MigratorSettings) {
+func MigrationLogOutput(writer io.Writer) func(*MigratorSettings) {
+	return func(ms *MigratorSettings) {
+		ms.LogWriter = writer
+	}
+}
+
+func MigrationJSONLogs() func(*MigratorSettings) {
+	return func(ms *MigratorSettings) {
+		ms.JSONLogs = true
+	}
+}
+
+func MigrationLogger(logger *slog.Logger) func(*MigratorSettings) {
+	return func(ms *MigratorSettings) {
+		ms.Logger = logger
+	}
+}
+
+// A migration written in Go, for changes that are impractical to express in pure SQL such as data backfills.
+// It runs inside the same transaction as the SQL migrations.
+type MigrationFunc func(context.Context, *sql.Tx) error
+
+type migration struct {
+	version string
+	path    string
+	fn      MigrationFunc
+}
+
+func SeedDirectory(dir string) func(*MigratorSettings) {
+	return func(ms *MigratorSettings) {
+		ms.SeedDirectory = dir
+	}
+}
+
+func SeedEnvironment(environment string) func(*MigratorSettings) {
+	return func(ms *MigratorSettings) {
+		ms.SeedEnvironment = environment
+	}
+}
+
+func MigrationAllowOutOfOrder(allow bool) func(*MigratorSettings) {
+	return func(ms *MigratorSettings) {
+		ms.AllowOutOfOrder = allow
+	}
+}
+
+func MigrationBeforeEach(hook func(MigrationEvent) error) func(*MigratorSettings) {
 	return func(ms *MigratorSettings) {
-		ms.LogWriter = file
+		ms.BeforeEach = hook
 	}
 }
 
+func MigrationAfterEach(hook func(MigrationEvent) error) func(*MigratorSettings) {
+	return func(ms *MigratorSettings) {
+		ms.AfterEach = hook
+	}
+}
+
+func MigrationOnError(hook func(MigrationEvent)) func(*MigratorSettings) {
+	return func(ms *MigratorSettings) {
+		ms.OnError = hook
+	}
+}
+
+func MigrationDialect(dialect Dialect) func(*MigratorSettings) {
+	return func(ms *MigratorSettings) {
+		ms.Dialect = &dialect
+	}
+}
+
+// Returned by [Migrator.Migrate] when a migration older than the last applied version has never been run,
+// typically because it was merged after a newer one had already been applied.
+var ErrOutOfOrderMigration = errors.New("unapplied migration older than the last applied version")
+
+// The database handle used by the migrator. Satisfied by *sql.DB, *sql.Conn and *sql.Tx as well as wrappers
+// around them. When the handle can't begin transactions, which is the case for *sql.Tx, migrations run
+// directly in it and committing is left to the caller.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type transactionBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
 type Migrator struct {
-	connection  *sql.DB
-	version     string
-	path        string
-	logger      *slog.Logger
-	LastVersion string
-	Settings    MigratorSettings
+	connection DBTX
+	applied    []string
+	// Every version up to the watermark counts as applied, see [pendingMigrations].
+	watermark    string
+	version      string
+	logger       *slog.Logger
+	goMigrations []migration
+	failed       *MigrationRecord
+	LastVersion  string
+	Settings     MigratorSettings
 }
 
-func NewMigrator(connection *sql.DB, settings ...SettingsFunc[MigratorSettings]) *Migrator {
+func NewMigrator(connection DBTX, settings ...SettingsFunc[MigratorSettings]) *Migrator {
 	migratorSettings := DefaultMigratorSettings()
 	for _, setting := range settings {
 		setting(&migratorSettings)
 	}
 
-	logLevel := slog.LevelInfo
-	if isGyrDebug() {
-		logLevel = slog.LevelDebug
+	logger := migratorSettings.Logger
+	if logger == nil && migratorSettings.LogWriter == nil {
+		logger = LoggerFrom(migratorSettings.Context).With("component", "migrator")
+	} else if logger == nil {
+		logLevel := slog.LevelInfo
+		if isGyrDebug() {
+			logLevel = slog.LevelDebug
+		}
+		handlerOptions := &slog.HandlerOptions{Level: logLevel}
+		if migratorSettings.JSONLogs {
+			logger = slog.New(slog.NewJSONHandler(migratorSettings.LogWriter, handlerOptions))
+		} else {
+			logger = slog.New(slog.NewTextHandler(migratorSettings.LogWriter, handlerOptions))
+		}
+	}
+
+	if migratorSettings.Dialect == nil {
+		dialect := DetectDialect(connection)
+		migratorSettings.Dialect = &dialect
 	}
-	logger := slog.New(slog.NewTextHandler(migratorSettings.LogWriter, &slog.HandlerOptions{Level: logLevel}))
 
-	logger.Info("Initializing Gyr Database Migrator", "directory", migratorSettings.Directory)
+	logger.Info("Initializing Gyr Database Migrator", "directory", migratorSettings.Directory, "dialect", migratorSettings.Dialect.Name)
 	return &Migrator{
 		connection: connection,
 		logger:     logger,
@@ -75,17 +215,31 @@ func NewMigrator(connection *sql.DB, settings ...SettingsFunc[MigratorSettings])
 	}
 }
 
+// Register a Go migration. The name follows the same convention as SQL files, e.g. "0.0.7_backfill",
+// and the migration is run in version order together with the SQL files.
+func (mig *Migrator) Register(name string, fn MigrationFunc) {
+	mig.goMigrations = append(mig.goMigrations, migration{
+		version: migrationVersionFromFilepath(name),
+		path:    name,
+		fn:      fn,
+	})
+}
+
 func (mig *Migrator) Migrate() error {
 	err := mig.createMigrationTable()
 	if err != nil {
 		return err
 	}
+	err = mig.createStoreTables()
+	if err != nil {
+		return err
+	}
 	err = mig.getMigrationVersion()
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+	if err != nil {
 		return err
 	}
 
-	transaction, err := mig.connection.BeginTx(mig.Settings.Context, nil)
+	transaction, err := mig.beginTransaction()
 	if err != nil {
 		return err
 	}
@@ -93,63 +247,272 @@ func (mig *Migrator) Migrate() error {
 	err = mig.executeMigrations(transaction)
 	if err != nil {
 		mig.logger.Error("Error in migration execution", "error", err)
+		mig.rollbackTransaction(transaction)
+		mig.recordFailure()
 		return err
 	}
 
-	err = mig.setMigrationVersion()
+	err = mig.commitTransaction(transaction)
 	if err != nil {
 		return err
 	}
-	return transaction.Commit()
+	if mig.version != "" {
+		mig.LastVersion = mig.version
+		mig.logger.Info("Migrated to version", "version", mig.LastVersion)
+	}
+	return nil
+}
+
+// Record of a single migration run as stored in gyr_migrator_version_history.
+type MigrationRecord struct {
+	Version   string
+	Path      string
+	AppliedAt time.Time
+	Duration  time.Duration
+	Success   bool
+	// SHA-256 of the SQL file. Empty for Go migrations.
+	Checksum string
 }
 
 func (mig *Migrator) createMigrationTable() error {
 	mig.logger.Debug("Creating gyr_migrator_version_history table")
-	const query = "create table if not exists gyr_migrator_version_history (version varchar(10), path varchar(255));"
-	_, err := mig.connection.ExecContext(mig.Settings.Context, query)
-	return err
+	_, err := mig.connection.ExecContext(mig.Settings.Context, mig.Settings.Dialect.HistoryTableDDL)
+	if err != nil {
+		return err
+	}
+	return mig.upgradeMigrationTable()
 }
 
-func (mig *Migrator) getMigrationVersion() error {
-	const query = "select version from gyr_migrator_version_history order by version desc"
-	row := mig.connection.QueryRowContext(mig.Settings.Context, query)
-	err := row.Scan(&mig.LastVersion)
+// Add the columns missing from history tables created by earlier versions of Gyr.
+func (mig *Migrator) upgradeMigrationTable() error {
+	columns := [][2]string{
+		{"applied_at", "timestamp null"},
+		{"duration_ms", "bigint"},
+		{"success", "boolean"},
+		{"checksum", "varchar(64)"},
+	}
+	for _, column := range columns {
+		rows, err := mig.connection.QueryContext(mig.Settings.Context, "select "+column[0]+" from gyr_migrator_version_history where 1 = 0")
+		if err == nil {
+			rows.Close()
+			continue
+		}
 
-	mig.logger.Info("Detected migration version", "version", mig.LastVersion)
-	return err
+		mig.logger.Info("Adding column to gyr_migrator_version_history", "column", column[0])
+		query := "alter table gyr_migrator_version_history add column " + column[0] + " " + column[1]
+		if _, err := mig.connection.ExecContext(mig.Settings.Context, query); err != nil {
+			return err
+		}
+	}
+	return mig.widenVersionColumn()
 }
 
-func (mig *Migrator) setMigrationVersion() error {
-	if mig.path == "" || mig.version == "" {
+// Widen the version column of history tables created when it was varchar(10), which timestamp versions overflow.
+func (mig *Migrator) widenVersionColumn() error {
+	dialect := mig.Settings.Dialect
+	if dialect.VersionLengthQuery == "" || dialect.WidenVersionColumn == "" {
 		return nil
 	}
-	const query = "insert into gyr_migrator_version_history (version, path) values (?, ?)"
-	_, err := mig.connection.ExecContext(mig.Settings.Context, query, mig.version, mig.path)
+	var length sql.NullInt64
+	err := mig.connection.QueryRowContext(mig.Settings.Context, dialect.VersionLengthQuery).Scan(&length)
+	if errors.Is(err, sql.ErrNoRows) || err == nil && (!length.Valid || length.Int64 >= 50) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	mig.logger.Info("Widening version column of gyr_migrator_version_history", "length", length.Int64)
+	_, err = mig.connection.ExecContext(mig.Settings.Context, dialect.WidenVersionColumn)
+	return err
+}
+
+// Load the applied versions and pick the most recent one. Done in Go since versions don't sort correctly as strings.
+func (mig *Migrator) getMigrationVersion() error {
+	applied, watermark, err := mig.appliedVersions()
 	if err != nil {
 		return err
 	}
-	mig.LastVersion = mig.version
-	mig.logger.Info("Migrated to version", "version", mig.LastVersion)
+	mig.applied = applied
+	mig.watermark = watermark
+	if len(applied) > 0 {
+		mig.LastVersion = slices.MaxFunc(applied, compareVersions)
+	}
+
+	mig.logger.Info("Detected migration version", "version", mig.LastVersion)
 	return nil
 }
 
+// Get the versions of the successful migrations and the most recent version of the rows written before the
+// audit columns existed, which is returned as the watermark.
+func (mig *Migrator) appliedVersions() ([]string, string, error) {
+	const query = "select version, success from gyr_migrator_version_history where success is null or success = ?"
+	rows, err := mig.connection.QueryContext(mig.Settings.Context, mig.Settings.Dialect.Rebind(query), true)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	versions := make([]string, 0)
+	watermark := ""
+	for rows.Next() {
+		var version string
+		var success sql.NullBool
+		if err := rows.Scan(&version, &success); err != nil {
+			return nil, "", err
+		}
+		versions = append(versions, version)
+		if !success.Valid && (watermark == "" || compareVersions(version, watermark) > 0) {
+			watermark = version
+		}
+	}
+	return versions, watermark, rows.Err()
+}
+
+// Get every recorded migration run, oldest first.
+func (mig *Migrator) History() ([]MigrationRecord, error) {
+	const query = "select version, path, applied_at, duration_ms, success, checksum from gyr_migrator_version_history order by applied_at"
+	rows, err := mig.connection.QueryContext(mig.Settings.Context, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]MigrationRecord, 0)
+	for rows.Next() {
+		var record MigrationRecord
+		var appliedAt sql.NullTime
+		var durationMs sql.NullInt64
+		var success sql.NullBool
+		var checksum sql.NullString
+		if err := rows.Scan(&record.Version, &record.Path, &appliedAt, &durationMs, &success, &checksum); err != nil {
+			return nil, err
+		}
+		record.AppliedAt = appliedAt.Time
+		record.Duration = time.Duration(durationMs.Int64) * time.Millisecond
+		// Rows written before the audit columns existed were only ever written on success.
+		record.Success = !success.Valid || success.Bool
+		record.Checksum = checksum.String
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+func (mig *Migrator) recordMigration(executor sqlExecutor, record MigrationRecord) error {
+	const query = "insert into gyr_migrator_version_history (version, path, applied_at, duration_ms, success, checksum) values (?, ?, ?, ?, ?, ?)"
+	_, err := executor.ExecContext(mig.Settings.Context, mig.Settings.Dialect.Rebind(query), record.Version, record.Path, record.AppliedAt, record.Duration.Milliseconds(), record.Success, record.Checksum)
+	return err
+}
+
+// Failures are recorded outside of the rolled back transaction so they survive it.
+func (mig *Migrator) recordFailure() {
+	if mig.failed == nil {
+		return
+	}
+	if err := mig.recordMigration(mig.connection, *mig.failed); err != nil {
+		mig.logger.Error("Failed to record failed migration", "path", mig.failed.Path, "error", err)
+	}
+}
+
+type sqlExecutor interface {
+	ExecContext(context.Context, string, ...any) (sql.Result, error)
+}
+
 func (mig *Migrator) executeMigrations(transaction *sql.Tx) error {
-	paths := getSqlFilenames(mig.Settings.Directory)
-	paths = removeAlreadyMigratedPaths(paths, mig.LastVersion)
-	mig.logger.Info("Running migrations", "migrations", len(paths))
+	// Only a failure of this run is recorded.
+	mig.failed = nil
+	migrations := collectMigrations(getSqlFilenames(mig.Settings.Directory), mig.goMigrations)
+	migrations, outOfOrder := pendingMigrations(migrations, mig.applied, mig.watermark, mig.LastVersion)
+	if len(outOfOrder) > 0 {
+		paths := make([]string, len(outOfOrder))
+		for i, m := range outOfOrder {
+			paths[i] = m.path
+		}
+		if !mig.Settings.AllowOutOfOrder {
+			return fmt.Errorf("%w: %s", ErrOutOfOrderMigration, strings.Join(paths, ", "))
+		}
+		mig.logger.Warn("Running out of order migrations", "paths", paths)
+	}
+	mig.logger.Info("Running migrations", "migrations", len(migrations))
 
-	for _, path := range paths {
-		err := mig.executeQueriesInFile(path, transaction)
+	for _, m := range migrations {
+		if mig.Settings.BeforeEach != nil {
+			if err := mig.Settings.BeforeEach(MigrationEvent{Version: m.version, Path: m.path}); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		start := time.Now()
+		if m.fn != nil {
+			mig.logger.Info("Running Go migration", "name", m.path)
+			err = m.fn(mig.Settings.Context, transaction)
+		} else {
+			err = mig.executeQueriesInFile(m.path, transaction)
+		}
+		record := MigrationRecord{
+			Version: m.version,
+			// Stored with forward slashes so the history is the same regardless of operating system.
+			Path:      filepath.ToSlash(m.path),
+			AppliedAt: start,
+			Duration:  time.Since(start),
+			Success:   err == nil,
+		}
+		if m.fn == nil {
+			record.Checksum, _ = fileChecksum(m.path)
+		}
+		event := MigrationEvent{Version: m.version, Path: m.path, Duration: record.Duration, Err: err}
 		if err != nil {
+			mig.failed = &record
+			if mig.Settings.OnError != nil {
+				mig.Settings.OnError(event)
+			}
 			return err
 		}
+		if err := mig.recordMigration(transaction, record); err != nil {
+			return err
+		}
+		if mig.Settings.AfterEach != nil {
+			if err := mig.Settings.AfterEach(event); err != nil {
+				return err
+			}
+		}
 
-		mig.path = path
-		mig.version = migrationVersionFromFilepath(path)
+		mig.version = m.version
 	}
 	return nil
 }
 
+// Merge SQL files and Go migrations into one list sorted by file name, which starts with the version.
+func collectMigrations(paths []string, goMigrations []migration) []migration {
+	migrations := make([]migration, 0, len(paths)+len(goMigrations))
+	for _, path := range paths {
+		migrations = append(migrations, migration{version: migrationVersionFromFilepath(path), path: path})
+	}
+	migrations = append(migrations, goMigrations...)
+	slices.SortStableFunc(migrations, func(a migration, b migration) int {
+		if order := compareVersions(a.version, b.version); order != 0 {
+			return order
+		}
+		return strings.Compare(filepath.Base(a.path), filepath.Base(b.path))
+	})
+	return migrations
+}
+
+// Remove the migrations that have been applied. Versions up to watermark count as applied, since earlier
+// versions of Gyr wrote a single row per run holding only the version it ended at. The second return value
+// contains the remaining migrations that are older than the most recent applied version.
+func pendingMigrations(migrations []migration, applied []string, watermark string, mostRecentVersion string) ([]migration, []migration) {
+	pending := slices.DeleteFunc(migrations, func(m migration) bool {
+		return slices.Contains(applied, m.version) || watermark != "" && compareVersions(m.version, watermark) <= 0
+	})
+	outOfOrder := make([]migration, 0)
+	for _, m := range pending {
+		if compareVersions(m.version, mostRecentVersion) <= 0 {
+			outOfOrder = append(outOfOrder, m)
+		}
+	}
+	return pending, outOfOrder
+}
+
 func (mig *Migrator) executeQueriesInFile(path string, transaction *sql.Tx) error {
 	file, err := os.Open(path)
 	if err != nil {
@@ -179,34 +542,87 @@ func (mig *Migrator) executeQueriesInFile(path string, transaction *sql.Tx) erro
 
 func removeAlreadyMigratedPaths(paths []string, mostRecentVersion string) []string {
 	return slices.DeleteFunc(paths, func(path string) bool {
-		return strings.Compare(migrationVersionFromFilepath(path), mostRecentVersion) <= 0
+		return compareVersions(migrationVersionFromFilepath(path), mostRecentVersion) <= 0
 	})
 }
 
 func getSqlFilenames(directory string) []string {
 	sqlFiles := make([]string, 0)
 	filepath.WalkDir(directory, func(path string, d fs.DirEntry, err error) error {
-		if !d.IsDir() && strings.HasSuffix(d.Name(), ".sql") {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".sql") && !strings.HasSuffix(d.Name(), downMigrationSuffix) {
 			sqlFiles = append(sqlFiles, path)
 		}
 		return nil
 	})
 	slices.SortFunc(sqlFiles, func(a string, b string) int {
-		fileNameA := a[strings.LastIndex(a, "/")+1:]
-		fileNameB := b[strings.LastIndex(b, "/")+1:]
-		return strings.Compare(fileNameA, fileNameB)
+		if order := compareVersions(migrationVersionFromFilepath(a), migrationVersionFromFilepath(b)); order != 0 {
+			return order
+		}
+		return strings.Compare(filepath.Base(a), filepath.Base(b))
 	})
 
 	return sqlFiles
 }
 
+// Begin a transaction on the connection, or use the connection itself if it already is a transaction.
+func (mig *Migrator) beginTransaction() (*sql.Tx, error) {
+	if tx, isTx := mig.connection.(*sql.Tx); isTx {
+		return tx, nil
+	}
+	beginner, canBegin := mig.connection.(transactionBeginner)
+	if !canBegin {
+		return nil, errors.New("connection can not begin transactions")
+	}
+	return beginner.BeginTx(mig.Settings.Context, nil)
+}
+
+// Transactions passed in as the connection are owned by the caller and are not committed or rolled back.
+func (mig *Migrator) ownsTransaction(transaction *sql.Tx) bool {
+	tx, isTx := mig.connection.(*sql.Tx)
+	return !isTx || tx != transaction
+}
+
+func (mig *Migrator) commitTransaction(transaction *sql.Tx) error {
+	if !mig.ownsTransaction(transaction) {
+		return nil
+	}
+	return transaction.Commit()
+}
+
 func (mig *Migrator) rollbackTransaction(transaction *sql.Tx) {
+	if !mig.ownsTransaction(transaction) {
+		return
+	}
 	if err := transaction.Rollback(); !errors.Is(err, sql.ErrTxDone) && err != nil {
 		mig.logger.Error("Transaction rollback failed", "error", err)
 	}
 }
 
 func migrationVersionFromFilepath(path string) string {
-	filename := path[strings.LastIndex(path, "/")+1:]
+	filename := filepath.Base(path)
 	return strings.Split(filename, "_")[0]
 }
+
+// Compare two migration versions part by part, so that "0.0.10" comes after "0.0.2". Timestamp prefixes
+// such as "20240501120000" are compared as a single number. Parts that aren't numbers are compared as strings.
+func compareVersions(a string, b string) int {
+	partsA := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	partsB := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		numberA, errA := strconv.ParseUint(partsA[i], 10, 64)
+		numberB, errB := strconv.ParseUint(partsB[i], 10, 64)
+		var order int
+		if errA == nil && errB == nil {
+			order = cmp.Compare(numberA, numberB)
+		} else {
+			order = strings.Compare(partsA[i], partsB[i])
+		}
+		if order != 0 {
+			return order
+		}
+	}
+	return cmp.Compare(len(partsA), len(partsB))
+}
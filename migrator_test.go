@@ -1,6 +1,18 @@
 package gyr
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
 
 func TestRemoveAlreadyMigratedPaths(t *testing.T) {
 	paths := []string{"0.0.1_init.sql", "0.0.3_insert.sql", "0.0.2_alter.sql"}
@@ -11,3 +23,341 @@ func TestRemoveAlreadyMigratedPaths(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestCollectMigrationsInterleavesGoMigrations(t *testing.T) {
+	paths := []string{"migrations/0.0.1_init.sql", "migrations/0.0.3_insert.sql"}
+	goMigrations := []migration{{version: "0.0.2", path: "0.0.2_backfill", fn: func(ctx context.Context, tx *sql.Tx) error { return nil }}}
+	migrations := collectMigrations(paths, goMigrations)
+	if len(migrations) != 3 {
+		t.Logf("Expected 3 migrations. Received %d\n", len(migrations))
+		t.FailNow()
+	}
+	if migrations[1].path != "0.0.2_backfill" || migrations[1].fn == nil {
+		t.Logf("Expected Go migration in the middle. Received %+v\n", migrations)
+		t.FailNow()
+	}
+}
+
+func TestPendingMigrationsDetectsOutOfOrder(t *testing.T) {
+	migrations := collectMigrations([]string{"0.0.1_init.sql", "0.0.2_late.sql", "0.0.3_insert.sql", "0.0.4_new.sql"}, nil)
+	pending, outOfOrder := pendingMigrations(migrations, []string{"0.0.1", "0.0.3"}, "", "0.0.3")
+	if len(pending) != 2 || pending[0].path != "0.0.2_late.sql" || pending[1].path != "0.0.4_new.sql" {
+		t.Logf("pending contained %+v\n", pending)
+		t.FailNow()
+	}
+	if len(outOfOrder) != 1 || outOfOrder[0].path != "0.0.2_late.sql" {
+		t.Logf("outOfOrder contained %+v\n", outOfOrder)
+		t.FailNow()
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"0.0.2", "0.0.10", -1},
+		{"0.1.0", "0.0.10", 1},
+		{"1.0", "1.0.0", -1},
+		{"20240501120000", "20240501120000", 0},
+		{"20240501120000", "20231231235959", 1},
+	}
+	for _, c := range cases {
+		if received := compareVersions(c.a, c.b); received != c.expected {
+			t.Logf("compareVersions(%s, %s): Expected %d. Received %d\n", c.a, c.b, c.expected, received)
+			t.Fail()
+		}
+	}
+}
+
+func TestCollectMigrationsSortsVersionsNumerically(t *testing.T) {
+	migrations := collectMigrations([]string{"0.0.10_ten.sql", "0.0.2_two.sql", "0.0.1_one.sql"}, nil)
+	if migrations[0].version != "0.0.1" || migrations[1].version != "0.0.2" || migrations[2].version != "0.0.10" {
+		t.Logf("Received %+v\n", migrations)
+		t.FailNow()
+	}
+}
+
+func TestMigrationVersionFromFilepath(t *testing.T) {
+	paths := getSqlFilenames(filepath.Join("test_files", "migrations"))
+	expected := []string{"0.0.1", "0.0.2"}
+	if len(paths) != len(expected) {
+		t.Logf("Received %+v\n", paths)
+		t.FailNow()
+	}
+	for i, path := range paths {
+		if version := migrationVersionFromFilepath(path); version != expected[i] {
+			t.Logf("Expected %s. Received %s\n", expected[i], version)
+			t.FailNow()
+		}
+	}
+}
+
+func TestGetSqlFilenamesMissingDirectory(t *testing.T) {
+	if paths := getSqlFilenames(filepath.Join("test_files", "does-not-exist")); len(paths) != 0 {
+		t.Logf("Received %+v\n", paths)
+		t.FailNow()
+	}
+}
+
+func TestMigrationHooks(t *testing.T) {
+	before := make([]string, 0)
+	after := make([]string, 0)
+	migrator := NewMigrator(openFakeDB(),
+		MigrationDirectory(filepath.Join("test_files", "migrations")),
+		MigrationLogOutput(os.Stderr),
+		MigrationBeforeEach(func(event MigrationEvent) error {
+			before = append(before, event.Version)
+			return nil
+		}),
+		MigrationAfterEach(func(event MigrationEvent) error {
+			after = append(after, event.Version)
+			return nil
+		}),
+	)
+	if err := migrator.Migrate(); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(before) != 2 || len(after) != 2 || after[1] != "0.0.2" {
+		t.Logf("before: %+v after: %+v\n", before, after)
+		t.FailNow()
+	}
+	if migrator.LastVersion != "0.0.2" {
+		t.Logf("Expected LastVersion 0.0.2. Received %s\n", migrator.LastVersion)
+		t.FailNow()
+	}
+}
+
+func TestMigrationHookAborts(t *testing.T) {
+	var failed MigrationEvent
+	abort := errors.New("abort")
+	migrator := NewMigrator(openFakeDB(),
+		MigrationDirectory(filepath.Join("test_files", "migrations")),
+		MigrationLogOutput(os.Stderr),
+		MigrationOnError(func(event MigrationEvent) {
+			failed = event
+		}),
+	)
+	migrator.Register("0.0.3_fails", func(ctx context.Context, tx *sql.Tx) error {
+		return abort
+	})
+	if err := migrator.Migrate(); !errors.Is(err, abort) {
+		t.Logf("Expected abort error. Received %v\n", err)
+		t.FailNow()
+	}
+	if failed.Version != "0.0.3" || !errors.Is(failed.Err, abort) {
+		t.Logf("Received %+v\n", failed)
+		t.FailNow()
+	}
+}
+
+func TestMigratorJSONLogs(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	NewMigrator(openFakeDB(), MigrationLogOutput(buffer), MigrationJSONLogs())
+	var line map[string]any
+	if err := json.Unmarshal(buffer.Bytes(), &line); err != nil {
+		t.Logf("Expected JSON log line. Received %s\n", buffer.String())
+		t.FailNow()
+	}
+	if line["msg"] != "Initializing Gyr Database Migrator" {
+		t.Logf("Received %+v\n", line)
+		t.FailNow()
+	}
+}
+
+func TestMigrateInsideOuterTransaction(t *testing.T) {
+	db := openFakeDB()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	defer tx.Rollback()
+
+	migrator := NewMigrator(tx, MigrationDirectory(filepath.Join("test_files", "migrations")), MigrationLogOutput(os.Stderr))
+	if err := migrator.Migrate(); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := tx.Commit(); err != nil {
+		t.Logf("Expected the outer transaction to still be open. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestMigrationRecordsAuditColumns(t *testing.T) {
+	migrator := NewMigrator(openFakeDB(), MigrationDirectory(filepath.Join("test_files", "migrations")), MigrationLogOutput(os.Stderr))
+	if err := migrator.Migrate(); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	records := testDriver.executedWith("insert into gyr_migrator_version_history")
+	if len(records) != 2 {
+		t.Logf("Expected 2 recorded migrations. Received %+v\n", records)
+		t.FailNow()
+	}
+	for i, expected := range []string{"test_files/migrations/0.0.1_init.sql", "test_files/migrations/nested/0.0.2_age.sql"} {
+		record := records[i]
+		appliedAt, _ := record[2].(time.Time)
+		durationMs, _ := record[3].(int64)
+		checksum, _ := record[5].(string)
+		if record[1] != expected || appliedAt.IsZero() || durationMs < 0 || record[4] != true || len(checksum) != 64 {
+			t.Logf("Received %+v for %s\n", record, expected)
+			t.FailNow()
+		}
+	}
+}
+
+func TestMigrationRecordsFailure(t *testing.T) {
+	migrator := NewMigrator(openFakeDB(), MigrationDirectory(filepath.Join("test_files", "migrations")), MigrationLogOutput(os.Stderr))
+	migrator.Register("0.0.3_fails", func(ctx context.Context, tx *sql.Tx) error {
+		return errors.New("abort")
+	})
+	if err := migrator.Migrate(); err == nil {
+		t.Log("Expected the migration to fail")
+		t.FailNow()
+	}
+	records := testDriver.executedWith("insert into gyr_migrator_version_history")
+	failure := records[len(records)-1]
+	if failure[0] != "0.0.3" || failure[1] != "0.0.3_fails" || failure[4] != false || failure[5] != "" {
+		t.Logf("Expected a failure row for 0.0.3. Received %+v\n", records)
+		t.FailNow()
+	}
+}
+
+func TestMigrationHistory(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	migrator := NewMigrator(openFakeDB(), MigrationLogOutput(os.Stderr))
+	testDriver.respond("select version, path, applied_at",
+		// Written before the audit columns existed.
+		[]driver.Value{"0.0.1", "migrations/0.0.1_init.sql", nil, nil, nil, nil},
+		[]driver.Value{"0.0.2", "migrations/0.0.2_age.sql", appliedAt, int64(1500), true, "abc"},
+		[]driver.Value{"0.0.3", "0.0.3_backfill", appliedAt, int64(20), false, nil},
+	)
+	history, err := migrator.History()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	expected := []MigrationRecord{
+		{Version: "0.0.1", Path: "migrations/0.0.1_init.sql", Success: true},
+		{Version: "0.0.2", Path: "migrations/0.0.2_age.sql", AppliedAt: appliedAt, Duration: 1500 * time.Millisecond, Success: true, Checksum: "abc"},
+		{Version: "0.0.3", Path: "0.0.3_backfill", AppliedAt: appliedAt, Duration: 20 * time.Millisecond},
+	}
+	if !slices.Equal(history, expected) {
+		t.Logf("Expected %+v. Received %+v\n", expected, history)
+		t.FailNow()
+	}
+}
+
+func TestUpgradeMigrationTable(t *testing.T) {
+	migrator := NewMigrator(openFakeDB(), MigrationLogOutput(os.Stderr))
+	// A history table from before the audit columns, where selecting them fails.
+	for _, column := range []string{"applied_at", "duration_ms", "success", "checksum"} {
+		testDriver.fail("select " + column + " from gyr_migrator_version_history")
+	}
+	if err := migrator.createMigrationTable(); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	expected := []string{
+		"alter table gyr_migrator_version_history add column applied_at timestamp null",
+		"alter table gyr_migrator_version_history add column duration_ms bigint",
+		"alter table gyr_migrator_version_history add column success boolean",
+		"alter table gyr_migrator_version_history add column checksum varchar(64)",
+	}
+	if executed := testDriver.executed(); !slices.Equal(executed[1:], expected) {
+		t.Logf("Expected %+v. Received %+v\n", expected, executed)
+		t.FailNow()
+	}
+
+	migrator = NewMigrator(openFakeDB(), MigrationLogOutput(os.Stderr))
+	if err := migrator.createMigrationTable(); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if executed := testDriver.executed(); len(executed) != 1 {
+		t.Logf("Expected an up to date table to be left alone. Executed %+v\n", executed)
+		t.FailNow()
+	}
+}
+
+func TestMigrateFromLegacyHistory(t *testing.T) {
+	migrator := NewMigrator(openFakeDB(), MigrationDirectory(filepath.Join("test_files", "migrations")), MigrationLogOutput(os.Stderr))
+	// Earlier versions of Gyr wrote one row per run, holding only the version it ended at.
+	testDriver.respond("select version, success from gyr_migrator_version_history", []driver.Value{"0.0.1", nil})
+	testDriver.respond("select version, path, applied_at", []driver.Value{"0.0.1", "test_files/migrations/0.0.1_init.sql", nil, nil, nil, nil})
+	if err := migrator.Migrate(); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	records := testDriver.executedWith("insert into gyr_migrator_version_history")
+	if len(records) != 1 || records[0][0] != "0.0.2" {
+		t.Logf("Expected only 0.0.2 to run. Recorded %+v\n", records)
+		t.FailNow()
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil || len(statuses) != 2 || !statuses[0].Applied || statuses[1].Applied {
+		t.Logf("Expected 0.0.1 to be applied by the legacy row. Received %+v (%v)\n", statuses, err)
+		t.FailNow()
+	}
+}
+
+func TestMigrationFailureRecordedOnce(t *testing.T) {
+	abort := errors.New("abort")
+	attempts := 0
+	migrator := NewMigrator(openFakeDB(),
+		MigrationDirectory(filepath.Join("test_files", "migrations")),
+		MigrationLogOutput(os.Stderr),
+		MigrationBeforeEach(func(event MigrationEvent) error {
+			// The second run fails before running anything.
+			if attempts++; attempts > 1 {
+				return abort
+			}
+			return nil
+		}),
+	)
+	migrator.Register("0.0.1_fails", func(ctx context.Context, tx *sql.Tx) error {
+		return abort
+	})
+	for range 2 {
+		if err := migrator.Migrate(); !errors.Is(err, abort) {
+			t.Logf("Expected abort error. Received %v\n", err)
+			t.FailNow()
+		}
+	}
+	if records := testDriver.executedWith("insert into gyr_migrator_version_history"); len(records) != 1 {
+		t.Logf("Expected a single failure row. Received %+v\n", records)
+		t.FailNow()
+	}
+}
+
+func TestUpgradeMigrationTableWidensVersion(t *testing.T) {
+	for _, dialect := range []Dialect{DialectMySQL, DialectPostgres} {
+		migrator := NewMigrator(openFakeDB(), MigrationLogOutput(os.Stderr), MigrationDialect(dialect))
+		// Created with version varchar(10) by earlier versions of Gyr.
+		testDriver.respond("select character_maximum_length", []driver.Value{int64(10)})
+		if err := migrator.createMigrationTable(); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		if executed := testDriver.executed(); !slices.Contains(executed, dialect.WidenVersionColumn) {
+			t.Logf("Expected the %s version column to be widened. Executed %+v\n", dialect.Name, executed)
+			t.FailNow()
+		}
+
+		migrator = NewMigrator(openFakeDB(), MigrationLogOutput(os.Stderr), MigrationDialect(dialect))
+		testDriver.respond("select character_maximum_length", []driver.Value{int64(50)})
+		if err := migrator.createMigrationTable(); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		if executed := testDriver.executed(); slices.Contains(executed, dialect.WidenVersionColumn) {
+			t.Logf("Expected a wide %s version column to be left alone. Executed %+v\n", dialect.Name, executed)
+			t.FailNow()
+		}
+	}
+}
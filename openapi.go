@@ -0,0 +1,348 @@
+package gyr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Returned when an OpenAPI document can't be loaded or bound to a router.
+var ErrInvalidOpenAPI = errors.New("invalid OpenAPI document")
+
+// The parts of an OpenAPI 3 document needed to register and validate routes.
+type OpenAPIDocument struct {
+	OpenAPI    string                      `json:"openapi"`
+	Paths      map[string]*OpenAPIPathItem `json:"paths"`
+	Components struct {
+		Schemas    map[string]*Schema           `json:"schemas"`
+		Parameters map[string]*OpenAPIParameter `json:"parameters"`
+	} `json:"components"`
+}
+
+type OpenAPIPathItem struct {
+	Parameters []*OpenAPIParameter `json:"parameters"`
+	Get        *OpenAPIOperation   `json:"get"`
+	Put        *OpenAPIOperation   `json:"put"`
+	Post       *OpenAPIOperation   `json:"post"`
+	Delete     *OpenAPIOperation   `json:"delete"`
+	Patch      *OpenAPIOperation   `json:"patch"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string              `json:"operationId"`
+	Parameters  []*OpenAPIParameter `json:"parameters"`
+	RequestBody *struct {
+		Required bool `json:"required"`
+		Content  map[string]struct {
+			Schema *Schema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+}
+
+type OpenAPIParameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// Read an OpenAPI 3 document in JSON format from file.
+func LoadOpenAPI(file string) (*OpenAPIDocument, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOpenAPI(content)
+}
+
+// Parse an OpenAPI 3 document in JSON format and resolve its references to components.
+func ParseOpenAPI(content []byte) (*OpenAPIDocument, error) {
+	var document OpenAPIDocument
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOpenAPI, err)
+	}
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		return nil, fmt.Errorf("%w: unsupported version %q", ErrInvalidOpenAPI, document.OpenAPI)
+	}
+	if err := document.resolve(); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+func (document *OpenAPIDocument) resolve() error {
+	resolved := make(map[*Schema]bool)
+	var resolveSchema func(schema *Schema) (*Schema, error)
+	resolveSchema = func(schema *Schema) (*Schema, error) {
+		if schema == nil {
+			return nil, nil
+		}
+		if schema.Ref != "" {
+			target, found := document.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+			if !found {
+				return nil, fmt.Errorf("%w: unknown reference %s", ErrInvalidOpenAPI, schema.Ref)
+			}
+			schema = target
+		}
+		if resolved[schema] {
+			return schema, nil
+		}
+		resolved[schema] = true
+		var err error
+		for name, property := range schema.Properties {
+			if schema.Properties[name], err = resolveSchema(property); err != nil {
+				return nil, err
+			}
+		}
+		schema.Items, err = resolveSchema(schema.Items)
+		return schema, err
+	}
+	resolveParameters := func(parameters []*OpenAPIParameter) error {
+		for i, parameter := range parameters {
+			if parameter.Ref != "" {
+				target, found := document.Components.Parameters[strings.TrimPrefix(parameter.Ref, "#/components/parameters/")]
+				if !found {
+					return fmt.Errorf("%w: unknown reference %s", ErrInvalidOpenAPI, parameter.Ref)
+				}
+				parameters[i] = target
+			}
+			var err error
+			if parameters[i].Schema, err = resolveSchema(parameters[i].Schema); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for path, item := range document.Paths {
+		if err := resolveParameters(item.Parameters); err != nil {
+			return err
+		}
+		for _, operation := range item.operations() {
+			if err := resolveParameters(operation.Parameters); err != nil {
+				return err
+			}
+			if operation.RequestBody == nil {
+				continue
+			}
+			for contentType, media := range operation.RequestBody.Content {
+				var err error
+				if media.Schema, err = resolveSchema(media.Schema); err != nil {
+					return fmt.Errorf("%s %s: %w", path, contentType, err)
+				}
+				operation.RequestBody.Content[contentType] = media
+			}
+		}
+	}
+	return nil
+}
+
+// The operations of the path item by HTTP method.
+func (item *OpenAPIPathItem) operations() map[string]*OpenAPIOperation {
+	operations := make(map[string]*OpenAPIOperation)
+	for method, operation := range map[string]*OpenAPIOperation{
+		http.MethodGet:    item.Get,
+		http.MethodPut:    item.Put,
+		http.MethodPost:   item.Post,
+		http.MethodDelete: item.Delete,
+		http.MethodPatch:  item.Patch,
+	} {
+		if operation != nil {
+			operations[method] = operation
+		}
+	}
+	return operations
+}
+
+type OpenAPISettings struct {
+	// Handlers by operationId. Operations without a handler respond with 501 Not Implemented.
+	Handlers map[string]Handler
+}
+
+func DefaultOpenAPISettings() OpenAPISettings {
+	return OpenAPISettings{Handlers: make(map[string]Handler)}
+}
+
+func OpenAPIHandler(operationID string, handler Handler) func(*OpenAPISettings) {
+	return func(oas *OpenAPISettings) {
+		oas.Handlers[operationID] = handler
+	}
+}
+
+// Register a route for every operation in document. Parameters and JSON bodies are validated against the
+// document before the handler runs, and invalid requests get 400 with a [ValidationErrorBody]. Operations that
+// have no handler yet respond with 501 Not Implemented.
+//
+//	document, err := gyr.LoadOpenAPI("openapi.json")
+//	err = router.BindOpenAPI(document, gyr.OpenAPIHandler("getUser", GetUserHandler))
+func (router *Router) BindOpenAPI(document *OpenAPIDocument, settings ...SettingsFunc[OpenAPISettings]) error {
+	openAPISettings := DefaultOpenAPISettings()
+	for _, setting := range settings {
+		setting(&openAPISettings)
+	}
+
+	unused := make(map[string]bool)
+	for operationID := range openAPISettings.Handlers {
+		unused[operationID] = true
+	}
+	paths := make([]string, 0, len(document.Paths))
+	for path := range document.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		item := document.Paths[path]
+		route := router.Path(openAPIRoutePath(path))
+		for method, operation := range item.operations() {
+			handler, exists := openAPISettings.Handlers[operation.OperationID]
+			if !exists {
+				handler = notImplemented
+			}
+			delete(unused, operation.OperationID)
+			route.method(method, operation.validated(mergeParameters(item.Parameters, operation.Parameters), handler))
+		}
+	}
+
+	if len(unused) > 0 {
+		operationIDs := make([]string, 0, len(unused))
+		for operationID := range unused {
+			operationIDs = append(operationIDs, operationID)
+		}
+		sort.Strings(operationIDs)
+		return fmt.Errorf("%w: no operations named %s", ErrInvalidOpenAPI, strings.Join(operationIDs, ", "))
+	}
+	return nil
+}
+
+// Convert /users/{id} to /users/:id.
+func openAPIRoutePath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			parts[i] = ":" + strings.Trim(part, "{}")
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// Operation parameters override path item parameters with the same name and location.
+func mergeParameters(pathParameters []*OpenAPIParameter, operationParameters []*OpenAPIParameter) []*OpenAPIParameter {
+	merged := make([]*OpenAPIParameter, 0, len(pathParameters)+len(operationParameters))
+	for _, parameter := range pathParameters {
+		overridden := false
+		for _, override := range operationParameters {
+			overridden = overridden || (override.Name == parameter.Name && override.In == parameter.In)
+		}
+		if !overridden {
+			merged = append(merged, parameter)
+		}
+	}
+	return append(merged, operationParameters...)
+}
+
+func notImplemented(ctx *Context) *Response {
+	return ctx.Response().Status(http.StatusNotImplemented).Text("501 - Not Implemented")
+}
+
+func (operation *OpenAPIOperation) validated(parameters []*OpenAPIParameter, handler Handler) Handler {
+	return func(ctx *Context) *Response {
+		errs := make(ValidationErrors, 0)
+		for _, parameter := range parameters {
+			field := parameter.In + "." + parameter.Name
+			value, present := parameter.lookup(ctx)
+			if !present {
+				if parameter.Required || parameter.In == "path" {
+					errs = append(errs, ValidationError{Field: field, Rule: "required", Message: "is required", Code: validationCode("required")})
+				}
+				continue
+			}
+			if parameter.Schema != nil {
+				parameter.Schema.validate(field, parameter.Schema.coerce(value), &errs)
+			}
+		}
+		if err := operation.validateBody(ctx, &errs); err != nil {
+			return ctx.Response().ValidationError(err)
+		}
+
+		if len(errs) > 0 {
+			return ctx.Response().ValidationError(errs)
+		}
+		return handler(ctx)
+	}
+}
+
+func (parameter *OpenAPIParameter) lookup(ctx *Context) (string, bool) {
+	switch parameter.In {
+	case "path":
+		value := ctx.Variable(parameter.Name)
+		return fmt.Sprint(value), value != nil
+	case "query":
+		values, present := ctx.Request.URL.Query()[parameter.Name]
+		return strings.Join(values, ","), present
+	case "header":
+		values := ctx.Request.Header.Values(parameter.Name)
+		return strings.Join(values, ","), len(values) > 0
+	case "cookie":
+		cookie, err := ctx.Request.Cookie(parameter.Name)
+		if err != nil {
+			return "", false
+		}
+		return cookie.Value, true
+	}
+	return "", false
+}
+
+// Validate the body against the schema for its content type. The body is put back so the handler can read
+// it. Errors that aren't validation failures, such as an unreadable body, are returned.
+func (operation *OpenAPIOperation) validateBody(ctx *Context, errs *ValidationErrors) error {
+	if operation.RequestBody == nil {
+		return nil
+	}
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return err
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		if operation.RequestBody.Required {
+			*errs = append(*errs, ValidationError{Field: "body", Rule: "required", Message: "is required", Code: validationCode("required")})
+		}
+		return nil
+	}
+
+	contentType := parseContentType(ctx.Request.Header.Get("Content-Type")).mimetype
+	media, found := operation.RequestBody.Content[contentType]
+	if !found {
+		allowed := make([]string, 0, len(operation.RequestBody.Content))
+		for candidate := range operation.RequestBody.Content {
+			allowed = append(allowed, candidate)
+		}
+		sort.Strings(allowed)
+		*errs = append(*errs, ValidationError{Field: "body", Rule: "oneof", Param: strings.Join(allowed, " "), Message: "must have content type " + strings.Join(allowed, ", "), Code: validationCode("oneof")})
+		return nil
+	}
+	if media.Schema == nil || !strings.HasSuffix(contentType, "json") {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return err
+	}
+	start := len(*errs)
+	media.Schema.validate("", value, errs)
+	for i := start; i < len(*errs); i++ {
+		if (*errs)[i].Field == "" {
+			(*errs)[i].Field = "body"
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package gyr_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestBindOpenAPI(t *testing.T) {
+	document, err := gyr.LoadOpenAPI("test_files/openapi.json")
+	if err != nil {
+		t.Logf("Failed loading document: %v\n", err)
+		t.FailNow()
+	}
+	router := defaultTestRouter()
+	err = router.BindOpenAPI(document,
+		gyr.OpenAPIHandler("listUsers", func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Json([]string{}) }),
+		gyr.OpenAPIHandler("createUser", func(ctx *gyr.Context) *gyr.Response {
+			user, _ := gyr.ReadBody[map[string]any](ctx)
+			return ctx.Response().Status(http.StatusCreated).Json(user)
+		}),
+	)
+	if err != nil {
+		t.Logf("Failed binding document: %v\n", err)
+		t.FailNow()
+	}
+
+	t.Run("valid requests reach the handler", func(t *testing.T) {
+		gyrtest.Get("/users").Query("limit", "10").Send(router).AssertStatus(t, http.StatusOK)
+		gyrtest.Post("/users").JSON(map[string]any{"name": "Ada", "email": "ada@example.com"}).Send(router).
+			AssertStatus(t, http.StatusCreated).
+			AssertJSON(t, map[string]any{"name": "Ada", "email": "ada@example.com"})
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		response := gyrtest.Get("/users").Query("limit", "500").Send(router).AssertStatus(t, http.StatusBadRequest)
+		body := gyrtest.DecodeJSON[gyr.ValidationErrorBody](t, response)
+		if len(body.Errors) != 1 || body.Errors[0].Field != "query.limit" || body.Errors[0].Rule != "max" {
+			t.Logf("Unexpected errors %+v\n", body.Errors)
+			t.FailNow()
+		}
+		gyrtest.Delete("/users/0").Send(router).AssertStatus(t, http.StatusBadRequest)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		response := gyrtest.Post("/users").JSON(map[string]any{"name": "A", "role": "owner", "tags": []any{1}}).Send(router).
+			AssertStatus(t, http.StatusBadRequest)
+		body := gyrtest.DecodeJSON[gyr.ValidationErrorBody](t, response)
+		fields := make([]string, len(body.Errors))
+		for i, validationError := range body.Errors {
+			fields[i] = validationError.Field + ":" + validationError.Rule
+		}
+		expected := []string{"email:required", "name:min", "role:oneof", "tags.0:type"}
+		if len(fields) != len(expected) {
+			t.Logf("Expected %v. Received %v\n", expected, fields)
+			t.FailNow()
+		}
+		for i := range expected {
+			if fields[i] != expected[i] {
+				t.Logf("Expected %v. Received %v\n", expected, fields)
+				t.FailNow()
+			}
+		}
+		gyrtest.Post("/users").Send(router).AssertStatus(t, http.StatusBadRequest)
+	})
+
+	t.Run("operations without handler", func(t *testing.T) {
+		gyrtest.Delete("/users/3").Send(router).AssertStatus(t, http.StatusNotImplemented)
+	})
+}
+
+func TestBindOpenAPIUnknownOperation(t *testing.T) {
+	document, _ := gyr.LoadOpenAPI("test_files/openapi.json")
+	err := defaultTestRouter().BindOpenAPI(document, gyr.OpenAPIHandler("getUsers", func(ctx *gyr.Context) *gyr.Response { return nil }))
+	if !errors.Is(err, gyr.ErrInvalidOpenAPI) {
+		t.Logf("Expected ErrInvalidOpenAPI for unknown operation. Received %v\n", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,80 @@
+package gyr
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+type MethodOverrideSettings struct {
+	// Header naming the method, used by clients that can only send GET and POST.
+	Header string
+	// Form field naming the method, for HTML forms.
+	FormField string
+	// Methods a POST request may be turned into.
+	Allowed []string
+}
+
+func DefaultMethodOverrideSettings() MethodOverrideSettings {
+	return MethodOverrideSettings{
+		Header:    "X-HTTP-Method-Override",
+		FormField: "_method",
+		Allowed:   []string{http.MethodPut, http.MethodPatch, http.MethodDelete},
+	}
+}
+
+func MethodOverrideHeader(name string) func(*MethodOverrideSettings) {
+	return func(mos *MethodOverrideSettings) {
+		mos.Header = name
+	}
+}
+
+func MethodOverrideFormField(name string) func(*MethodOverrideSettings) {
+	return func(mos *MethodOverrideSettings) {
+		mos.FormField = name
+	}
+}
+
+func MethodOverrideAllowed(methods ...string) func(*MethodOverrideSettings) {
+	return func(mos *MethodOverrideSettings) {
+		mos.Allowed = methods
+	}
+}
+
+// Let POST requests be handled as another method named by a header or form field, so HTML forms and
+// legacy clients can reach PUT, PATCH and DELETE routes. Methods outside the allowlist are ignored.
+func (router *Router) MethodOverride(settings ...SettingsFunc[MethodOverrideSettings]) {
+	overrideSettings := DefaultMethodOverrideSettings()
+	for _, setting := range settings {
+		setting(&overrideSettings)
+	}
+	router.methodOverride = &overrideSettings
+}
+
+// Returns a copy of req with the overridden method, or req itself if there is no override.
+func (router *Router) overrideMethod(req *http.Request) *http.Request {
+	settings := router.methodOverride
+	if settings == nil || req.Method != http.MethodPost {
+		return req
+	}
+
+	method := ""
+	if settings.Header != "" {
+		method = req.Header.Get(settings.Header)
+	}
+	if method == "" && settings.FormField != "" && isFormContentType(req.Header.Get("Content-Type")) {
+		method = req.FormValue(settings.FormField)
+	}
+	method = strings.ToUpper(method)
+	if !slices.Contains(settings.Allowed, method) {
+		return req
+	}
+	overridden := req.WithContext(req.Context())
+	overridden.Method = method
+	return overridden
+}
+
+func isFormContentType(header string) bool {
+	mimetype := parseContentType(header).mimetype
+	return mimetype == "application/x-www-form-urlencoded" || mimetype == "multipart/form-data"
+}
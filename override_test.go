@@ -0,0 +1,46 @@
+package gyr_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestMethodOverride(t *testing.T) {
+	router := gyr.DefaultRouter()
+	router.MethodOverride()
+	router.Path("/items/:id").
+		Delete(func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Text("deleted") }).
+		Put(func(ctx *gyr.Context) *gyr.Response {
+			return ctx.Response().Text("updated " + ctx.Request.PostForm.Get("name"))
+		}).
+		Post(func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Text("posted") })
+
+	form := url.Values{"_method": {"put"}, "name": {"lamp"}}
+	tests := []struct {
+		name        string
+		header      string
+		body        string
+		contentType string
+		expected    string
+	}{
+		{"Header", "DELETE", "", "", "deleted"},
+		{"Form field", "", form.Encode(), "application/x-www-form-urlencoded", "updated lamp"},
+		{"Not allowed", "GET", "", "", "posted"},
+		{"Form field needs form body", "", `{"_method":"DELETE"}`, "application/json", "posted"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodPost, "/items/1", strings.NewReader(test.body))
+			request.Header.Set("X-HTTP-Method-Override", test.header)
+			request.Header.Set("Content-Type", test.contentType)
+			if received := sendRequest(router, request).Body.String(); received != test.expected {
+				t.Logf("Expected %s. Received %s\n", test.expected, received)
+				t.FailNow()
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package gyr
+
+import (
+	"bytes"
+	"sync"
+)
+
+// A typed [sync.Pool] for reusing values on hot paths. Values are reset when put back, and may be dropped by
+// the garbage collector at any time.
+//
+//	var buffers = gyr.NewPool(func() *bytes.Buffer { return new(bytes.Buffer) }, (*bytes.Buffer).Reset)
+//
+//	buf := buffers.Get()
+//	defer buffers.Put(buf)
+type Pool[T any] struct {
+	pool  sync.Pool
+	reset func(T)
+}
+
+// Create a pool making new values with newValue and clearing them with reset, which may be nil.
+func NewPool[T any](newValue func() T, reset func(T)) *Pool[T] {
+	return &Pool[T]{
+		pool:  sync.Pool{New: func() any { return newValue() }},
+		reset: reset,
+	}
+}
+
+// A value from the pool, or a new one if the pool is empty.
+func (pool *Pool[T]) Get() T {
+	return pool.pool.Get().(T)
+}
+
+// Reset value and return it to the pool. The value must not be used after it is put back.
+func (pool *Pool[T]) Put(value T) {
+	if pool.reset != nil {
+		pool.reset(value)
+	}
+	pool.pool.Put(value)
+}
+
+// Buffers larger than this are not put back in [bufferPool], so a single large response doesn't keep its
+// memory alive.
+const maxPooledBufferSize = 64 * 1024
+
+var bufferPool = NewPool(func() *bytes.Buffer { return new(bytes.Buffer) }, (*bytes.Buffer).Reset)
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBufferSize {
+		bufferPool.Put(buf)
+	}
+}
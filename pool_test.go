@@ -0,0 +1,28 @@
+package gyr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestPool(t *testing.T) {
+	created := 0
+	pool := gyr.NewPool(func() *strings.Builder {
+		created++
+		return &strings.Builder{}
+	}, (*strings.Builder).Reset)
+
+	builder := pool.Get()
+	builder.WriteString("used")
+	pool.Put(builder)
+	if builder.Len() != 0 {
+		t.Logf("Expected the value to be reset when put back. Received %q\n", builder.String())
+		t.FailNow()
+	}
+	if reused := pool.Get(); reused.Len() != 0 || created == 0 {
+		t.Logf("Expected an empty value from the pool. Received %q after creating %d\n", reused.String(), created)
+		t.FailNow()
+	}
+}
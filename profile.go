@@ -0,0 +1,69 @@
+package gyr
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// A bundle of behavior that differs between environments, selected with one switch. See [CurrentProfile].
+type Profile struct {
+	Name string
+	// Log at debug level and allow [Router.Debug].
+	Debug bool
+	// Show the panic and stack trace in the response when a handler panics, instead of a plain 500.
+	DebugErrors bool
+	// Print the routes of the router when serving with [ServerComponent].
+	PrintRoutes bool
+	// Allow requests from any origin in [CORS].
+	RelaxedCORS bool
+}
+
+var (
+	ProfileDevelopment = Profile{Name: "development", Debug: true, DebugErrors: true, PrintRoutes: true, RelaxedCORS: true}
+	ProfileStaging     = Profile{Name: "staging", DebugErrors: true, PrintRoutes: true}
+	ProfileProduction  = Profile{Name: "production"}
+)
+
+var profileOverride atomic.Pointer[Profile]
+
+// Use profile instead of the one resolved from the environment. Setting a profile without a name goes back
+// to resolving it from the environment.
+func SetProfile(profile Profile) {
+	if profile.Name == "" {
+		profileOverride.Store(nil)
+		return
+	}
+	profileOverride.Store(&profile)
+}
+
+// The active profile. Unless set with [SetProfile] it is resolved from GYR_PROFILE, or APP_ENV if that isn't
+// set: dev and development select [ProfileDevelopment], staging [ProfileStaging] and anything else
+// [ProfileProduction]. Without either variable the profile is development when GYR_DEBUG is set and production
+// otherwise. GYR_DEBUG always turns on Debug.
+func CurrentProfile() Profile {
+	if profile := profileOverride.Load(); profile != nil {
+		return *profile
+	}
+
+	_, debug := os.LookupEnv("GYR_DEBUG")
+	name, isSet := os.LookupEnv("GYR_PROFILE")
+	if !isSet {
+		name, isSet = os.LookupEnv("APP_ENV")
+	}
+
+	var profile Profile
+	switch strings.ToLower(name) {
+	case "dev", "development", "local":
+		profile = ProfileDevelopment
+	case "staging", "stage":
+		profile = ProfileStaging
+	default:
+		profile = ProfileProduction
+		if !isSet && debug {
+			profile = ProfileDevelopment
+		}
+	}
+	profile.Debug = profile.Debug || debug
+	return profile
+}
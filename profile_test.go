@@ -0,0 +1,71 @@
+package gyr_test
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func TestCurrentProfile(t *testing.T) {
+	for _, name := range []string{"GYR_PROFILE", "APP_ENV", "GYR_DEBUG"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+
+	if profile := gyr.CurrentProfile(); profile != gyr.ProfileProduction {
+		t.Logf("Expected production by default. Received %+v\n", profile)
+		t.FailNow()
+	}
+
+	t.Setenv("APP_ENV", "staging")
+	if profile := gyr.CurrentProfile(); profile != gyr.ProfileStaging {
+		t.Logf("Expected staging from APP_ENV. Received %+v\n", profile)
+		t.FailNow()
+	}
+
+	t.Setenv("GYR_PROFILE", "dev")
+	if profile := gyr.CurrentProfile(); profile != gyr.ProfileDevelopment {
+		t.Logf("Expected GYR_PROFILE to take precedence. Received %+v\n", profile)
+		t.FailNow()
+	}
+
+	t.Setenv("GYR_PROFILE", "production")
+	t.Setenv("GYR_DEBUG", "")
+	if profile := gyr.CurrentProfile(); !profile.Debug || profile.DebugErrors {
+		t.Logf("Expected GYR_DEBUG to only turn on Debug. Received %+v\n", profile)
+		t.FailNow()
+	}
+
+	gyr.SetProfile(gyr.Profile{Name: "custom"})
+	defer gyr.SetProfile(gyr.Profile{})
+	if profile := gyr.CurrentProfile(); profile.Name != "custom" {
+		t.Logf("Expected profile set with SetProfile. Received %+v\n", profile)
+		t.FailNow()
+	}
+}
+
+func TestPanicResponses(t *testing.T) {
+	router := defaultTestRouter()
+	router.Path("/panic").Get(func(ctx *gyr.Context) *gyr.Response {
+		panic("boom")
+	})
+	defer gyr.SetProfile(gyr.Profile{})
+
+	gyr.SetProfile(gyr.ProfileProduction)
+	response := gyrtest.Get("/panic").Send(router).AssertStatus(t, http.StatusInternalServerError)
+	if strings.Contains(response.Body.String(), "boom") {
+		t.Logf("Expected no panic details in production. Received %s\n", response.Body.String())
+		t.FailNow()
+	}
+
+	gyr.SetProfile(gyr.ProfileDevelopment)
+	response = gyrtest.Get("/panic").Send(router).AssertStatus(t, http.StatusInternalServerError)
+	if !strings.Contains(response.Body.String(), "panic: boom") {
+		t.Logf("Expected panic details in development. Received %s\n", response.Body.String())
+		t.FailNow()
+	}
+}
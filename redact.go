@@ -0,0 +1,167 @@
+package gyr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+type RedactionSettings struct {
+	// Headers whose values are masked.
+	DenyHeaders []string
+	// When not empty, only these headers are shown and all others are masked.
+	AllowHeaders []string
+	// Query parameters and form fields whose values are masked.
+	QueryParams []string
+	// Dot separated paths of JSON body fields to mask. * matches every key or array element, so
+	// cards.*.number masks the number of every card.
+	BodyFields []string
+	Mask       string
+}
+
+func DefaultRedactionSettings() RedactionSettings {
+	return RedactionSettings{
+		DenyHeaders: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+		QueryParams: []string{"token", "access_token", "api_key", "password"},
+		BodyFields:  []string{"password"},
+		Mask:        "[REDACTED]",
+	}
+}
+
+func RedactHeaders(headers ...string) func(*RedactionSettings) {
+	return func(rs *RedactionSettings) {
+		rs.DenyHeaders = append(rs.DenyHeaders, headers...)
+	}
+}
+
+// Mask every header except these.
+func RedactAllHeadersExcept(headers ...string) func(*RedactionSettings) {
+	return func(rs *RedactionSettings) {
+		rs.AllowHeaders = headers
+	}
+}
+
+func RedactQueryParams(params ...string) func(*RedactionSettings) {
+	return func(rs *RedactionSettings) {
+		rs.QueryParams = append(rs.QueryParams, params...)
+	}
+}
+
+func RedactBodyFields(paths ...string) func(*RedactionSettings) {
+	return func(rs *RedactionSettings) {
+		rs.BodyFields = append(rs.BodyFields, paths...)
+	}
+}
+
+// Masks sensitive values in headers, URLs and bodies before they are logged or recorded.
+type Redactor struct {
+	Settings RedactionSettings
+}
+
+func NewRedactor(settings ...SettingsFunc[RedactionSettings]) *Redactor {
+	redactionSettings := DefaultRedactionSettings()
+	for _, setting := range settings {
+		setting(&redactionSettings)
+	}
+	return &Redactor{Settings: redactionSettings}
+}
+
+// Configure how the router redacts requests in its logs and the debug recorder. Redaction with
+// [DefaultRedactionSettings] is applied when this isn't called.
+func (router *Router) Redaction(settings ...SettingsFunc[RedactionSettings]) {
+	router.redactor = NewRedactor(settings...)
+}
+
+// A copy of header with the values of sensitive headers masked.
+func (redactor *Redactor) Headers(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name, values := range redacted {
+		if !redactor.isSensitiveHeader(name) {
+			continue
+		}
+		for i := range values {
+			values[i] = redactor.Settings.Mask
+		}
+	}
+	return redacted
+}
+
+func (redactor *Redactor) isSensitiveHeader(name string) bool {
+	matches := func(candidate string) bool { return strings.EqualFold(candidate, name) }
+	if len(redactor.Settings.AllowHeaders) > 0 {
+		return !slices.ContainsFunc(redactor.Settings.AllowHeaders, matches)
+	}
+	return slices.ContainsFunc(redactor.Settings.DenyHeaders, matches)
+}
+
+// The URL with the values of sensitive query parameters masked.
+func (redactor *Redactor) URL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	redacted := *u
+	redacted.RawQuery = redactor.query(u.RawQuery)
+	return redacted.String()
+}
+
+func (redactor *Redactor) query(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redactor.Settings.Mask
+	}
+	for name, paramValues := range values {
+		if !slices.ContainsFunc(redactor.Settings.QueryParams, func(param string) bool { return strings.EqualFold(param, name) }) {
+			continue
+		}
+		for i := range paramValues {
+			paramValues[i] = redactor.Settings.Mask
+		}
+	}
+	return values.Encode()
+}
+
+// The body with sensitive fields masked. JSON bodies are masked by path and form bodies by field name,
+// other bodies are returned unchanged.
+func (redactor *Redactor) Body(contentType string, body string) string {
+	switch parseContentType(contentType).mimetype {
+	case "application/json":
+		var document any
+		if err := json.Unmarshal([]byte(body), &document); err != nil {
+			return body
+		}
+		for _, path := range redactor.Settings.BodyFields {
+			document = redactor.maskPath(document, strings.Split(path, "."))
+		}
+		redacted, err := json.Marshal(document)
+		if err != nil {
+			return body
+		}
+		return string(redacted)
+	case "application/x-www-form-urlencoded":
+		return redactor.query(body)
+	}
+	return body
+}
+
+func (redactor *Redactor) maskPath(value any, path []string) any {
+	if len(path) == 0 {
+		return redactor.Settings.Mask
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if path[0] == "*" || path[0] == key {
+				value[key] = redactor.maskPath(child, path[1:])
+			}
+		}
+	case []any:
+		if path[0] == "*" {
+			for i, child := range value {
+				value[i] = redactor.maskPath(child, path[1:])
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,77 @@
+package gyr_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestRedactor(t *testing.T) {
+	redactor := gyr.NewRedactor(gyr.RedactBodyFields("cards.*.number"))
+
+	t.Run("Headers", func(t *testing.T) {
+		headers := http.Header{"Authorization": {"Bearer secret"}, "Accept": {"text/html"}}
+		redacted := redactor.Headers(headers)
+		if redacted.Get("Authorization") != "[REDACTED]" || redacted.Get("Accept") != "text/html" || headers.Get("Authorization") != "Bearer secret" {
+			t.Logf("Unexpected redacted headers %v from %v\n", redacted, headers)
+			t.FailNow()
+		}
+
+		allowOnly := gyr.NewRedactor(gyr.RedactAllHeadersExcept("Accept"))
+		redacted = allowOnly.Headers(http.Header{"X-Custom": {"value"}, "Accept": {"text/html"}})
+		if redacted.Get("X-Custom") != "[REDACTED]" || redacted.Get("Accept") != "text/html" {
+			t.Logf("Expected headers outside the allow list to be masked. Received %v\n", redacted)
+			t.FailNow()
+		}
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		u, _ := url.Parse("/items?token=abc&page=2")
+		if redacted := redactor.URL(u); redacted != "/items?page=2&token=%5BREDACTED%5D" {
+			t.Logf("Unexpected redacted URL %s\n", redacted)
+			t.FailNow()
+		}
+	})
+
+	t.Run("Body", func(t *testing.T) {
+		redacted := redactor.Body("application/json", `{"password":"hunter2","cards":[{"number":"4111","name":"A"}]}`)
+		var body map[string]any
+		json.Unmarshal([]byte(redacted), &body)
+		card := body["cards"].([]any)[0].(map[string]any)
+		if body["password"] != "[REDACTED]" || card["number"] != "[REDACTED]" || card["name"] != "A" {
+			t.Logf("Unexpected redacted body %s\n", redacted)
+			t.FailNow()
+		}
+
+		if redacted := redactor.Body("application/x-www-form-urlencoded", "password=x&user=y"); redacted != "password=%5BREDACTED%5D&user=y" {
+			t.Logf("Unexpected redacted form %s\n", redacted)
+			t.FailNow()
+		}
+	})
+}
+
+func TestDebugRecorderRedacts(t *testing.T) {
+	t.Setenv("GYR_DEBUG", "")
+	recorder := gyr.NewDebugRecorder()
+	router := defaultTestRouter()
+	router.Debug(recorder)
+	router.Redaction(gyr.RedactQueryParams("session"))
+	router.Path("/login").Post(func(ctx *gyr.Context) *gyr.Response {
+		gyr.ReadBody[map[string]string](ctx)
+		return ctx.Response().Text("ok")
+	})
+
+	request, _ := http.NewRequest(http.MethodPost, "/login?session=abc", createPayload(map[string]string{"password": "hunter2"}))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer secret")
+	sendRequest(router, request)
+
+	recorded := recorder.Requests()[0]
+	if recorded.URL != "/login?session=%5BREDACTED%5D" || recorded.RequestHeaders.Get("Authorization") != "[REDACTED]" || recorded.RequestBody != `{"password":"[REDACTED]"}` {
+		t.Logf("Expected recording to be redacted. Received %+v\n", recorded)
+		t.FailNow()
+	}
+}
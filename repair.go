@@ -0,0 +1,162 @@
+package gyr
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// What [Migrator.Repair] changed in the history table. Each slice contains the affected history paths.
+type RepairReport struct {
+	// Entries removed because their file no longer exists.
+	Removed []string
+	// Entries whose checksum was recomputed from the current file content.
+	ChecksumsUpdated []string
+	// Entries whose path or version was normalized.
+	PathsFixed []string
+}
+
+// Whether the repair changed anything.
+func (report RepairReport) Changed() bool {
+	return len(report.Removed) > 0 || len(report.ChecksumsUpdated) > 0 || len(report.PathsFixed) > 0
+}
+
+type historyEntry struct {
+	version  string
+	path     string
+	checksum string
+}
+
+// Reconcile the history table with the migrations on disk. Entries whose file can't be found in the migration
+// directory are removed, checksums are recomputed and paths and versions are normalized so that the ordering of
+// the history is correct. Paths are matched by file name when the stored path doesn't exist from the current
+// working directory, and nothing is changed if the migration directory can't be found. Entries of Go migrations
+// are left as they are, whether or not they are registered.
+func (mig *Migrator) Repair() (RepairReport, error) {
+	report := RepairReport{
+		Removed:          make([]string, 0),
+		ChecksumsUpdated: make([]string, 0),
+		PathsFixed:       make([]string, 0),
+	}
+	if info, err := os.Stat(mig.Settings.Directory); err != nil {
+		return report, err
+	} else if !info.IsDir() {
+		return report, fmt.Errorf("migration directory %s is not a directory", mig.Settings.Directory)
+	}
+	files := make(map[string]string)
+	for _, file := range getSqlFilenames(mig.Settings.Directory) {
+		files[filepath.Base(file)] = filepath.ToSlash(file)
+	}
+	if err := mig.createMigrationTable(); err != nil {
+		return report, err
+	}
+	entries, err := mig.historyEntries()
+	if err != nil {
+		return report, err
+	}
+
+	transaction, err := mig.beginTransaction()
+	if err != nil {
+		return report, err
+	}
+	defer mig.rollbackTransaction(transaction)
+
+	dialect := mig.Settings.Dialect
+	for _, entry := range entries {
+		// Go migrations have no file, and may not be registered when repairing from the command line.
+		if mig.isGoMigration(entry.path) || !strings.HasSuffix(strings.ToLower(entry.path), ".sql") {
+			continue
+		}
+
+		// Windows paths in the history would otherwise not match the files or produce broken versions.
+		path := strings.ReplaceAll(entry.path, "\\", "/")
+		if _, err := os.Stat(filepath.FromSlash(path)); errors.Is(err, os.ErrNotExist) {
+			if found, exists := files[filepath.Base(filepath.FromSlash(path))]; exists {
+				path = found
+			}
+		}
+		if _, err := os.Stat(filepath.FromSlash(path)); errors.Is(err, os.ErrNotExist) {
+			const query = "delete from gyr_migrator_version_history where path = ?"
+			if _, err := transaction.ExecContext(mig.Settings.Context, dialect.Rebind(query), entry.path); err != nil {
+				return report, err
+			}
+			report.Removed = append(report.Removed, entry.path)
+			continue
+		}
+
+		version := migrationVersionFromFilepath(path)
+		if path != entry.path || version != entry.version {
+			const query = "update gyr_migrator_version_history set path = ?, version = ? where path = ?"
+			if _, err := transaction.ExecContext(mig.Settings.Context, dialect.Rebind(query), path, version, entry.path); err != nil {
+				return report, err
+			}
+			report.PathsFixed = append(report.PathsFixed, entry.path)
+		}
+
+		checksum, err := fileChecksum(filepath.FromSlash(path))
+		if err != nil {
+			return report, err
+		}
+		if checksum != entry.checksum {
+			const query = "update gyr_migrator_version_history set checksum = ? where path = ?"
+			if _, err := transaction.ExecContext(mig.Settings.Context, dialect.Rebind(query), checksum, path); err != nil {
+				return report, err
+			}
+			report.ChecksumsUpdated = append(report.ChecksumsUpdated, path)
+		}
+	}
+
+	if err := mig.commitTransaction(transaction); err != nil {
+		return report, err
+	}
+	mig.logger.Info("Repaired migration history", "removed", len(report.Removed), "checksums", len(report.ChecksumsUpdated), "paths", len(report.PathsFixed))
+	return report, nil
+}
+
+func (mig *Migrator) historyEntries() ([]historyEntry, error) {
+	const query = "select version, path, checksum from gyr_migrator_version_history"
+	rows, err := mig.connection.QueryContext(mig.Settings.Context, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]historyEntry, 0)
+	for rows.Next() {
+		var entry historyEntry
+		var checksum sql.NullString
+		if err := rows.Scan(&entry.version, &entry.path, &checksum); err != nil {
+			return nil, err
+		}
+		entry.checksum = checksum.String
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (mig *Migrator) isGoMigration(path string) bool {
+	return slices.ContainsFunc(mig.goMigrations, func(m migration) bool {
+		return m.path == path
+	})
+}
+
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
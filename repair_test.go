@@ -0,0 +1,104 @@
+package gyr
+
+import (
+	"database/sql/driver"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	db := openFakeDB()
+	checksum, _ := fileChecksum("test_files/migrations/0.0.1_init.sql")
+	testDriver.respond("select version, path, checksum",
+		[]driver.Value{"0.0.1", "test_files/migrations/0.0.1_init.sql", checksum},
+		[]driver.Value{"test_files\\migrations\\nested\\0.0.2_age.sql", "test_files\\migrations\\nested\\0.0.2_age.sql", nil},
+		[]driver.Value{"0.0.3", "test_files/migrations/0.0.3_deleted.sql", "abc"},
+		[]driver.Value{"0.0.4", "0.0.4_backfill", nil},
+	)
+	migrator := NewMigrator(db, MigrationLogOutput(os.Stderr), MigrationDirectory("test_files/migrations"))
+	migrator.Register("0.0.4_backfill", nil)
+
+	report, err := migrator.Repair()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	t.Run("Removed", func(t *testing.T) {
+		if len(report.Removed) != 1 || report.Removed[0] != "test_files/migrations/0.0.3_deleted.sql" {
+			t.Logf("Received %+v\n", report.Removed)
+			t.FailNow()
+		}
+	})
+	t.Run("Paths fixed", func(t *testing.T) {
+		if len(report.PathsFixed) != 1 {
+			t.Logf("Received %+v\n", report.PathsFixed)
+			t.FailNow()
+		}
+	})
+	t.Run("Checksums updated", func(t *testing.T) {
+		if len(report.ChecksumsUpdated) != 1 || report.ChecksumsUpdated[0] != "test_files/migrations/nested/0.0.2_age.sql" {
+			t.Logf("Received %+v\n", report.ChecksumsUpdated)
+			t.FailNow()
+		}
+	})
+}
+
+func TestRepairFromOtherDirectory(t *testing.T) {
+	t.Run("matches files by name", func(t *testing.T) {
+		db := openFakeDB()
+		checksum, _ := fileChecksum("test_files/migrations/0.0.1_init.sql")
+		// Recorded by a migrate run from within test_files.
+		testDriver.respond("select version, path, checksum",
+			[]driver.Value{"0.0.1", "migrations/0.0.1_init.sql", checksum},
+			[]driver.Value{"0.0.2", "migrations/nested/0.0.2_age.sql", nil},
+		)
+		migrator := NewMigrator(db, MigrationLogOutput(os.Stderr), MigrationDirectory("test_files/migrations"))
+
+		report, err := migrator.Repair()
+		if err != nil || len(report.Removed) != 0 || len(report.PathsFixed) != 2 {
+			t.Logf("Expected both entries to be kept with fixed paths. Received %+v (%v)\n", report, err)
+			t.FailNow()
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		db := openFakeDB()
+		testDriver.respond("select version, path, checksum",
+			[]driver.Value{"0.0.1", "migrations/0.0.1_init.sql", "abc"},
+		)
+		migrator := NewMigrator(db, MigrationLogOutput(os.Stderr), MigrationDirectory("does_not_exist"))
+
+		if _, err := migrator.Repair(); err == nil {
+			t.Log("Expected an error for a missing migration directory")
+			t.FailNow()
+		}
+		for _, statement := range testDriver.executed() {
+			if strings.HasPrefix(statement, "delete") {
+				t.Logf("Expected no history to be deleted. Executed %q\n", statement)
+				t.FailNow()
+			}
+		}
+	})
+}
+
+func TestRepairKeepsUnregisteredGoMigrations(t *testing.T) {
+	db := openFakeDB()
+	testDriver.respond("select version, path, checksum",
+		[]driver.Value{"0.0.4", "0.0.4_backfill", nil},
+	)
+	// Like the command line, which can't register Go migrations.
+	migrator := NewMigrator(db, MigrationLogOutput(os.Stderr), MigrationDirectory("test_files/migrations"))
+
+	report, err := migrator.Repair()
+	if err != nil || report.Changed() {
+		t.Logf("Expected the Go migration to be left alone. Received %+v (%v)\n", report, err)
+		t.FailNow()
+	}
+	for _, statement := range testDriver.executed() {
+		if strings.HasPrefix(statement, "delete") || strings.HasPrefix(statement, "update") {
+			t.Logf("Expected the history to be left alone. Executed %q\n", statement)
+			t.FailNow()
+		}
+	}
+}
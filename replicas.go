@@ -0,0 +1,143 @@
+package gyr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// How long a replica that failed with a connection error is skipped by [ReplicatedDB].
+var ReplicaRetryAfter = 10 * time.Second
+
+type primaryKey struct{}
+
+// Make reads with ctx go to the primary of a [ReplicatedDB], for example to read a row right after writing it.
+func ReadFromPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func readsFromPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+// A primary database with read replicas. Selects are spread over the replicas round-robin, while other
+// statements, transactions and reads with [ReadFromPrimary] go to the primary. A replica failing with a
+// connection error is skipped for [ReplicaRetryAfter], and reads fall back to the primary when no replica is
+// available. It can be used wherever a [DBTX] is expected.
+type ReplicatedDB struct {
+	Primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+type replica struct {
+	db          *sql.DB
+	mx          sync.Mutex
+	failedUntil time.Time
+}
+
+func NewReplicatedDB(primary *sql.DB, replicas ...*sql.DB) *ReplicatedDB {
+	replicated := &ReplicatedDB{Primary: primary}
+	for _, db := range replicas {
+		replicated.replicas = append(replicated.replicas, &replica{db: db})
+	}
+	return replicated
+}
+
+func (db *ReplicatedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return db.Primary.ExecContext(ctx, query, args...)
+}
+
+func (db *ReplicatedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if !db.isRead(ctx, query) {
+		return db.Primary.QueryContext(ctx, query, args...)
+	}
+	for _, replica := range db.available() {
+		rows, err := replica.db.QueryContext(ctx, query, args...)
+		if err == nil || !isConnectionError(err) {
+			return rows, err
+		}
+		replica.fail(err)
+	}
+	return db.Primary.QueryContext(ctx, query, args...)
+}
+
+// Like QueryContext, but since the error of a [sql.Row] is only known when it is scanned, a failing replica is
+// not retried for this query. It is skipped by the following queries.
+func (db *ReplicatedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if !db.isRead(ctx, query) {
+		return db.Primary.QueryRowContext(ctx, query, args...)
+	}
+	if available := db.available(); len(available) > 0 {
+		return available[0].db.QueryRowContext(ctx, query, args...)
+	}
+	return db.Primary.QueryRowContext(ctx, query, args...)
+}
+
+// Transactions always run on the primary.
+func (db *ReplicatedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return db.Primary.BeginTx(ctx, opts)
+}
+
+// The driver of the primary, used by [DetectDialect].
+func (db *ReplicatedDB) Driver() driver.Driver {
+	return db.Primary.Driver()
+}
+
+func (db *ReplicatedDB) PingContext(ctx context.Context) error {
+	return db.Primary.PingContext(ctx)
+}
+
+// Close the primary and every replica.
+func (db *ReplicatedDB) Close() error {
+	errs := []error{db.Primary.Close()}
+	for _, replica := range db.replicas {
+		errs = append(errs, replica.db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (db *ReplicatedDB) isRead(ctx context.Context, query string) bool {
+	if len(db.replicas) == 0 || readsFromPrimary(ctx) {
+		return false
+	}
+	keyword, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	return strings.EqualFold(keyword, "select")
+}
+
+// The replicas that haven't failed recently, starting with the next one in turn.
+func (db *ReplicatedDB) available() []*replica {
+	start := int(db.next.Add(1) - 1)
+	now := time.Now()
+	available := make([]*replica, 0, len(db.replicas))
+	for i := range db.replicas {
+		replica := db.replicas[(start+i)%len(db.replicas)]
+		replica.mx.Lock()
+		healthy := now.After(replica.failedUntil)
+		replica.mx.Unlock()
+		if healthy {
+			available = append(available, replica)
+		}
+	}
+	return available
+}
+
+func (replica *replica) fail(err error) {
+	Logger().Warn("Replica failed, skipping it", "component", "sql", "error", err, "retry_after", ReplicaRetryAfter)
+	replica.mx.Lock()
+	defer replica.mx.Unlock()
+	replica.failedUntil = time.Now().Add(ReplicaRetryAfter)
+}
+
+// Errors meaning the database couldn't be reached, as opposed to errors in the query.
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr)
+}
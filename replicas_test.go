@@ -0,0 +1,62 @@
+package gyr
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestReplicatedDB(t *testing.T) {
+	primary := openNamedFakeDB("primary")
+	first, second := openNamedFakeDB("first"), openNamedFakeDB("second")
+	db := NewReplicatedDB(primary, first, second)
+	defer db.Close()
+	ctx := context.Background()
+
+	for range 3 {
+		rows, err := db.QueryContext(ctx, "select * from users")
+		if err != nil {
+			t.Logf("Query failed: %v\n", err)
+			t.FailNow()
+		}
+		rows.Close()
+	}
+	rows, _ := db.QueryContext(ReadFromPrimary(ctx), "select * from users")
+	rows.Close()
+	rows, _ = db.QueryContext(ctx, "insert into users (name) values (?) returning id", "a")
+	rows.Close()
+	db.ExecContext(ctx, "update users set name = ?", "b")
+
+	expected := []string{
+		"first: select * from users",
+		"second: select * from users",
+		"first: select * from users",
+		"primary: select * from users",
+		"primary: insert into users (name) values (?) returning id",
+	}
+	if queried := testDriver.queries(); !slices.Equal(queried, expected) {
+		t.Logf("Expected queries %v, got %v\n", expected, queried)
+		t.FailNow()
+	}
+
+	t.Run("fallback", func(t *testing.T) {
+		testDriver.mx.Lock()
+		testDriver.queried = nil
+		testDriver.mx.Unlock()
+		down := openNamedFakeDB("down")
+		db := NewReplicatedDB(primary, down)
+		for range 2 {
+			rows, err := db.QueryContext(ctx, "select * from users")
+			if err != nil {
+				t.Logf("Expected fallback to primary, got %v\n", err)
+				t.FailNow()
+			}
+			rows.Close()
+		}
+		expected := []string{"primary: select * from users", "primary: select * from users"}
+		if queried := testDriver.queries(); !slices.Equal(queried, expected) {
+			t.Logf("Expected queries %v, got %v\n", expected, queried)
+			t.FailNow()
+		}
+	})
+}
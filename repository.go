@@ -0,0 +1,294 @@
+package gyr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Returned by [Repository] when no row has the requested primary key.
+var ErrEntityNotFound = errors.New("entity not found")
+
+// Returned by [Repository.FindWhere] for a column that isn't a column of the entity.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// Most ids in the in list of a statement made by [Repository.DeleteByIDs], to stay below the parameter limits
+// of databases.
+var BulkBatchSize = 500
+
+type RepositorySettings struct {
+	// How long entities read by FindByID and FindWhere are cached. 0 disables caching.
+	CacheTTL time.Duration
+	// Maximum number of cached lookups of each kind. 0 means no limit.
+	CacheMaxEntries int
+}
+
+func DefaultRepositorySettings() RepositorySettings {
+	return RepositorySettings{
+		CacheMaxEntries: 1000,
+	}
+}
+
+// Cache the entities read by FindByID and FindWhere for ttl. The cache is cleared when the repository inserts,
+// updates or deletes an entity, so writes made outside of it are only seen when the cached entries expire.
+// Repositories created with a *sql.Tx don't cache, since what they read may be rolled back.
+func RepositoryCache(ttl time.Duration) func(*RepositorySettings) {
+	return func(rs *RepositorySettings) {
+		rs.CacheTTL = ttl
+	}
+}
+
+func RepositoryCacheMaxEntries(max int) func(*RepositorySettings) {
+	return func(rs *RepositorySettings) {
+		rs.CacheMaxEntries = max
+	}
+}
+
+// Loads and stores entities registered with [RegisterEntity]. Fields are matched with columns using their
+// gyr_column tags.
+type Repository[EntityType any] struct {
+	Settings RepositorySettings
+	db       DBTX
+	metadata EntityMetadata
+	// Index of the field for each column of the entity.
+	fields map[string]int
+	// Set when caching is enabled.
+	byID  *Cache[string, EntityType]
+	where *Cache[string, []EntityType]
+}
+
+func NewRepository[EntityType any](db DBTX, settings ...SettingsFunc[RepositorySettings]) (*Repository[EntityType], error) {
+	repoSettings := DefaultRepositorySettings()
+	for _, setting := range settings {
+		setting(&repoSettings)
+	}
+	metadata, err := getEntityMetadata[EntityType]()
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(metadata.Columns, metadata.PrimaryKey) {
+		return nil, fmt.Errorf("primary key %s is not a column of %s", metadata.PrimaryKey, metadata.Table)
+	}
+	repo := &Repository[EntityType]{Settings: repoSettings, db: db, metadata: metadata, fields: make(map[string]int)}
+	// In a transaction of the caller, entities could be cached before the changes to them are committed.
+	if _, canBegin := db.(transactionBeginner); repoSettings.CacheTTL > 0 && canBegin {
+		cacheSettings := []SettingsFunc[CacheSettings]{CacheTTL(repoSettings.CacheTTL), CacheMaxEntries(repoSettings.CacheMaxEntries)}
+		repo.byID = NewCache[string, EntityType](cacheSettings...)
+		repo.where = NewCache[string, []EntityType](cacheSettings...)
+	}
+	for i, fieldIndex := range columnFieldIndexes(reflect.TypeFor[EntityType](), metadata.Columns) {
+		if fieldIndex == -1 {
+			return nil, fmt.Errorf("column %s of %s has no field", metadata.Columns[i], metadata.Table)
+		}
+		repo.fields[metadata.Columns[i]] = fieldIndex
+	}
+	return repo, nil
+}
+
+// The entities matching the filters of listQuery, sorted and paginated.
+func (repo *Repository[EntityType]) FindAll(ctx context.Context, listQuery ListQuery) ([]EntityType, error) {
+	query, args := ApplyListQuery(NewQuery[EntityType](), listQuery)
+	return Fetch[EntityType](ctx, repo.db, query, args...)
+}
+
+// The entity with the primary key id, or [ErrEntityNotFound].
+func (repo *Repository[EntityType]) FindByID(ctx context.Context, id any) (EntityType, error) {
+	if repo.byID == nil {
+		return repo.findByID(ctx, id)
+	}
+	return repo.byID.GetOrCompute(fmt.Sprint(id), func() (EntityType, error) {
+		return repo.findByID(ctx, id)
+	})
+}
+
+func (repo *Repository[EntityType]) findByID(ctx context.Context, id any) (EntityType, error) {
+	query := NewQuery[EntityType]().SelectAll().Where(repo.metadata.PrimaryKey).EqualsVar().Query()
+	entity, err := FetchOne[EntityType](ctx, repo.db, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity, fmt.Errorf("%w: %s %v", ErrEntityNotFound, repo.metadata.Table, id)
+	}
+	return entity, err
+}
+
+// The entities where column equals value. column must be one of the columns of the entity, or
+// [ErrUnknownColumn] is returned.
+func (repo *Repository[EntityType]) FindWhere(ctx context.Context, column string, value any) ([]EntityType, error) {
+	if !slices.Contains(repo.metadata.Columns, column) {
+		return nil, fmt.Errorf("%w: %s has no column %q", ErrUnknownColumn, repo.metadata.Table, column)
+	}
+	if repo.where == nil {
+		return repo.findWhere(ctx, column, value)
+	}
+	entities, err := repo.where.GetOrCompute(fmt.Sprintf("%s=%v", column, value), func() ([]EntityType, error) {
+		return repo.findWhere(ctx, column, value)
+	})
+	// Callers may modify the slice, which would change the cached entities.
+	return slices.Clone(entities), err
+}
+
+func (repo *Repository[EntityType]) findWhere(ctx context.Context, column string, value any) ([]EntityType, error) {
+	query := NewQuery[EntityType]().SelectAll().Where(column).EqualsVar().Query()
+	return Fetch[EntityType](ctx, repo.db, query, value)
+}
+
+// Insert entity. A zero primary key is left out so the database can generate it, and is then set from the
+// id reported by the driver when there is one.
+func (repo *Repository[EntityType]) Insert(ctx context.Context, entity *EntityType) error {
+	value := reflect.ValueOf(entity).Elem()
+	primaryKey := value.Field(repo.fields[repo.metadata.PrimaryKey])
+	columns := repo.metadata.Columns
+	if primaryKey.IsZero() {
+		columns = slices.DeleteFunc(slices.Clone(columns), func(column string) bool { return column == repo.metadata.PrimaryKey })
+	}
+
+	query := NewQuery[EntityType]().Insert(columns).AddValue().Query()
+	result, err := Execute(ctx, repo.db, query, repo.values(value, columns)...)
+	if err != nil {
+		return err
+	}
+	repo.invalidate(nil)
+	if primaryKey.IsZero() && primaryKey.CanInt() {
+		if id, err := result.LastInsertId(); err == nil {
+			primaryKey.SetInt(id)
+		}
+	}
+	return nil
+}
+
+// Update every column of the row with the primary key of entity, or return [ErrEntityNotFound].
+func (repo *Repository[EntityType]) Update(ctx context.Context, entity EntityType) error {
+	value := reflect.ValueOf(&entity).Elem()
+	columns := slices.DeleteFunc(slices.Clone(repo.metadata.Columns), func(column string) bool { return column == repo.metadata.PrimaryKey })
+	query := NewQuery[EntityType]().Update(columns).Where(repo.metadata.PrimaryKey).EqualsVar().Query()
+	id := value.Field(repo.fields[repo.metadata.PrimaryKey]).Interface()
+	result, err := Execute(ctx, repo.db, query, append(repo.values(value, columns), id)...)
+	if err != nil {
+		return err
+	}
+	repo.invalidate(id)
+	return repo.requireAffected(result, id)
+}
+
+// Delete the row with the primary key id, or return [ErrEntityNotFound].
+func (repo *Repository[EntityType]) Delete(ctx context.Context, id any) error {
+	query := NewQuery[EntityType]().Delete().Where(repo.metadata.PrimaryKey).EqualsVar().Query()
+	result, err := Execute(ctx, repo.db, query, id)
+	if err != nil {
+		return err
+	}
+	repo.invalidate(id)
+	return repo.requireAffected(result, id)
+}
+
+// Update every entity in one transaction, with one statement per entity. Nothing is updated if an entity
+// doesn't exist, in which case [ErrEntityNotFound] is returned. When the repository was created with a
+// *sql.Tx the statements run in it and committing is left to the caller.
+func (repo *Repository[EntityType]) UpdateAll(ctx context.Context, entities []EntityType) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	db, commit, rollback, err := repo.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback()
+
+	columns := slices.DeleteFunc(slices.Clone(repo.metadata.Columns), func(column string) bool { return column == repo.metadata.PrimaryKey })
+	query := NewQuery[EntityType]().Update(columns).Where(repo.metadata.PrimaryKey).EqualsVar().Query()
+	for i := range entities {
+		value := reflect.ValueOf(&entities[i]).Elem()
+		id := value.Field(repo.fields[repo.metadata.PrimaryKey]).Interface()
+		result, err := Execute(ctx, db, query, append(repo.values(value, columns), id)...)
+		if err != nil {
+			return err
+		}
+		if err := repo.requireAffected(result, id); err != nil {
+			return err
+		}
+	}
+	if err := commit(); err != nil {
+		return err
+	}
+	repo.invalidate(nil)
+	if repo.byID != nil {
+		repo.byID.Clear()
+	}
+	return nil
+}
+
+// Delete the rows with the primary keys in ids, in batches of [BulkBatchSize], and return how many were
+// deleted. Ids without a row are ignored.
+func (repo *Repository[EntityType]) DeleteByIDs(ctx context.Context, ids []any) (int64, error) {
+	var deleted int64
+	for batch := range slices.Chunk(ids, BulkBatchSize) {
+		query := NewQuery[EntityType]().Delete().Where(repo.metadata.PrimaryKey).InVars(len(batch)).Query()
+		result, err := Execute(ctx, repo.db, query, batch...)
+		if err != nil {
+			return deleted, err
+		}
+		for _, id := range batch {
+			repo.invalidate(id)
+		}
+		if affected, err := result.RowsAffected(); err == nil {
+			deleted += affected
+		}
+	}
+	return deleted, nil
+}
+
+// Begin a transaction on the database of the repository. Without a way to begin one, such as when the
+// repository was created with a *sql.Tx, statements run directly on it and commit does nothing.
+func (repo *Repository[EntityType]) begin(ctx context.Context) (db DBTX, commit func() error, rollback func(), err error) {
+	beginner, canBegin := repo.db.(transactionBeginner)
+	if !canBegin {
+		return repo.db, func() error { return nil }, func() {}, nil
+	}
+	tx, err := beginner.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return tx, tx.Commit, func() { tx.Rollback() }, nil
+}
+
+// Remove the cached entity with the primary key id, if not nil, and every cached FindWhere result since any
+// of them could include a changed entity.
+func (repo *Repository[EntityType]) invalidate(id any) {
+	if repo.byID == nil {
+		return
+	}
+	if id != nil {
+		repo.byID.Delete(fmt.Sprint(id))
+	}
+	repo.where.Clear()
+}
+
+func (repo *Repository[EntityType]) values(value reflect.Value, columns []string) []any {
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		values[i] = value.Field(repo.fields[column]).Interface()
+	}
+	return values
+}
+
+func (repo *Repository[EntityType]) requireAffected(result sql.Result, id any) error {
+	affected, err := result.RowsAffected()
+	if err == nil && affected == 0 {
+		return fmt.Errorf("%w: %s %v", ErrEntityNotFound, repo.metadata.Table, id)
+	}
+	return nil
+}
+
+// Set the primary key field of entity to id, converting it to the type of the field.
+func (repo *Repository[EntityType]) setID(entity *EntityType, id any) error {
+	field := reflect.ValueOf(entity).Elem().Field(repo.fields[repo.metadata.PrimaryKey])
+	idValue := reflect.ValueOf(id)
+	if !idValue.IsValid() || !idValue.CanConvert(field.Type()) || (idValue.Kind() == reflect.String) != (field.Kind() == reflect.String) {
+		return fmt.Errorf("can not use %v as primary key of %s", id, repo.metadata.Table)
+	}
+	field.Set(idValue.Convert(field.Type()))
+	return nil
+}
@@ -0,0 +1,224 @@
+package gyr
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+type repoUser struct {
+	ID   int64  `gyr_column:"id" json:"id"`
+	Name string `gyr_column:"name" json:"name" validate:"required"`
+}
+
+func newUserRepository(t *testing.T) *Repository[repoUser] {
+	RegisterEntity[repoUser](EntityMetadata{Table: "users"})
+	repo, err := NewRepository[repoUser](openFakeDB())
+	if err != nil {
+		t.Logf("Failed creating repository: %v\n", err)
+		t.FailNow()
+	}
+	return repo
+}
+
+func TestRepository(t *testing.T) {
+	repo := newUserRepository(t)
+	ctx := context.Background()
+	testDriver.respondColumns("select id, name from users where id = ?", []string{"id", "name"}, []driver.Value{int64(1), "kalle"})
+
+	user, err := repo.FindByID(ctx, 1)
+	if err != nil || user != (repoUser{ID: 1, Name: "kalle"}) {
+		t.Logf("Received %+v (%v)\n", user, err)
+		t.FailNow()
+	}
+	if _, err := repo.FindWhere(ctx, "name", "kalle"); err != nil {
+		t.Logf("FindWhere failed: %v\n", err)
+		t.FailNow()
+	}
+
+	repo.Insert(ctx, &repoUser{Name: "lisa"})
+	repo.Update(ctx, repoUser{ID: 1, Name: "kalle"})
+	repo.Delete(ctx, 1)
+	expected := []string{
+		"insert into users (name) values (?)",
+		"update users set name = ? where id = ?",
+		"delete from users where id = ?",
+	}
+	if executed := testDriver.executed(); !slices.Equal(executed, expected) {
+		t.Logf("Expected %v. Received %v\n", expected, executed)
+		t.FailNow()
+	}
+}
+
+func TestRepositoryCache(t *testing.T) {
+	RegisterEntity[repoUser](EntityMetadata{Table: "users"})
+	repo, err := NewRepository[repoUser](openFakeDB(), RepositoryCache(time.Minute))
+	if err != nil {
+		t.Logf("Failed creating repository: %v\n", err)
+		t.FailNow()
+	}
+	ctx := context.Background()
+	testDriver.respondColumns("select id, name from users", []string{"id", "name"}, []driver.Value{int64(1), "kalle"})
+
+	countQueries := func() int { return len(testDriver.queries()) }
+	repo.FindByID(ctx, 1)
+	repo.FindByID(ctx, 1)
+	repo.FindWhere(ctx, "name", "kalle")
+	repo.FindWhere(ctx, "name", "kalle")
+	if queries := countQueries(); queries != 2 {
+		t.Logf("Expected 2 queries with caching. Received %d\n", queries)
+		t.FailNow()
+	}
+
+	repo.Update(ctx, repoUser{ID: 1, Name: "kalle"})
+	repo.FindByID(ctx, 1)
+	repo.FindWhere(ctx, "name", "kalle")
+	if queries := countQueries(); queries != 4 {
+		t.Logf("Expected update to invalidate the cache. Received %d queries\n", queries)
+		t.FailNow()
+	}
+}
+
+func TestRepositoryFindWhereUnknownColumn(t *testing.T) {
+	repo := newUserRepository(t)
+	if _, err := repo.FindWhere(context.Background(), "name = name or 1", 1); !errors.Is(err, ErrUnknownColumn) {
+		t.Logf("Expected ErrUnknownColumn. Received %v\n", err)
+		t.FailNow()
+	}
+	if queries := testDriver.queries(); len(queries) != 0 {
+		t.Logf("Expected no queries. Received %+v\n", queries)
+		t.FailNow()
+	}
+}
+
+func TestRepositoryCacheInTransaction(t *testing.T) {
+	RegisterEntity[repoUser](EntityMetadata{Table: "users"})
+	tx, err := openFakeDB().Begin()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	defer tx.Rollback()
+	repo, err := NewRepository[repoUser](tx, RepositoryCache(time.Minute))
+	if err != nil {
+		t.Logf("Failed creating repository: %v\n", err)
+		t.FailNow()
+	}
+	testDriver.respondColumns("select id, name from users", []string{"id", "name"}, []driver.Value{int64(1), "kalle"})
+
+	repo.FindByID(context.Background(), 1)
+	repo.FindByID(context.Background(), 1)
+	if queries := testDriver.queries(); len(queries) != 2 {
+		t.Logf("Expected no caching inside the transaction of the caller. Received %d queries\n", len(queries))
+		t.FailNow()
+	}
+}
+
+func TestRepositoryBulk(t *testing.T) {
+	repo := newUserRepository(t)
+	ctx := context.Background()
+	previousBatchSize := BulkBatchSize
+	BulkBatchSize = 2
+	defer func() { BulkBatchSize = previousBatchSize }()
+
+	if err := repo.UpdateAll(ctx, []repoUser{{ID: 1, Name: "kalle"}, {ID: 2, Name: "lisa"}}); err != nil {
+		t.Logf("UpdateAll failed: %v\n", err)
+		t.FailNow()
+	}
+	deleted, err := repo.DeleteByIDs(ctx, []any{1, 2, 3})
+	if err != nil || deleted != 2 {
+		t.Logf("Expected 2 deleted (the fake driver reports one row per statement). Received %d (%v)\n", deleted, err)
+		t.FailNow()
+	}
+	expected := []string{
+		"update users set name = ? where id = ?",
+		"update users set name = ? where id = ?",
+		"delete from users where id in (?,?)",
+		"delete from users where id in (?)",
+	}
+	if executed := testDriver.executed(); !slices.Equal(executed, expected) {
+		t.Logf("Expected %v. Received %v\n", expected, executed)
+		t.FailNow()
+	}
+}
+
+func TestRepositoryNotFound(t *testing.T) {
+	repo := newUserRepository(t)
+	if _, err := repo.FindByID(context.Background(), 7); !errors.Is(err, ErrEntityNotFound) {
+		t.Logf("Expected ErrEntityNotFound. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestMountCRUD(t *testing.T) {
+	repo := newUserRepository(t)
+	router := DefaultRouter()
+	MountCRUD(router, "/users", repo, CRUDListQuery(ListSortable("name")))
+	testDriver.respondColumns("select id, name from users", []string{"id", "name"}, []driver.Value{int64(1), "kalle"})
+
+	send := func(method string, path string, body any) *httptest.ResponseRecorder {
+		var payload bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&payload).Encode(body)
+		}
+		request := httptest.NewRequest(method, path, &payload)
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("list", func(t *testing.T) {
+		response := send(http.MethodGet, "/users?sort=-name&page=2&page_size=10", nil)
+		var page Page[repoUser]
+		json.Unmarshal(response.Body.Bytes(), &page)
+		if response.Code != http.StatusOK || len(page.Items) != 1 || page.Page != 2 || page.PageSize != 10 {
+			t.Logf("Unexpected list response %d %s\n", response.Code, response.Body.String())
+			t.FailNow()
+		}
+		if response := send(http.MethodGet, "/users?sort=id", nil); response.Code != http.StatusBadRequest {
+			t.Logf("Expected 400 for unsortable column. Received %d\n", response.Code)
+			t.FailNow()
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		if response := send(http.MethodGet, "/users/1", nil); response.Code != http.StatusOK || response.Body.String() != `{"id":1,"name":"kalle"}` {
+			t.Logf("Unexpected get response %d %s\n", response.Code, response.Body.String())
+			t.FailNow()
+		}
+	})
+
+	t.Run("create", func(t *testing.T) {
+		if response := send(http.MethodPost, "/users", map[string]any{"name": "lisa"}); response.Code != http.StatusCreated {
+			t.Logf("Expected 201. Received %d %s\n", response.Code, response.Body.String())
+			t.FailNow()
+		}
+		if response := send(http.MethodPost, "/users", map[string]any{}); response.Code != http.StatusBadRequest {
+			t.Logf("Expected 400 for invalid body. Received %d\n", response.Code)
+			t.FailNow()
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		response := send(http.MethodPut, "/users/1", map[string]any{"name": "kalle"})
+		if response.Code != http.StatusOK || response.Body.String() != `{"id":1,"name":"kalle"}` {
+			t.Logf("Unexpected update response %d %s\n", response.Code, response.Body.String())
+			t.FailNow()
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if response := send(http.MethodDelete, "/users/1", nil); response.Code != http.StatusNoContent {
+			t.Logf("Expected 204. Received %d\n", response.Code)
+			t.FailNow()
+		}
+	})
+}
@@ -1,18 +1,28 @@
 package gyr
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
+	"reflect"
+	"strconv"
 )
 
 type Response struct {
+	ctx     *Context
 	w       http.ResponseWriter
 	status  int
 	toWrite []byte
+	// Trailer names in the order they were declared, with the functions computing their values.
+	trailers     []string
+	trailerFuncs map[string]func() string
+	// Set when the body was written by a Stream.
+	streamed bool
 }
 
 func NewResponse(ctx *Context) *Response {
 	return &Response{
+		ctx:     ctx,
 		w:       ctx.writer,
 		status:  http.StatusOK,
 		toWrite: make([]byte, 0),
@@ -36,14 +46,23 @@ func (r *Response) Html(html string) *Response {
 	return r
 }
 
+// Write object as JSON. When the request selects fields with ?fields=id,name only those fields of a 2xx
+// response are written, see [Route.Fields].
 func (r *Response) Json(object any) *Response {
-	jsonBytes, err := json.Marshal(object)
+	fields, err := r.requestedFields()
 	if err != nil {
+		return r.ValidationError(err)
+	} else if fields != nil {
+		object = sparseFieldset(reflect.ValueOf(object), fields)
+	}
+	buf := bufferPool.Get()
+	defer putBuffer(buf)
+	if err := json.NewEncoder(buf).Encode(object); err != nil {
 		r.InternalError().Text("Internal Server Error")
 		return r
 	}
 	r.w.Header().Set("Content-Type", "application/json")
-	r.toWrite = append(r.toWrite, jsonBytes...)
+	r.toWrite = append(r.toWrite, bytes.TrimSuffix(buf.Bytes(), []byte("\n"))...)
 	return r
 }
 
@@ -62,12 +81,61 @@ func (r *Response) NoContent() *Response {
 	return r.Status(http.StatusNoContent)
 }
 
+// Redirect to location with 303 See Other, which makes the browser follow with a GET, as after a form post.
+func (r *Response) Redirect(location string) *Response {
+	return r.Status(http.StatusSeeOther).Header("Location", location)
+}
+
 func (r *Response) Header(name string, value string) *Response {
 	r.w.Header().Set(name, value)
 	return r
 }
 
+// Send a trailer with the response, after the body.
+func (r *Response) Trailer(name string, value string) *Response {
+	return r.TrailerFunc(name, func() string { return value })
+}
+
+// Send a trailer computed by value once the body has been written, for example a checksum of the body.
+func (r *Response) TrailerFunc(name string, value func() string) *Response {
+	name = http.CanonicalHeaderKey(name)
+	if r.trailerFuncs == nil {
+		r.trailerFuncs = make(map[string]func() string)
+	}
+	if _, declared := r.trailerFuncs[name]; !declared {
+		r.trailers = append(r.trailers, name)
+	}
+	r.trailerFuncs[name] = value
+	return r
+}
+
 func (r *Response) send() {
+	if r.streamed {
+		return
+	}
+	for _, name := range r.trailers {
+		r.w.Header().Add("Trailer", name)
+	}
+	body := r.toWrite
+	if r.ctx != nil && r.ctx.compressor != nil {
+		var release func()
+		body, release = r.ctx.compressor.encode(r.ctx.Request, r.w.Header(), r.status, r.toWrite)
+		defer release()
+	}
+	if r.ctx != nil && r.ctx.Request.Method == http.MethodHead {
+		// Headers are sent as for GET, without the body.
+		if r.w.Header().Get("Content-Type") == "" && len(body) > 0 {
+			r.w.Header().Set("Content-Type", http.DetectContentType(body))
+		}
+		if r.w.Header().Get("Content-Length") == "" {
+			r.w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+		r.w.WriteHeader(r.status)
+		return
+	}
 	r.w.WriteHeader(r.status)
-	r.w.Write(r.toWrite)
+	r.w.Write(body)
+	for _, name := range r.trailers {
+		r.w.Header().Set(name, r.trailerFuncs[name]())
+	}
 }
@@ -0,0 +1,72 @@
+package gyr_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestTrailersAndInformationalResponses(t *testing.T) {
+	router := defaultTestRouter()
+	router.Path("/export").Get(func(ctx *gyr.Context) *gyr.Response {
+		ctx.EarlyHints("</app.css>; rel=preload; as=style")
+		ctx.Informational(http.StatusProcessing, nil)
+		body := "exported rows"
+		return ctx.Response().Text(body).TrailerFunc("X-Checksum", func() string {
+			sum := sha256.Sum256([]byte(body))
+			return hex.EncodeToString(sum[:])
+		})
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	var informational []int
+	var earlyLinks []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			informational = append(informational, code)
+			if code == http.StatusEarlyHints {
+				earlyLinks = header.Values("Link")
+			}
+			return nil
+		},
+	}
+	request, _ := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, server.URL+"/export", nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+
+	sum := sha256.Sum256(body)
+	if response.Trailer.Get("X-Checksum") != hex.EncodeToString(sum[:]) {
+		t.Logf("Expected checksum trailer. Received %v\n", response.Trailer)
+		t.FailNow()
+	}
+	if len(informational) != 2 || informational[0] != http.StatusEarlyHints || informational[1] != http.StatusProcessing {
+		t.Logf("Expected 103 and 102 before the response. Received %v\n", informational)
+		t.FailNow()
+	}
+	if len(earlyLinks) != 1 || earlyLinks[0] != "</app.css>; rel=preload; as=style" {
+		t.Logf("Expected Link header with early hints. Received %v\n", earlyLinks)
+		t.FailNow()
+	}
+}
+
+func TestInformationalRejectsFinalStatus(t *testing.T) {
+	ctx := gyr.CreateContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if err := ctx.Informational(http.StatusOK, nil); err == nil {
+		t.Log("Expected error for non-informational status")
+		t.FailNow()
+	}
+}
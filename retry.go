@@ -0,0 +1,116 @@
+package gyr
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// How [Retry] spaces out and limits attempts.
+type RetryPolicy struct {
+	// Total number of attempts, including the first. Values below 1 are treated as 1.
+	MaxAttempts int
+	// Delay before the first retry. Doubled for each following retry up to MaxBackoff, without a limit when
+	// MaxBackoff is 0.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Fraction of each delay that is randomized, between 0 and 1. 0.2 gives delays within ±20% of the backoff.
+	Jitter float64
+	// Decides if an error is worth retrying. All errors are retried when nil. Errors wrapped with
+	// [Permanent] are never retried.
+	Retryable func(error) bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     100 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (err *permanentError) Error() string {
+	return err.err.Error()
+}
+
+func (err *permanentError) Unwrap() error {
+	return err.err
+}
+
+// Mark err as not worth retrying, making [Retry] return it immediately.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// The delay before retry number retry, starting at 1.
+func (policy RetryPolicy) Delay(retry int) time.Duration {
+	delay := policy.Backoff
+	for i := 1; i < retry && (policy.MaxBackoff <= 0 || delay < policy.MaxBackoff); i++ {
+		if delay > math.MaxInt64/2 {
+			delay = math.MaxInt64
+			break
+		}
+		delay *= 2
+	}
+	if policy.MaxBackoff > 0 {
+		delay = min(delay, policy.MaxBackoff)
+	}
+	if policy.Jitter > 0 && delay > 0 {
+		spread := float64(delay) * min(policy.Jitter, 1)
+		if jittered := float64(delay) + spread*(2*rand.Float64()-1); jittered < math.MaxInt64 {
+			delay = time.Duration(jittered)
+		} else {
+			delay = math.MaxInt64
+		}
+	}
+	return delay
+}
+
+func (policy RetryPolicy) shouldRetry(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	return policy.Retryable == nil || policy.Retryable(err)
+}
+
+// Call fn until it succeeds, returns an error that isn't retryable or the attempts of policy run out. The last
+// error is returned, unwrapped from [Permanent]. Waiting between attempts stops early when ctx is done.
+func Retry(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	attempts := max(policy.MaxAttempts, 1)
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			timer := CurrentClock().NewTimer(policy.Delay(attempt - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return errors.Join(ctx.Err(), err)
+			case <-timer.C():
+			}
+		}
+
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		if !policy.shouldRetry(err) {
+			break
+		}
+	}
+
+	if permanent, isPermanent := err.(*permanentError); isPermanent {
+		return permanent.err
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package gyr_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestRetry(t *testing.T) {
+	policy := gyr.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	failure := errors.New("failure")
+
+	t.Run("Succeeds after retries", func(t *testing.T) {
+		calls := 0
+		err := gyr.Retry(context.Background(), policy, func() error {
+			calls++
+			if calls < 3 {
+				return failure
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Logf("Expected success after 3 calls. Received %v after %d\n", err, calls)
+			t.FailNow()
+		}
+	})
+
+	t.Run("Gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		err := gyr.Retry(context.Background(), policy, func() error {
+			calls++
+			return failure
+		})
+		if !errors.Is(err, failure) || calls != 3 {
+			t.Logf("Expected failure after 3 calls. Received %v after %d\n", err, calls)
+			t.FailNow()
+		}
+	})
+
+	t.Run("Stops on permanent errors", func(t *testing.T) {
+		calls := 0
+		err := gyr.Retry(context.Background(), policy, func() error {
+			calls++
+			return gyr.Permanent(failure)
+		})
+		if err != failure || calls != 1 {
+			t.Logf("Expected unwrapped failure after 1 call. Received %v after %d\n", err, calls)
+			t.FailNow()
+		}
+	})
+
+	t.Run("Classifies errors", func(t *testing.T) {
+		classified := policy
+		classified.Retryable = func(err error) bool { return !errors.Is(err, failure) }
+		calls := 0
+		gyr.Retry(context.Background(), classified, func() error {
+			calls++
+			return failure
+		})
+		if calls != 1 {
+			t.Logf("Expected 1 call. Received %d\n", calls)
+			t.FailNow()
+		}
+	})
+
+	t.Run("Waits for the backoff", func(t *testing.T) {
+		clock := gyr.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		gyr.SetClock(clock)
+		defer gyr.SetClock(nil)
+
+		var calls atomic.Int32
+		done := make(chan error)
+		go func() {
+			done <- gyr.Retry(context.Background(), gyr.RetryPolicy{MaxAttempts: 2, Backoff: time.Hour}, func() error {
+				if calls.Add(1) < 2 {
+					return failure
+				}
+				return nil
+			})
+		}()
+		for !clock.Waiting() {
+			time.Sleep(time.Millisecond)
+		}
+		if calls.Load() != 1 {
+			t.Logf("Expected 1 call before the backoff. Received %d\n", calls.Load())
+			t.FailNow()
+		}
+		clock.Advance(time.Hour)
+		if err := <-done; err != nil || calls.Load() != 2 {
+			t.Logf("Expected success after the backoff. Received %v after %d\n", err, calls.Load())
+			t.FailNow()
+		}
+	})
+
+	t.Run("Stops waiting when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		slow := gyr.RetryPolicy{MaxAttempts: 2, Backoff: time.Hour}
+		err := gyr.Retry(ctx, slow, func() error { return failure })
+		if !errors.Is(err, context.Canceled) || !errors.Is(err, failure) {
+			t.Logf("Expected canceled and failure. Received %v\n", err)
+			t.FailNow()
+		}
+	})
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := gyr.RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	for i, delay := range expected {
+		if received := policy.Delay(i + 1); received != delay {
+			t.Logf("Retry %d: expected %v. Received %v\n", i+1, delay, received)
+			t.FailNow()
+		}
+	}
+
+	uncapped := gyr.RetryPolicy{Backoff: 100 * time.Millisecond}
+	for i, delay := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond} {
+		if received := uncapped.Delay(i + 1); received != delay {
+			t.Logf("Uncapped retry %d: expected %v. Received %v\n", i+1, delay, received)
+			t.FailNow()
+		}
+	}
+	if received := uncapped.Delay(100); received != math.MaxInt64 {
+		t.Logf("Expected the delay to stop at the largest duration. Received %v\n", received)
+		t.FailNow()
+	}
+
+	policy.Jitter = 0.5
+	for range 20 {
+		if received := policy.Delay(1); received < 50*time.Millisecond || received > 150*time.Millisecond {
+			t.Logf("Expected jittered delay within 50ms-150ms. Received %v\n", received)
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package gyr
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Returned by [Migrator.Rollback] when no migration has been applied.
+var ErrNothingToRollback = errors.New("no applied migration to roll back")
+
+// State of a single migration as reported by [Migrator.Status].
+type MigrationStatus struct {
+	Version   string
+	Path      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Get every known migration, from files and registered Go migrations, and whether it has been applied.
+func (mig *Migrator) Status() ([]MigrationStatus, error) {
+	if err := mig.createMigrationTable(); err != nil {
+		return nil, err
+	}
+	history, err := mig.History()
+	if err != nil {
+		return nil, err
+	}
+
+	watermark := legacyWatermark(history)
+	migrations := collectMigrations(getSqlFilenames(mig.Settings.Directory), mig.goMigrations)
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		statuses[i] = MigrationStatus{Version: m.version, Path: m.path}
+		statuses[i].Applied = watermark != "" && compareVersions(m.version, watermark) <= 0
+		for _, record := range history {
+			if record.Success && record.Version == m.version {
+				statuses[i].Applied = true
+				statuses[i].AppliedAt = record.AppliedAt
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// Revert the most recently applied migration by running its .down.sql file and removing it from the history.
+// Go migrations can not be rolled back.
+func (mig *Migrator) Rollback() error {
+	if err := mig.createMigrationTable(); err != nil {
+		return err
+	}
+	if err := mig.getMigrationVersion(); err != nil {
+		return err
+	}
+	if mig.LastVersion == "" {
+		return ErrNothingToRollback
+	}
+
+	history, err := mig.History()
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(history, func(record MigrationRecord) bool {
+		return record.Success && record.Version == mig.LastVersion
+	})
+	if index == -1 {
+		return ErrNothingToRollback
+	}
+	path := history[index].Path
+	if mig.isGoMigration(path) {
+		return fmt.Errorf("go migration %s can not be rolled back", path)
+	}
+	downPath := filepath.FromSlash(strings.TrimSuffix(path, ".sql") + downMigrationSuffix)
+
+	transaction, err := mig.beginTransaction()
+	if err != nil {
+		return err
+	}
+	defer mig.rollbackTransaction(transaction)
+	if err := mig.executeQueriesInFile(downPath, transaction); err != nil {
+		mig.logger.Error("Error in rollback execution", "file", downPath, "error", err)
+		return err
+	}
+	const query = "delete from gyr_migrator_version_history where version = ?"
+	if _, err := transaction.ExecContext(mig.Settings.Context, mig.Settings.Dialect.Rebind(query), mig.LastVersion); err != nil {
+		return err
+	}
+	// A row from before the audit columns also stood for every earlier version, so one is kept for the version
+	// before it.
+	previous, isLegacy := migration{}, history[index].AppliedAt.IsZero()
+	if isLegacy {
+		for _, m := range collectMigrations(getSqlFilenames(mig.Settings.Directory), mig.goMigrations) {
+			if compareVersions(m.version, mig.LastVersion) < 0 {
+				previous = m
+			}
+		}
+	}
+	if previous.version != "" {
+		const insert = "insert into gyr_migrator_version_history (version, path) values (?, ?)"
+		if _, err := transaction.ExecContext(mig.Settings.Context, mig.Settings.Dialect.Rebind(insert), previous.version, filepath.ToSlash(previous.path)); err != nil {
+			return err
+		}
+	}
+	if err := mig.commitTransaction(transaction); err != nil {
+		return err
+	}
+
+	mig.logger.Info("Rolled back version", "version", mig.LastVersion)
+	mig.applied = slices.DeleteFunc(mig.applied, func(version string) bool {
+		return version == mig.LastVersion
+	})
+	if isLegacy {
+		mig.watermark = previous.version
+		if previous.version != "" {
+			mig.applied = append(mig.applied, previous.version)
+		}
+	}
+	mig.LastVersion = ""
+	if len(mig.applied) > 0 {
+		mig.LastVersion = slices.MaxFunc(mig.applied, compareVersions)
+	}
+	return nil
+}
+
+// The most recent version of the history rows written before the audit columns existed, which stand for every
+// version up to their own.
+func legacyWatermark(history []MigrationRecord) string {
+	watermark := ""
+	for _, record := range history {
+		if record.AppliedAt.IsZero() && record.Success && (watermark == "" || compareVersions(record.Version, watermark) > 0) {
+			watermark = record.Version
+		}
+	}
+	return watermark
+}
@@ -0,0 +1,60 @@
+package gyr
+
+import (
+	"database/sql/driver"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRollback(t *testing.T) {
+	db := openFakeDB()
+	testDriver.respond("select version, success from gyr_migrator_version_history", []driver.Value{"0.0.1", true}, []driver.Value{"0.0.2", true})
+	testDriver.respond("select version, path, applied_at",
+		[]driver.Value{"0.0.1", "test_files/migrations/0.0.1_init.sql", time.Now(), int64(1), true, nil},
+		[]driver.Value{"0.0.2", "test_files/migrations/nested/0.0.2_age.sql", time.Now(), int64(1), true, nil},
+	)
+	migrator := NewMigrator(db, MigrationLogOutput(os.Stderr))
+	if err := migrator.Rollback(); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if !slices.Contains(testDriver.executed(), "alter table users drop column age;") {
+		t.Logf("Down migration was not executed. Executed %+v\n", testDriver.executed())
+		t.FailNow()
+	}
+	if migrator.LastVersion != "0.0.1" {
+		t.Logf("Expected LastVersion 0.0.1. Received %s\n", migrator.LastVersion)
+		t.FailNow()
+	}
+}
+
+func TestRollbackNothingApplied(t *testing.T) {
+	migrator := NewMigrator(openFakeDB(), MigrationLogOutput(os.Stderr))
+	if err := migrator.Rollback(); !errors.Is(err, ErrNothingToRollback) {
+		t.Logf("Expected ErrNothingToRollback. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestRollbackLegacyHistory(t *testing.T) {
+	db := openFakeDB()
+	testDriver.respond("select version, success from gyr_migrator_version_history", []driver.Value{"0.0.2", nil})
+	testDriver.respond("select version, path, applied_at", []driver.Value{"0.0.2", "test_files/migrations/nested/0.0.2_age.sql", nil, nil, nil, nil})
+	migrator := NewMigrator(db, MigrationDirectory("test_files/migrations"), MigrationLogOutput(os.Stderr))
+	if err := migrator.Rollback(); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	// The rolled back row also stood for 0.0.1, which has to stay applied.
+	if kept := testDriver.executedWith("insert into gyr_migrator_version_history"); len(kept) != 1 || kept[0][0] != "0.0.1" {
+		t.Logf("Expected a row kept for 0.0.1. Received %+v\n", kept)
+		t.FailNow()
+	}
+	if migrator.LastVersion != "0.0.1" {
+		t.Logf("Expected LastVersion 0.0.1. Received %s\n", migrator.LastVersion)
+		t.FailNow()
+	}
+}
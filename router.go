@@ -9,10 +9,15 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"runtime"
+	"runtime/debug"
 	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"text/tabwriter"
 )
 
 type RouterMatchable interface {
@@ -24,66 +29,133 @@ type Handler func(*Context) *Response
 type Router struct {
 	routes      []RouterMatchable
 	middlewares []Handler
-	logger      *slog.Logger
+	// Built from routes on first lookup and reset when a route or group is added.
+	tree     atomic.Pointer[routeTree]
+	logger   *slog.Logger
+	recorder *DebugRecorder
+	// Prefixes of the groups created by Version.
+	versions           []string
+	versionNegotiation *VersionNegotiationSettings
+	methodOverride     *MethodOverrideSettings
+	redactor           *Redactor
+	background         *BackgroundPool
+	streams            *streamRegistry
+	accessLog          AccessLogSettings
+	// Files added by StaticDir, by their path relative to the directory.
+	assets      map[string]asset
+	maintenance atomic.Pointer[maintenance]
 	// Directories that will be ignored by HtmlDir() and StaticDir()
 	IgnoredDirectories []string
 }
 
 func DefaultRouter() *Router {
-	var logLevel slog.Level
-	if isGyrDebug() {
-		logLevel = slog.LevelDebug
-	} else {
-		logLevel = slog.LevelInfo
-	}
 	return &Router{
 		routes:      make([]RouterMatchable, 0),
 		middlewares: make([]Handler, 0),
-		logger:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel})),
+		logger:      Logger().With("component", "router"),
+		redactor:    NewRedactor(),
+		background:  NewBackgroundPool(),
+		streams:     newStreamRegistry(),
+		accessLog:   DefaultAccessLogSettings(),
 	}
 }
 
 func (router *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	router.logger.Info("Incoming request", "method", req.Method, "path", req.URL.Path)
+	req = router.negotiateVersion(req)
+	req = router.overrideMethod(req)
+	start := CurrentClock().Now()
+	requestCtx := WithLogAttrs(req.Context(), "method", req.Method, "path", req.URL.Path)
+	if id := req.Header.Get(RequestIDHeader); isValidRequestID(id) {
+		requestCtx = WithRequestID(requestCtx, id)
+	}
+	req = req.WithContext(requestCtx)
+	route := router.FindRoute(req.URL.Path)
+	accessLogger := router.accessLogger(route)
+	if accessLogger != nil {
+		router.logIncoming(accessLogger, req)
+	}
+
+	var recording *debugRecording
+	if router.recorder != nil && !router.recorder.isOwnPath(req.URL.Path) {
+		req, recording = router.recorder.begin(req)
+	}
 
 	context := CreateContext(w, req)
-	route := router.FindRoute(req.URL.Path)
+	context.background = router.background
+	context.streams = router.streams
 
 	var response *Response
 	defer func() {
+		if recovered := recover(); recovered != nil {
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			response = router.panicResponse(context, recovered)
+		}
+		defer context.runCleanups()
+		context.response = response
 		response.send()
-		router.logger.Info("Response sent", "status", response.status, "length", len(response.toWrite))
+		if accessLogger != nil {
+			router.logSent(accessLogger, context.Request, response, start)
+		}
+		if recording != nil {
+			router.recorder.finish(recording, route, response, router.redactor)
+		}
 	}()
 
+	if !isSafeRequestPath(req.URL.Path) {
+		response = context.Response().Status(http.StatusBadRequest).Text("400 - Bad Request")
+		return
+	}
+	if router.inMaintenance(req.URL.Path) {
+		response = serviceUnavailable(context, MaintenanceRetryAfter)
+		return
+	}
 	if route == nil {
 		response = context.Response().Status(http.StatusNotFound).Text("404 - Not Found")
 		return
 	}
 
-	if handler := route.handlers[req.Method]; handler != nil {
+	context.route = route
+	handler := route.handlers[req.Method]
+	if handler == nil && req.Method == http.MethodHead {
+		handler = route.handlers[http.MethodGet]
+	}
+	if handler == nil && req.Method == http.MethodOptions {
+		// Answered after the middleware so it can handle CORS preflight requests.
+		handler = func(ctx *Context) *Response {
+			return ctx.Response().Header("Allow", strings.Join(route.allowedMethods(), ", ")).NoContent()
+		}
+	}
+	if handler != nil {
 		if len(route.variables) > 0 {
 			extractVariablesIntoContext(route, context)
 		}
 
-		if len(route.middlewares) > 0 || len(router.middlewares) > 0 {
-			middlewares := make([]Handler, len(router.middlewares), len(router.middlewares)+len(route.middlewares))
-			copy(middlewares, router.middlewares)
-			middlewares = append(middlewares, route.middlewares...)
-
-			response = runMiddlewares(middlewares, context)
-			if response != nil {
-				return
+		response = runChain(route.middlewareChain(router.middlewares), func(ctx *Context) *Response {
+			response := handler(ctx)
+			if response == nil {
+				router.logger.Warn("Handler returned no response, creating a default response", "path", req.URL.Path)
+				response = NewResponse(ctx)
 			}
-		}
-
-		response = handler(context)
-		if response == nil {
-			router.logger.Warn("Handler returned no response, creating a default response", "path", req.URL.Path)
-			response = NewResponse(context)
-		}
+			return response
+		}, context)
 		return
 	}
-	response = context.Response().Status(http.StatusMethodNotAllowed).Text("405 - Method Not Allowed")
+	allow := strings.Join(route.allowedMethods(), ", ")
+	response = context.Response().Status(http.StatusMethodNotAllowed).Header("Allow", allow).Text("405 - Method Not Allowed")
+}
+
+// Response to a panic in a handler or middleware. The panic and stack trace are included when the [Profile]
+// has DebugErrors.
+func (router *Router) panicResponse(ctx *Context, recovered any) *Response {
+	stack := debug.Stack()
+	ctx.Logger().Error("Handler panicked", "panic", recovered, "stack", string(stack))
+	response := ctx.Response().InternalError()
+	if CurrentProfile().DebugErrors {
+		return response.Text(fmt.Sprintf("500 - Internal Server Error\n\npanic: %v\n\n%s", recovered, stack))
+	}
+	return response.Text("Internal Server Error")
 }
 
 func (router *Router) Middleware(middleware ...Handler) {
@@ -93,12 +165,14 @@ func (router *Router) Middleware(middleware ...Handler) {
 func (router *Router) Path(path string) *Route {
 	route := createRoute(path)
 	router.routes = append(router.routes, route)
+	router.tree.Store(nil)
 	return route
 }
 
 func (router *Router) Group(prefix string) *RouteGroup {
 	group := createGroup(prefix)
 	router.routes = append(router.routes, group)
+	router.tree.Store(nil)
 	return group
 }
 
@@ -119,7 +193,8 @@ func (router *Router) StaticDir(directory string) {
 		cleaned := strings.ReplaceAll(path, "\\", "/")
 		cleaned = strings.TrimPrefix(cleaned, directory)
 		cleaned = strings.TrimPrefix(cleaned, "/")
-		group.Path(cleaned).Get(staticFileHandler(router, path))
+		group.Path(cleaned).Get(staticFileHandler(router, directory, path))
+		router.addAsset(group, directory, cleaned, path)
 		router.logger.Info("Added static file", "file", path)
 		return nil
 	})
@@ -152,7 +227,11 @@ func (router *Router) HtmlFile(path string, file string) {
 }
 
 func (router *Router) FindRoute(path string) *Route {
-	return searchRoute(router.routes, path)
+	segments, ok := requestSegments(path)
+	if !ok {
+		return nil
+	}
+	return loadRouteTree(&router.tree, router.routes).find(segments)
 }
 
 func htmlFileHandler(router *Router, fpath string) Handler {
@@ -175,11 +254,14 @@ func htmlFileHandler(router *Router, fpath string) Handler {
 	}
 }
 
-func staticFileHandler(router *Router, fpath string) Handler {
+func staticFileHandler(router *Router, directory string, fpath string) Handler {
 	return func(ctx *Context) *Response {
-		file, err := os.Open(fpath)
+		file, err := openInDirectory(directory, fpath)
 		if errors.Is(err, os.ErrNotExist) {
-			return ctx.Response().Status(http.StatusNotFound).Text(fmt.Sprintf("404 %s not found", fpath))
+			return ctx.Response().Status(http.StatusNotFound).Text("404 - Not Found")
+		} else if errors.Is(err, errOutsideDirectory) {
+			router.logger.Warn("Refused static file outside of its directory", "file", fpath)
+			return ctx.Response().Status(http.StatusNotFound).Text("404 - Not Found")
 		} else if err != nil {
 			router.logger.Error("failed reading static file", "err", err)
 			return ctx.Response().InternalError().Text("Internal Server Error")
@@ -195,6 +277,47 @@ func staticFileHandler(router *Router, fpath string) Handler {
 	}
 }
 
+var errOutsideDirectory = errors.New("file is outside of its directory")
+
+// Open fpath, refusing it if it resolves to a file outside of directory, such as through a symlink that was
+// changed after the route was added.
+func openInDirectory(directory string, fpath string) (*os.File, error) {
+	root, err := filepath.EvalSymlinks(directory)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := filepath.EvalSymlinks(fpath)
+	if err != nil {
+		return nil, err
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, errOutsideDirectory
+	}
+	return os.Open(resolved)
+}
+
+// Reports whether a decoded request path is free of dot segments, NUL bytes and backslashes, which are only
+// ever sent to reach files outside of a served directory.
+func isSafeRequestPath(path string) bool {
+	if strings.ContainsAny(path, "\\\x00") {
+		return false
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
 // Create a [Response]-object based on the extension of the file.
 func responseBasedOnFileExtension(ctx *Context, fpath string, content string) *Response {
 	response := ctx.Response()
@@ -220,8 +343,10 @@ func responseBasedOnFileExtension(ctx *Context, fpath string, content string) *R
 
 func extractVariablesIntoContext(route *Route, ctx *Context) {
 	urlParts := strings.Split(ctx.Request.URL.Path, "/")
+	// Routes in groups only match the end of the path, after the group prefixes.
+	offset := len(urlParts) - len(strings.Split(route.Path, "/"))
 	for variableName, variableIndex := range route.variables {
-		value := urlParts[variableIndex]
+		value := urlParts[offset+variableIndex]
 
 		valueInt, err := strconv.Atoi(value)
 		if err == nil {
@@ -251,6 +376,11 @@ type Route struct {
 	handlers    map[string]Handler
 	middlewares []Handler
 	variables   map[string]int
+	meta        map[string]any
+	// Innermost group containing the route, nil for routes directly on the router.
+	group *RouteGroup
+	// Method of the handler added last, which requirements such as RequireScopes apply to.
+	lastMethod string
 }
 
 func createRoute(path string) *Route {
@@ -259,6 +389,7 @@ func createRoute(path string) *Route {
 		handlers:    make(map[string]Handler),
 		middlewares: make([]Handler, 0),
 		variables:   make(map[string]int),
+		meta:        make(map[string]any),
 	}
 	createPathRegex(route)
 	return route
@@ -314,20 +445,55 @@ func (route *Route) Patch(handler Handler) *Route {
 	return route.method(http.MethodPatch, handler)
 }
 
+// Attach configuration to the route for middlewares to read at dispatch time, for example required scopes
+// or a cache TTL. See [RouteMeta].
+func (route *Route) Set(key string, value any) *Route {
+	route.meta[key] = value
+	return route
+}
+
+// The values set on the route with [Route.Set]. The map must not be modified.
+func (route *Route) Meta() map[string]any {
+	return route.meta
+}
+
 func (route *Route) Middleware(middleware ...Handler) *Route {
 	route.middlewares = append(route.middlewares, middleware...)
 	return route
 }
 
+// The methods the route has handlers for, sorted, with OPTIONS and HEAD for GET routes which are answered
+// automatically.
+func (route *Route) allowedMethods() []string {
+	methods := make([]string, 0, len(route.handlers)+1)
+	for method := range route.handlers {
+		methods = append(methods, method)
+	}
+	if _, hasOptions := route.handlers[http.MethodOptions]; !hasOptions {
+		methods = append(methods, http.MethodOptions)
+	}
+	_, hasGet := route.handlers[http.MethodGet]
+	if _, hasHead := route.handlers[http.MethodHead]; hasGet && !hasHead {
+		methods = append(methods, http.MethodHead)
+	}
+	slices.Sort(methods)
+	return methods
+}
+
 func (route *Route) method(method string, handler Handler) *Route {
 	route.handlers[method] = handler
+	route.lastMethod = method
 	return route
 }
 
 type RouteGroup struct {
 	Prefix      string
 	middlewares []Handler
+	parent      *RouteGroup
 	routes      []RouterMatchable
+	tree        atomic.Pointer[routeTree]
+	// Set by Deprecated.
+	usage *deprecationUsage
 }
 
 func createGroup(prefix string) *RouteGroup {
@@ -348,45 +514,158 @@ func (group *RouteGroup) MatchesPath(path string) bool {
 
 func (group *RouteGroup) Path(path string) *Route {
 	route := createRoute(path)
-	route.middlewares = append(route.middlewares, group.middlewares...)
+	route.group = group
 	group.routes = append(group.routes, route)
+	group.tree.Store(nil)
 	return route
 }
 
 func (group *RouteGroup) Group(prefix string) *RouteGroup {
 	nestedGroup := createGroup(prefix)
+	nestedGroup.parent = group
 	group.routes = append(group.routes, nestedGroup)
+	group.tree.Store(nil)
 	return nestedGroup
 }
 
-// Must be called before any routes are added to the group or the routes added before the call won't have the middlewares.
+// Add middleware run for every route in the group and its nested groups, including routes added before the
+// call. Middleware of outer groups runs before that of nested groups, and both before that of the route.
 func (group *RouteGroup) Middleware(middleware ...Handler) *RouteGroup {
 	group.middlewares = append(group.middlewares, middleware...)
 	return group
 }
 
-func (group *RouteGroup) findInGroup(path string) *Route {
-	return searchRoute(group.routes, path)
+// A registered route as listed by [Router.Routes].
+type RouteInfo struct {
+	// Full path including the prefixes of the groups containing the route.
+	Path string
+	// Methods with a handler, sorted.
+	Methods []string
+	// Full prefix of the innermost group containing the route, empty for routes directly on the router.
+	Group string
+	// Number of middlewares run before the handlers, including those of the router.
+	Middlewares int
+	Route       *Route
+}
+
+// Every registered route, including those in groups, in registration order.
+func (router *Router) Routes() []RouteInfo {
+	routes := listRoutes("", router.routes)
+	for i := range routes {
+		routes[i].Middlewares += len(router.middlewares)
+	}
+	return routes
 }
 
-func searchRoute(haystack []RouterMatchable, path string) *Route {
-	var route *Route = nil
+// List the routes in haystack with their full paths, including group prefixes.
+func listRoutes(prefix string, haystack []RouterMatchable) []RouteInfo {
+	routes := make([]RouteInfo, 0)
 	for _, routeOrGroup := range haystack {
-		if routeOrGroup.MatchesPath(path) {
-			switch routeOrGroup := routeOrGroup.(type) {
-			case *Route:
-				route = routeOrGroup
-			case *RouteGroup:
-				strippedPath := strings.TrimPrefix(path, routeOrGroup.Prefix)
-				route = routeOrGroup.findInGroup(strippedPath)
-				if route == nil {
-					continue
-				}
+		switch routeOrGroup := routeOrGroup.(type) {
+		case *Route:
+			methods := make([]string, 0, len(routeOrGroup.handlers))
+			for method := range routeOrGroup.handlers {
+				methods = append(methods, method)
+			}
+			slices.Sort(methods)
+			path := prefix + routeOrGroup.Path
+			if prefix != "" && !strings.HasPrefix(routeOrGroup.Path, "/") {
+				path = prefix + "/" + routeOrGroup.Path
 			}
-			break
+			routes = append(routes, RouteInfo{Path: path, Methods: methods, Group: prefix, Middlewares: len(routeOrGroup.middlewareChain(nil)), Route: routeOrGroup})
+		case *RouteGroup:
+			routes = append(routes, listRoutes(prefix+routeOrGroup.Prefix, routeOrGroup.routes)...)
 		}
 	}
-	return route
+	return routes
+}
+
+// Write a table of every route and method with its group, number of middlewares and handler function.
+func (router *Router) PrintRoutes(w io.Writer) error {
+	writer := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "METHOD\tPATH\tGROUP\tMIDDLEWARES\tHANDLER")
+	for _, route := range router.Routes() {
+		group := route.Group
+		if group == "" {
+			group = "-"
+		}
+		for _, method := range route.Methods {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%s\n", method, route.Path, group, route.Middlewares, handlerName(route.Route.handlers[method]))
+		}
+	}
+	return writer.Flush()
+}
+
+func handlerName(handler Handler) string {
+	function := runtime.FuncForPC(reflect.ValueOf(handler).Pointer())
+	if function == nil {
+		return "unknown"
+	}
+	return function.Name()
+}
+
+// The middleware run before the handlers of the route: routerMiddlewares, then those of the groups containing
+// the route from the outermost in, then those of the route itself.
+func (route *Route) middlewareChain(routerMiddlewares []Handler) []Handler {
+	var groups []*RouteGroup
+	for group := route.group; group != nil; group = group.parent {
+		groups = append(groups, group)
+	}
+	if len(groups) == 0 && len(routerMiddlewares) == 0 {
+		return route.middlewares
+	}
+	middlewares := slices.Clone(routerMiddlewares)
+	for _, group := range slices.Backward(groups) {
+		middlewares = append(middlewares, group.middlewares...)
+	}
+	return append(middlewares, route.middlewares...)
+}
+
+// Middleware wrapping the rest of the chain, see [Wrap]. next runs the remaining middleware and the handler
+// and returns their response.
+type Wrapper func(ctx *Context, next Handler) *Response
+
+// Turn wrapper into middleware that can run code both before and after the rest of the chain, such as
+// timing the handler or changing its response. Returning without calling next halts the chain like any other
+// middleware. next must be called at most once.
+//
+//	router.Middleware(gyr.Wrap(func(ctx *gyr.Context, next gyr.Handler) *gyr.Response {
+//		start := time.Now()
+//		response := next(ctx)
+//		return response.Header("Server-Timing", fmt.Sprintf("app;dur=%d", time.Since(start).Milliseconds()))
+//	}))
+func Wrap(wrapper Wrapper) Handler {
+	return func(ctx *Context) *Response {
+		rest := ctx.next
+		var inner *Response
+		called := false
+		response := wrapper(ctx, func(*Context) *Response {
+			called = true
+			if rest != nil {
+				inner = rest()
+			}
+			return inner
+		})
+		if response == nil && called {
+			return inner
+		}
+		return response
+	}
+}
+
+// Run middlewares and then handler, halting at the first middleware returning a response. The rest of the
+// chain is kept in the context for middleware created with [Wrap].
+func runChain(middlewares []Handler, handler Handler, ctx *Context) *Response {
+	for i, middleware := range middlewares {
+		ctx.next = func() *Response {
+			return runChain(middlewares[i+1:], handler, ctx)
+		}
+		if response := middleware(ctx); response != nil {
+			return response
+		}
+	}
+	ctx.next = nil
+	return handler(ctx)
 }
 
 // Non-nil return value means execution should halt and response be sent.
@@ -5,25 +5,23 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
 )
 
 func sendRequest(router *gyr.Router, req *http.Request) *httptest.ResponseRecorder {
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-	return w
+	return gyrtest.Send(router, req)
 }
 
 func createPayload(object any) *bytes.Reader {
-	marshaledPayload, err := json.Marshal(object)
-	if err != nil {
-		return nil
-	}
-	return bytes.NewReader(marshaledPayload)
+	return gyrtest.Payload(object)
 }
 
 func defaultTestRouter() *gyr.Router {
@@ -71,6 +69,35 @@ func TestRoutingMethodNotAllowed(t *testing.T) {
 			t.FailNow()
 		}
 	})
+
+	t.Run("Allow header", func(t *testing.T) {
+		if allow := response.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS" {
+			t.Logf("Expected \"GET, HEAD, OPTIONS\". Received \"%s\"\n", allow)
+			t.FailNow()
+		}
+	})
+}
+
+func TestRoutingOptions(t *testing.T) {
+	router := defaultTestRouter()
+	router.Path("/items").Get(func(ctx *gyr.Context) *gyr.Response { return ctx.Response() }).
+		Post(func(ctx *gyr.Context) *gyr.Response { return ctx.Response() })
+	gyrtest.NewRequest(http.MethodOptions, "/items").Send(router).
+		AssertStatus(t, http.StatusNoContent).
+		AssertHeader(t, "Allow", "GET, HEAD, OPTIONS, POST")
+	gyrtest.NewRequest(http.MethodOptions, "/missing").Send(router).AssertStatus(t, http.StatusNotFound)
+}
+
+func TestRoutingHead(t *testing.T) {
+	router := defaultTestRouter()
+	response := gyrtest.NewRequest(http.MethodHead, "/test").Send(router).
+		AssertStatus(t, http.StatusOK).
+		AssertHeader(t, "Content-Length", "6").
+		AssertHeader(t, "Content-Type", "text/plain")
+	if response.Body.Len() != 0 {
+		t.Logf("Expected no body. Received \"%s\"\n", response.Body.String())
+		t.FailNow()
+	}
 }
 
 func TestRoutingNotFound(t *testing.T) {
@@ -211,6 +238,68 @@ func TestFindRoutePrefixMatchesGroupButRouteOutsideOfGroup(t *testing.T) {
 	}
 }
 
+func TestFindRouteAmongManyRoutes(t *testing.T) {
+	router := defaultTestRouter()
+	for i := 0; i < 500; i++ {
+		router.Path("/items" + strconv.Itoa(i) + "/:id").Get(func(ctx *gyr.Context) *gyr.Response { return nil })
+	}
+	variable := router.Path("/users/:id").Get(func(ctx *gyr.Context) *gyr.Response { return nil })
+
+	if found := router.FindRoute("/users/me"); found != variable {
+		t.Logf("Expected variable route, found %+v\n", found)
+		t.FailNow()
+	}
+	if found := router.FindRoute("/users/a_b"); found != nil {
+		t.Logf("Expected no match for invalid variable value, found %+v\n", found)
+		t.FailNow()
+	}
+
+	late := router.Path("/late").Get(func(ctx *gyr.Context) *gyr.Response { return nil })
+	if found := router.FindRoute("/late"); found != late {
+		t.Logf("Expected route added after lookup to be found, found %+v\n", found)
+		t.FailNow()
+	}
+}
+
+func TestFindRouteStaticSegmentsBeatVariables(t *testing.T) {
+	noop := func(ctx *gyr.Context) *gyr.Response { return nil }
+	tests := []struct {
+		name     string
+		register []string
+		path     string
+		expected string
+	}{
+		{"static registered last", []string{"/users/:id", "/users/new"}, "/users/new", "/users/new"},
+		{"static registered first", []string{"/users/new", "/users/:id"}, "/users/new", "/users/new"},
+		{"variable still matches", []string{"/users/new", "/users/:id"}, "/users/7", "/users/:id"},
+		{"earlier segment decides", []string{"/:section/:id/posts", "/users/:id/:kind"}, "/users/7/posts", "/users/:id/:kind"},
+		{"same pattern registered first", []string{"/files/:name", "/files/:file"}, "/files/a", "/files/:name"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router := gyr.DefaultRouter()
+			for _, path := range test.register {
+				router.Path(path).Get(noop)
+			}
+			found := router.FindRoute(test.path)
+			if found == nil || found.Path != test.expected {
+				t.Logf("Expected %s, found %+v\n", test.expected, found)
+				t.FailNow()
+			}
+		})
+	}
+
+	t.Run("across groups", func(t *testing.T) {
+		router := gyr.DefaultRouter()
+		router.Group("/users").Path("/:id").Get(noop)
+		expected := router.Path("/users/new").Get(noop)
+		if found := router.FindRoute("/users/new"); found != expected {
+			t.Logf("Expected static route outside the group, found %+v\n", found)
+			t.FailNow()
+		}
+	})
+}
+
 func TestRouteWithIntPathVariable(t *testing.T) {
 	router := defaultTestRouter()
 	router.Path("/with-var/:v").Get(func(ctx *gyr.Context) *gyr.Response {
@@ -472,3 +561,170 @@ func TestHtmlDir(t *testing.T) {
 		}
 	})
 }
+
+func listUsers(ctx *gyr.Context) *gyr.Response {
+	return ctx.Response().NoContent()
+}
+
+func TestRoutes(t *testing.T) {
+	router := defaultTestRouter()
+	router.Middleware(func(ctx *gyr.Context) *gyr.Response { return nil })
+	api := router.Group("/api").Middleware(func(ctx *gyr.Context) *gyr.Response { return nil })
+	users := api.Group("/v1").Path("users").Post(listUsers).Get(listUsers)
+
+	routes := router.Routes()
+	if len(routes) != 2 {
+		t.Logf("Expected 2 routes. Received %+v\n", routes)
+		t.FailNow()
+	}
+	route := routes[1]
+	if route.Path != "/api/v1/users" || route.Group != "/api/v1" || route.Route != users ||
+		strings.Join(route.Methods, ",") != "GET,POST" || route.Middlewares != 2 {
+		t.Logf("Unexpected route %+v\n", route)
+		t.FailNow()
+	}
+}
+
+func TestGroupMiddlewareResolvedAtDispatch(t *testing.T) {
+	router := defaultTestRouter()
+	order := make([]string, 0)
+	track := func(name string) gyr.Handler {
+		return func(ctx *gyr.Context) *gyr.Response {
+			order = append(order, name)
+			return nil
+		}
+	}
+	api := router.Group("/api")
+	v1 := api.Group("/v1")
+	v1.Path("/users").Middleware(track("route")).Get(listUsers)
+	v1.Middleware(track("nested"))
+	api.Middleware(track("group"))
+	router.Middleware(track("router"))
+
+	gyrtest.Get("/api/v1/users").Send(router).AssertStatus(t, http.StatusNoContent)
+	if strings.Join(order, ",") != "router,group,nested,route" {
+		t.Logf("Expected router,group,nested,route. Received %v\n", order)
+		t.FailNow()
+	}
+}
+
+func TestPrintRoutes(t *testing.T) {
+	router := defaultTestRouter()
+	router.Middleware(func(ctx *gyr.Context) *gyr.Response { return nil })
+	api := router.Group("/api")
+	api.Middleware(func(ctx *gyr.Context) *gyr.Response { return nil })
+	api.Path("/users").Get(listUsers).Post(listUsers)
+
+	output := &bytes.Buffer{}
+	router.PrintRoutes(output)
+	expected := "METHOD  PATH        GROUP  MIDDLEWARES  HANDLER\n" +
+		"GET     /test       -      1            github.com/aigr20/gyr_test.defaultTestRouter.func1\n" +
+		"GET     /api/users  /api   2            github.com/aigr20/gyr_test.listUsers\n" +
+		"POST    /api/users  /api   2            github.com/aigr20/gyr_test.listUsers\n"
+	if output.String() != expected {
+		t.Logf("Expected\n%s\nReceived\n%s\n", expected, output.String())
+		t.FailNow()
+	}
+}
+
+func TestRouteMeta(t *testing.T) {
+	router := defaultTestRouter()
+	router.Middleware(func(ctx *gyr.Context) *gyr.Response {
+		scopes, _ := gyr.RouteMeta[[]string](ctx, "scopes")
+		if len(scopes) > 0 && ctx.Request.Header.Get("X-Scope") != scopes[0] {
+			return ctx.Response().Status(http.StatusForbidden)
+		}
+		return nil
+	})
+	router.Path("/admin").Set("scopes", []string{"admin"}).Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text("admin")
+	})
+
+	request, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+	if response := sendRequest(router, request); response.Code != http.StatusForbidden {
+		t.Logf("Expected 403 without scope. Received %d\n", response.Code)
+		t.FailNow()
+	}
+	request.Header.Set("X-Scope", "admin")
+	if response := sendRequest(router, request); response.Code != http.StatusOK {
+		t.Logf("Expected 200 with scope. Received %d\n", response.Code)
+		t.FailNow()
+	}
+	request, _ = http.NewRequest(http.MethodGet, "/test", nil)
+	if response := sendRequest(router, request); response.Code != http.StatusOK {
+		t.Logf("Expected routes without meta to pass. Received %d\n", response.Code)
+		t.FailNow()
+	}
+}
+
+func TestWrapMiddleware(t *testing.T) {
+	router := defaultTestRouter()
+	order := make([]string, 0)
+	router.Middleware(gyr.Wrap(func(ctx *gyr.Context, next gyr.Handler) *gyr.Response {
+		order = append(order, "before")
+		response := next(ctx)
+		order = append(order, "after")
+		return response.Header("X-Wrapped", "yes")
+	}))
+	router.Middleware(gyr.When(func(ctx *gyr.Context) bool { return ctx.Request.URL.Path == "/denied" },
+		gyr.Wrap(func(ctx *gyr.Context, next gyr.Handler) *gyr.Response {
+			return ctx.Response().Status(http.StatusForbidden)
+		})))
+	handler := func(ctx *gyr.Context) *gyr.Response {
+		order = append(order, "handler")
+		return ctx.Response().Text("ok")
+	}
+	router.Path("/wrapped").Get(handler)
+	router.Path("/denied").Get(handler)
+
+	gyrtest.Get("/wrapped").Send(router).AssertStatus(t, http.StatusOK).AssertHeader(t, "X-Wrapped", "yes")
+	if strings.Join(order, ",") != "before,handler,after" {
+		t.Logf("Expected before,handler,after. Received %v\n", order)
+		t.FailNow()
+	}
+
+	order = order[:0]
+	gyrtest.Get("/denied").Send(router).AssertStatus(t, http.StatusForbidden).AssertHeader(t, "X-Wrapped", "yes")
+	if strings.Join(order, ",") != "before,after" {
+		t.Logf("Expected the handler to be skipped. Received %v\n", order)
+		t.FailNow()
+	}
+}
+
+func TestStaticDirTraversal(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o600)
+	served := filepath.Join(dir, "public")
+	os.Mkdir(served, 0o755)
+	os.WriteFile(filepath.Join(served, "file.txt"), []byte("public"), 0o644)
+
+	router := defaultTestRouter()
+	router.StaticDir(served)
+	prefix := filepath.ToSlash(served)
+
+	for _, path := range []string{
+		prefix + "/../secret.txt",
+		prefix + "/%2e%2e/secret.txt",
+		prefix + "/%2E%2E%2Fsecret.txt",
+		prefix + "/..%5csecret.txt",
+		prefix + "/file.txt%00",
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.URL, _ = url.ParseRequestURI(path)
+		response := sendRequest(router, request)
+		if response.Code == http.StatusOK || strings.Contains(response.Body.String(), "secret") || strings.Contains(response.Body.String(), dir) {
+			t.Logf("%s: received %d %q", path, response.Code, response.Body.String())
+			t.FailNow()
+		}
+	}
+
+	os.Remove(filepath.Join(served, "file.txt"))
+	if err := os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(served, "file.txt")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	response := sendRequest(router, httptest.NewRequest(http.MethodGet, prefix+"/file.txt", nil))
+	if response.Code != http.StatusNotFound || strings.Contains(response.Body.String(), "secret") {
+		t.Logf("Expected 404 for symlink out of the directory. Received %d %q", response.Code, response.Body.String())
+		t.FailNow()
+	}
+}
@@ -0,0 +1,158 @@
+package gyr
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate JSON request bodies against schema before the handler runs. Invalid bodies get 400 with a
+// [ValidationErrorBody]. Requests without a body are only checked for POST, PUT and PATCH.
+//
+//	router.Path("/users").Post(CreateUser).RequestSchema(gyr.SchemaFor[CreateUserRequest]())
+func (route *Route) RequestSchema(schema *Schema) *Route {
+	return route.Middleware(func(ctx *Context) *Response {
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			return ctx.Response().ValidationError(err)
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if len(body) == 0 {
+			switch ctx.Request.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+			default:
+				return nil
+			}
+		}
+		if err := schema.ValidateJSON("body", body); err != nil {
+			return ctx.Response().ValidationError(err)
+		}
+		return nil
+	})
+}
+
+// Check JSON responses against schema when the [Profile] has Debug, logging responses that don't conform.
+// Only successful responses are checked. Nothing is checked outside debug mode.
+func (route *Route) ResponseSchema(schema *Schema) *Route {
+	return route.Middleware(func(ctx *Context) *Response {
+		if !CurrentProfile().Debug {
+			return nil
+		}
+		ctx.onDone(func() {
+			response := ctx.response
+			if response == nil || response.status < 200 || response.status > 299 || !strings.HasSuffix(parseContentType(response.w.Header().Get("Content-Type")).mimetype, "json") {
+				return
+			}
+			if err := schema.ValidateJSON("body", response.toWrite); err != nil {
+				ctx.Logger().Error("Response does not match schema", "route", route.Path, "status", response.status, "err", err)
+			}
+		})
+		return nil
+	})
+}
+
+// Derive a schema from the json and validate tags of T. Fields with required are required, and min, max, len,
+// oneof, email and uuid become the corresponding schema keywords.
+func SchemaFor[T any]() *Schema {
+	return schemaForType(reflect.TypeFor[T](), "")
+}
+
+func schemaForType(valueType reflect.Type, rules string) *Schema {
+	schema := &Schema{}
+	if valueType.Kind() == reflect.Pointer {
+		schema = schemaForType(valueType.Elem(), rules)
+		schema.Nullable = true
+		return schema
+	}
+
+	switch valueType.Kind() {
+	case reflect.String:
+		schema.Type = "string"
+	case reflect.Bool:
+		schema.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema.Type = "number"
+	case reflect.Slice, reflect.Array:
+		schema.Type = "array"
+		schema.Items = schemaForType(valueType.Elem(), "")
+	case reflect.Map:
+		schema.Type = "object"
+	case reflect.Struct:
+		if valueType == reflect.TypeFor[time.Time]() {
+			schema.Type, schema.Format = "string", "date-time"
+			break
+		}
+		if valueType == reflect.TypeFor[UUID]() {
+			schema.Type, schema.Format = "string", "uuid"
+			break
+		}
+		schema.Type = "object"
+		schema.Properties = make(map[string]*Schema)
+		for i := 0; i < valueType.NumField(); i++ {
+			field := valueType.Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+			name := validationFieldName(field)
+			fieldRules := field.Tag.Get(validate_tag)
+			schema.Properties[name] = schemaForType(field.Type, fieldRules)
+			if strings.Contains(","+fieldRules+",", ",required,") {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+	schema.applyRules(rules)
+	return schema
+}
+
+// Translate validate tag rules to schema keywords.
+func (schema *Schema) applyRules(rules string) {
+	for _, rule := range strings.Split(rules, ",") {
+		rule, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		number, numberErr := strconv.ParseFloat(param, 64)
+		count, countErr := strconv.Atoi(param)
+		switch {
+		case rule == "email" || rule == "uuid":
+			schema.Format = rule
+		case rule == "oneof":
+			for _, option := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, option)
+			}
+		case (rule == "min" || rule == "max" || rule == "len") && (numberErr == nil || countErr == nil):
+			schema.applyBound(rule, number, count)
+		}
+	}
+}
+
+func (schema *Schema) applyBound(rule string, number float64, count int) {
+	switch schema.Type {
+	case "string":
+		if rule != "max" {
+			schema.MinLength = &count
+		}
+		if rule != "min" {
+			schema.MaxLength = &count
+		}
+	case "array":
+		if rule != "max" {
+			schema.MinItems = &count
+		}
+		if rule != "min" {
+			schema.MaxItems = &count
+		}
+	case "integer", "number":
+		if rule != "max" {
+			schema.Minimum = &number
+		}
+		if rule != "min" {
+			schema.Maximum = &number
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package gyr_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+type schemaUser struct {
+	Name  string   `json:"name" validate:"required,min=2"`
+	Email string   `json:"email" validate:"required,email"`
+	Age   *int     `json:"age" validate:"min=18"`
+	Tags  []string `json:"tags" validate:"max=2"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema := gyr.SchemaFor[schemaUser]()
+	if schema.Type != "object" || len(schema.Required) != 2 {
+		t.Logf("Expected object with 2 required properties, got %+v", schema)
+		t.FailNow()
+	}
+	if age := schema.Properties["age"]; age.Type != "integer" || !age.Nullable || *age.Minimum != 18 {
+		t.Logf("Unexpected age schema %+v", age)
+		t.FailNow()
+	}
+	if err := schema.ValidateJSON("body", []byte(`{"name":"Al","email":"al@example.com","tags":["a","b","c"]}`)); err == nil {
+		t.Log("Expected too many tags to fail validation")
+		t.FailNow()
+	}
+}
+
+func TestRouteSchema(t *testing.T) {
+	router := defaultTestRouter()
+	router.Path("/users").Post(func(ctx *gyr.Context) *gyr.Response {
+		user, err := gyr.ReadBody[schemaUser](ctx)
+		if err != nil {
+			return ctx.Response().ValidationError(err)
+		}
+		return ctx.Response().Status(http.StatusCreated).Json(user)
+	}).RequestSchema(gyr.SchemaFor[schemaUser]())
+
+	gyrtest.Post("/users").JSON(map[string]string{"name": "Alice", "email": "alice@example.com"}).Send(router).
+		AssertStatus(t, http.StatusCreated)
+	gyrtest.Post("/users").JSON(map[string]string{"name": "A"}).Send(router).
+		AssertStatus(t, http.StatusBadRequest)
+	gyrtest.Post("/users").Send(router).AssertStatus(t, http.StatusBadRequest)
+
+	t.Run("response", func(t *testing.T) {
+		gyr.SetProfile(gyr.ProfileDevelopment)
+		defer gyr.SetProfile(gyr.Profile{})
+		router.Path("/drift").Get(func(ctx *gyr.Context) *gyr.Response {
+			return ctx.Response().Json(map[string]int{"name": 1})
+		}).ResponseSchema(gyr.SchemaFor[schemaUser]())
+		gyrtest.Get("/drift").Send(router).AssertStatus(t, http.StatusOK)
+	})
+}
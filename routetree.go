@@ -0,0 +1,173 @@
+package gyr
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Trie over the path segments of the routes and groups registered directly on a router or group, so finding
+// a route costs one lookup per segment instead of one regex per route. Groups have trees of their own.
+type routeTree struct {
+	root *routeNode
+}
+
+type routeNode struct {
+	static   map[string]*routeNode
+	variable *routeNode
+	// Routes ending at the node and groups whose prefix ends at it, with their registration order.
+	routes []routeEntry
+	groups []routeEntry
+}
+
+type routeEntry struct {
+	order int
+	entry RouterMatchable
+}
+
+// A route matching the path, with the kind of each matched segment, 0 for static and 1 for variable, and the
+// registration order of the route and the groups containing it.
+type routeMatch struct {
+	route *Route
+	kinds []byte
+	order []int
+}
+
+func newRouteNode() *routeNode {
+	return &routeNode{static: make(map[string]*routeNode)}
+}
+
+func buildRouteTree(haystack []RouterMatchable) *routeTree {
+	tree := &routeTree{root: newRouteNode()}
+	for order, routeOrGroup := range haystack {
+		entry := routeEntry{order: order, entry: routeOrGroup}
+		switch routeOrGroup := routeOrGroup.(type) {
+		case *Route:
+			node := tree.root.insert(routeSegments(routeOrGroup.Path))
+			node.routes = append(node.routes, entry)
+		case *RouteGroup:
+			node := tree.root.insert(routeSegments(routeOrGroup.Prefix))
+			node.groups = append(node.groups, entry)
+		}
+	}
+	return tree
+}
+
+func (node *routeNode) insert(segments []string) *routeNode {
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			if node.variable == nil {
+				node.variable = newRouteNode()
+			}
+			node = node.variable
+			continue
+		}
+		child, ok := node.static[segment]
+		if !ok {
+			child = newRouteNode()
+			node.static[segment] = child
+		}
+		node = child
+	}
+	return node
+}
+
+// Find the route matching segments. Static segments win over variables, compared from the start of the path,
+// so /users/new wins over /users/:id and /users/:id/posts over /:section/:id/posts regardless of registration
+// order. Routes in groups compete with the routes outside them. Between routes matching with the same
+// segment kinds, the one registered first wins.
+func (tree *routeTree) find(segments []string) *Route {
+	var best *routeMatch
+	tree.root.match(segments, nil, nil, &best)
+	if best == nil {
+		return nil
+	}
+	return best.route
+}
+
+func (node *routeNode) match(segments []string, kinds []byte, order []int, best **routeMatch) {
+	for _, group := range node.groups {
+		routeOrGroup := group.entry.(*RouteGroup)
+		groupTree := loadRouteTree(&routeOrGroup.tree, routeOrGroup.routes)
+		groupTree.root.match(segments, kinds, append(slices.Clip(order), group.order), best)
+	}
+	if len(segments) == 0 {
+		for _, route := range node.routes {
+			candidate := &routeMatch{
+				route: route.entry.(*Route),
+				kinds: slices.Clone(kinds),
+				order: append(slices.Clone(order), route.order),
+			}
+			if *best == nil || candidate.beats(*best) {
+				*best = candidate
+			}
+		}
+		return
+	}
+	if child, ok := node.static[segments[0]]; ok {
+		child.match(segments[1:], append(slices.Clip(kinds), 0), order, best)
+	}
+	if node.variable != nil && isVariableValue(segments[0]) {
+		node.variable.match(segments[1:], append(slices.Clip(kinds), 1), order, best)
+	}
+}
+
+func (match *routeMatch) beats(other *routeMatch) bool {
+	if byKind := bytes.Compare(match.kinds, other.kinds); byKind != 0 {
+		return byKind < 0
+	}
+	return slices.Compare(match.order, other.order) < 0
+}
+
+// The tree stored in current, building it from haystack if it has been reset.
+func loadRouteTree(current *atomic.Pointer[routeTree], haystack []RouterMatchable) *routeTree {
+	tree := current.Load()
+	if tree == nil {
+		tree = buildRouteTree(haystack)
+		current.Store(tree)
+	}
+	return tree
+}
+
+// The segments a route or group path matches. Empty parts are ignored, except that "/" matches
+// only the root path.
+func routeSegments(path string) []string {
+	if path == "/" {
+		return []string{""}
+	}
+	segments := make([]string, 0)
+	for _, part := range strings.Split(path, "/") {
+		if part != "" {
+			segments = append(segments, part)
+		}
+	}
+	return segments
+}
+
+// Split a request path into segments. The empty path has no segments and "/" has one empty segment.
+// Reports false for paths not starting with a slash.
+func requestSegments(path string) ([]string, bool) {
+	if path == "" {
+		return nil, true
+	}
+	if path[0] != '/' {
+		return nil, false
+	}
+	return strings.Split(path[1:], "/"), true
+}
+
+// Path variables match letters, digits, dashes and dots.
+func isVariableValue(segment string) bool {
+	if segment == "" || segment == "." || segment == ".." {
+		return false
+	}
+	for _, ch := range segment {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9', ch == '-', ch == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package gyr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// How long [Run] gives each component to shut down once it has been asked to stop.
+var ShutdownTimeout = 30 * time.Second
+
+// A long running part of an application that can be started together with others by [Run].
+type Component interface {
+	// Run until ctx is done, then shut down and return. A non-nil error stops the other components.
+	Run(ctx context.Context) error
+}
+
+// Function implementing [Component].
+type ComponentFunc func(ctx context.Context) error
+
+func (fn ComponentFunc) Run(ctx context.Context) error {
+	return fn(ctx)
+}
+
+// Run the components concurrently until ctx is done, SIGINT or SIGTERM is received or one of them fails.
+// The rest are then stopped one at a time in the order they were passed, each only once the one before it has
+// returned, and the first error is returned. Pass a server before the components its requests use, such as a
+// [JobQueue], so that they are stopped after the last request has finished.
+func Run(ctx context.Context, components ...Component) error {
+	ctx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	ctx, stopAll := context.WithCancel(ctx)
+	defer stopAll()
+
+	var once sync.Once
+	var firstErr error
+	cancels := make([]context.CancelFunc, len(components))
+	done := make([]chan struct{}, len(components))
+	for i, component := range components {
+		// Each component gets its own context so they can be stopped in order.
+		componentCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		cancels[i], done[i] = cancel, make(chan struct{})
+		go func() {
+			defer close(done[i])
+			err := component.Run(componentCtx)
+			// Components returning the context's own error were stopped rather than failing.
+			if err != nil && (componentCtx.Err() == nil || !errors.Is(err, componentCtx.Err())) {
+				once.Do(func() { firstErr = err })
+			}
+			stopAll()
+		}()
+	}
+	<-ctx.Done()
+	for i := range components {
+		cancels[i]()
+		<-done[i]
+	}
+	return firstErr
+}
+
+// Component serving HTTP with server, for example with a [Router] as handler. The routes of a [Router]
+// are printed at startup when the [Profile] has PrintRoutes, its streams are closed with [Router.Shutdown]
+// before the server shuts down and the tasks of its [BackgroundPool] are drained after.
+func ServerComponent(server *http.Server) Component {
+	return serverComponent(server, server.ListenAndServe)
+}
+
+// Like [ServerComponent], serving HTTPS with the certificate and key in certFile and keyFile.
+func TLSServerComponent(server *http.Server, certFile string, keyFile string) Component {
+	return serverComponent(server, func() error {
+		return server.ListenAndServeTLS(certFile, keyFile)
+	})
+}
+
+func serverComponent(server *http.Server, listen func() error) Component {
+	return ComponentFunc(func(ctx context.Context) error {
+		if router, isRouter := server.Handler.(*Router); isRouter && CurrentProfile().PrintRoutes {
+			router.PrintRoutes(os.Stdout)
+		}
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- listen()
+		}()
+
+		select {
+		case err := <-serveErr:
+			return err
+		case <-ctx.Done():
+		}
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		var routerErr error
+		if router, isRouter := server.Handler.(*Router); isRouter {
+			routerErr = router.Shutdown(shutdownCtx)
+		}
+		// The server is shut down even if the streams didn't finish, so it stops accepting connections.
+		serverErr := server.Shutdown(shutdownCtx)
+		if serverErr != nil {
+			server.Close()
+		}
+		if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+			serverErr = errors.Join(serverErr, err)
+		}
+		// Deferred tasks are drained once no request can schedule more.
+		if router, isRouter := server.Handler.(*Router); isRouter && router.background != nil {
+			routerErr = errors.Join(routerErr, router.background.Shutdown(shutdownCtx))
+		}
+		return errors.Join(routerErr, serverErr)
+	})
+}
+
+type ServerSettings struct {
+	// Accept HTTP/2 without TLS (h2c) alongside HTTP/1, for services behind a load balancer that terminates TLS
+	// and speaks HTTP/2 to its backends. Only prior knowledge connections are supported, not upgrades.
+	H2C bool
+}
+
+func DefaultServerSettings() ServerSettings {
+	return ServerSettings{}
+}
+
+func ServeH2C() func(*ServerSettings) {
+	return func(ss *ServerSettings) {
+		ss.H2C = true
+	}
+}
+
+// Serve the router on addr until SIGINT or SIGTERM is received, then let streams and in-flight requests finish
+// for up to [ShutdownTimeout] and return. Use [Run] with [ServerComponent] to run it alongside other components.
+//
+//	log.Fatal(router.Run(":8080", gyr.ServeH2C()))
+func (router *Router) Run(addr string, settings ...SettingsFunc[ServerSettings]) error {
+	serverSettings := DefaultServerSettings()
+	for _, setting := range settings {
+		setting(&serverSettings)
+	}
+	server := &http.Server{Addr: addr, Handler: router}
+	if serverSettings.H2C {
+		server.Protocols = new(http.Protocols)
+		server.Protocols.SetHTTP1(true)
+		server.Protocols.SetUnencryptedHTTP2(true)
+	}
+	return Run(context.Background(), ServerComponent(server))
+}
+
+// Like [Router.Run], serving HTTPS with the certificate and key in certFile and keyFile.
+func (router *Router) RunTLS(addr string, certFile string, keyFile string) error {
+	return Run(context.Background(), TLSServerComponent(&http.Server{Addr: addr, Handler: router}, certFile, keyFile))
+}
+
+// Start the workers and shut them down when ctx is done, so the queue can be passed to [Run].
+func (queue *JobQueue) Run(ctx context.Context) error {
+	queue.Start()
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	return queue.Shutdown(shutdownCtx)
+}
+
+// Wait until ctx is done and then drain asynchronous deliveries, so the bus can be passed to [Run].
+func (bus *EventBus) Run(ctx context.Context) error {
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	return bus.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,193 @@
+package gyr_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestRunStopsAllComponentsOnError(t *testing.T) {
+	failure := errors.New("fatal")
+	var stopped atomic.Int32
+	waiting := gyr.ComponentFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		stopped.Add(1)
+		return ctx.Err()
+	})
+	failing := gyr.ComponentFunc(func(ctx context.Context) error {
+		return failure
+	})
+
+	err := gyr.Run(context.Background(), waiting, gyr.NewJobQueue(), gyr.NewEventBus(), waiting, failing)
+	if !errors.Is(err, failure) || stopped.Load() != 2 {
+		t.Logf("Expected fatal error and 2 stopped components. Received %v and %d\n", err, stopped.Load())
+		t.FailNow()
+	}
+}
+
+func TestRunServerComponent(t *testing.T) {
+	server := &http.Server{Addr: "127.0.0.1:0", Handler: defaultTestRouter()}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := gyr.Run(ctx, gyr.ServerComponent(server)); err != nil {
+		t.Logf("Expected clean shutdown. Received %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestRouterRunStopsOnSignal(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("can't listen:", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	router := defaultTestRouter()
+	released := make(chan struct{})
+	router.Path("/slow").Get(func(ctx *gyr.Context) *gyr.Response {
+		<-released
+		return ctx.Response().Text("done")
+	})
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- router.Run(addr)
+	}()
+
+	var dialErr error
+	for range 100 {
+		var conn net.Conn
+		if conn, dialErr = net.Dial("tcp", addr); dialErr == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if dialErr != nil {
+		t.Logf("Server never started: %v", dialErr)
+		t.FailNow()
+	}
+
+	body := make(chan string, 1)
+	go func() {
+		response, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer response.Body.Close()
+		content, _ := io.ReadAll(response.Body)
+		body <- string(content)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	process, _ := os.FindProcess(os.Getpid())
+	if err := process.Signal(os.Interrupt); err != nil {
+		close(released)
+		t.Skip("can't signal the process:", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(released)
+
+	if received := <-body; received != "done" {
+		t.Logf("Expected the in-flight request to finish. Received %q", received)
+		t.FailNow()
+	}
+	if err := <-runErr; err != nil {
+		t.Logf("Expected clean shutdown. Received %v", err)
+		t.FailNow()
+	}
+}
+
+func TestRouterRunH2C(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("can't listen:", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	router := defaultTestRouter()
+	router.Path("/proto").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text(ctx.Request.Proto)
+	})
+	go router.Run(addr, gyr.ServeH2C())
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	var response *http.Response
+	for range 100 {
+		if response, err = client.Get("http://" + addr + "/proto"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Logf("Request failed: %v", err)
+		t.FailNow()
+	}
+	defer response.Body.Close()
+	content, _ := io.ReadAll(response.Body)
+	if string(content) != "HTTP/2.0" {
+		t.Logf("Expected HTTP/2 without TLS. Received %q", content)
+		t.FailNow()
+	}
+}
+
+func TestServerComponentClosesServerWhenStreamsHang(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("can't listen:", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	defer func(timeout time.Duration) { gyr.ShutdownTimeout = timeout }(gyr.ShutdownTimeout)
+	gyr.ShutdownTimeout = 50 * time.Millisecond
+	router := defaultTestRouter()
+	router.StreamGrace(10 * time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	router.Path("/hang").Get(func(ctx *gyr.Context) *gyr.Response {
+		stream := ctx.Stream()
+		stream.Write([]byte("data: hello\n\n"))
+		close(started)
+		<-release
+		return stream.End()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- gyr.Run(ctx, gyr.ServerComponent(&http.Server{Addr: addr, Handler: router}))
+	}()
+	for range 100 {
+		if response, err := http.Get("http://" + addr + "/hang"); err == nil {
+			defer response.Body.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	<-started
+	cancel()
+
+	if err := <-runErr; !errors.Is(err, context.DeadlineExceeded) {
+		t.Logf("Expected the shutdown deadline error. Received %v", err)
+		t.FailNow()
+	}
+	if conn, err := net.Dial("tcp", addr); err == nil {
+		conn.Close()
+		t.Log("Expected the listener to be closed once Run returned")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,103 @@
+package gyr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Suffix of the file holding the statements that revert a migration. These files are skipped by [Migrator.Migrate].
+const downMigrationSuffix = ".down.sql"
+
+const timestampVersionLayout = "20060102150405"
+
+type MigrationFileSettings struct {
+	// Prefix the files with a timestamp instead of the next version. Timestamps are also used when the
+	// existing migrations are timestamp prefixed.
+	Timestamp bool
+}
+
+func MigrationFileTimestamp() func(*MigrationFileSettings) {
+	return func(mfs *MigrationFileSettings) {
+		mfs.Timestamp = true
+	}
+}
+
+// Create an empty migration and its down migration in dir, prefixed with the next version.
+// Returns the paths of the created files.
+func NewMigrationFile(dir string, name string, settings ...SettingsFunc[MigrationFileSettings]) (string, string, error) {
+	var fileSettings MigrationFileSettings
+	for _, setting := range settings {
+		setting(&fileSettings)
+	}
+
+	name = strings.ToLower(strings.Join(strings.Fields(name), "_"))
+	if name == "" {
+		return "", "", errors.New("migration name is empty")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+
+	version := nextMigrationVersion(getSqlFilenames(dir), fileSettings.Timestamp, time.Now())
+	upPath := filepath.Join(dir, version+"_"+name+".sql")
+	downPath := filepath.Join(dir, version+"_"+name+downMigrationSuffix)
+
+	upContent := fmt.Sprintf("-- Migration %s: %s\n", version, name)
+	if err := writeNewFile(upPath, upContent); err != nil {
+		return "", "", err
+	}
+	downContent := fmt.Sprintf("-- Reverts migration %s: %s\n", version, name)
+	if err := writeNewFile(downPath, downContent); err != nil {
+		os.Remove(upPath)
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
+
+func nextMigrationVersion(paths []string, timestamp bool, now time.Time) string {
+	versions := make([]string, len(paths))
+	for i, path := range paths {
+		versions[i] = migrationVersionFromFilepath(path)
+	}
+	if len(versions) == 0 {
+		if timestamp {
+			return now.UTC().Format(timestampVersionLayout)
+		}
+		return "0.0.1"
+	}
+
+	last := slices.MaxFunc(versions, compareVersions)
+	if timestamp || isTimestampVersion(last) {
+		return now.UTC().Format(timestampVersionLayout)
+	}
+
+	parts := strings.Split(last, ".")
+	lastPart, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return now.UTC().Format(timestampVersionLayout)
+	}
+	parts[len(parts)-1] = strconv.Itoa(lastPart + 1)
+	return strings.Join(parts, ".")
+}
+
+func isTimestampVersion(version string) bool {
+	_, err := time.Parse(timestampVersionLayout, version)
+	return err == nil
+}
+
+// Write content to path, failing if the file already exists.
+func writeNewFile(path string, content string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString(content)
+	return err
+}
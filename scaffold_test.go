@@ -0,0 +1,46 @@
+package gyr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNextMigrationVersion(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		paths     []string
+		timestamp bool
+		expected  string
+	}{
+		{[]string{}, false, "0.0.1"},
+		{[]string{"0.0.9_a.sql", "0.0.2_b.sql"}, false, "0.0.10"},
+		{[]string{"0.0.9_a.sql"}, true, "20240501120000"},
+		{[]string{"20230101000000_a.sql"}, false, "20240501120000"},
+	}
+	for _, c := range cases {
+		if received := nextMigrationVersion(c.paths, c.timestamp, now); received != c.expected {
+			t.Logf("Expected %s. Received %s\n", c.expected, received)
+			t.Fail()
+		}
+	}
+}
+
+func TestNewMigrationFile(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "0.0.1_init.sql"), []byte(""), 0o644)
+	upPath, downPath, err := NewMigrationFile(dir, "Add users table")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if upPath != filepath.Join(dir, "0.0.2_add_users_table.sql") || downPath != filepath.Join(dir, "0.0.2_add_users_table.down.sql") {
+		t.Logf("Received %s and %s\n", upPath, downPath)
+		t.FailNow()
+	}
+	if paths := getSqlFilenames(dir); len(paths) != 2 {
+		t.Logf("Expected down migration to be skipped. Received %+v\n", paths)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,223 @@
+package gyr
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The subset of JSON Schema used by OpenAPI 3: types, formats, enums, bounds and patterns, nested through
+// properties and items.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+}
+
+// Check a decoded JSON value, as produced by unmarshalling into an any, against the schema. field names the
+// value in the returned [ValidationErrors], nested fields are separated by dots.
+func (schema *Schema) Validate(field string, value any) error {
+	errs := make(ValidationErrors, 0)
+	schema.validate(field, value, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Check a JSON document against the schema.
+func (schema *Schema) ValidateJSON(field string, document []byte) error {
+	var value any
+	if err := json.Unmarshal(document, &value); err != nil {
+		return ValidationErrors{{Field: field, Rule: "decode", Message: err.Error(), Code: "invalid_body"}}
+	}
+	return schema.Validate(field, value)
+}
+
+func (schema *Schema) validate(field string, value any, errs *ValidationErrors) {
+	fail := func(rule string, param string, message string) {
+		*errs = append(*errs, ValidationError{Field: field, Rule: rule, Param: param, Message: message, Code: validationCode(rule)})
+	}
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			fail("type", schema.Type, "must be of type "+schema.Type)
+		}
+		return
+	}
+	if !schema.hasType(value) {
+		fail("type", schema.Type, "must be of type "+schema.Type)
+		return
+	}
+
+	if len(schema.Enum) > 0 && !slicesContainsJSON(schema.Enum, value) {
+		allowed := make([]string, len(schema.Enum))
+		for i, option := range schema.Enum {
+			allowed[i] = fmt.Sprint(option)
+		}
+		fail("oneof", strings.Join(allowed, " "), "must be one of "+strings.Join(allowed, ", "))
+	}
+
+	switch value := value.(type) {
+	case string:
+		schema.checkBounds(value, schema.MinLength, schema.MaxLength, fail)
+		if schema.Pattern != "" {
+			if pattern, err := regexp.Compile(schema.Pattern); err != nil || !pattern.MatchString(value) {
+				fail("pattern", schema.Pattern, "must match "+schema.Pattern)
+			}
+		}
+		schema.checkFormat(value, fail)
+	case float64:
+		if schema.Minimum != nil && value < *schema.Minimum {
+			param := strconv.FormatFloat(*schema.Minimum, 'f', -1, 64)
+			fail("min", param, "must be at least "+param)
+		}
+		if schema.Maximum != nil && value > *schema.Maximum {
+			param := strconv.FormatFloat(*schema.Maximum, 'f', -1, 64)
+			fail("max", param, "must be at most "+param)
+		}
+	case []any:
+		schema.checkBounds(value, schema.MinItems, schema.MaxItems, fail)
+		if schema.Items != nil {
+			for i, item := range value {
+				schema.Items.validate(field+"."+strconv.Itoa(i), item, errs)
+			}
+		}
+	case map[string]any:
+		prefix := field + "."
+		if field == "" {
+			prefix = ""
+		}
+		for _, name := range schema.Required {
+			if _, exists := value[name]; !exists {
+				*errs = append(*errs, ValidationError{Field: prefix + name, Rule: "required", Message: "is required", Code: validationCode("required")})
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propertyValue := value[name]
+			property, known := schema.Properties[name]
+			if !known {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					*errs = append(*errs, ValidationError{Field: prefix + name, Rule: "unknown", Message: "is not allowed", Code: validationCode("unknown")})
+				}
+				continue
+			}
+			property.validate(prefix+name, propertyValue, errs)
+		}
+	}
+}
+
+func (schema *Schema) hasType(value any) bool {
+	switch schema.Type {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == float64(int64(number))
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	}
+	return true
+}
+
+func (schema *Schema) checkBounds(value any, minimum *int, maximum *int, fail func(string, string, string)) {
+	check := func(rule string, limit *int) {
+		if limit == nil {
+			return
+		}
+		if message := checkBound(reflect.ValueOf(value), rule, strconv.Itoa(*limit)); message != "" {
+			fail(rule, strconv.Itoa(*limit), message)
+		}
+	}
+	check("min", minimum)
+	check("max", maximum)
+}
+
+func (schema *Schema) checkFormat(value string, fail func(string, string, string)) {
+	switch schema.Format {
+	case "email", "uuid":
+		if message := checkRule(reflect.ValueOf(value), schema.Format, ""); message != "" {
+			fail(schema.Format, "", message)
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			fail("format", schema.Format, "must be an RFC 3339 date-time")
+		}
+	case "date":
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			fail("format", schema.Format, "must be a date")
+		}
+	}
+}
+
+// Convert a string from a path, query or header parameter to the type of the schema so it can be validated.
+// Values that can't be converted are returned unchanged and fail validation on their type.
+func (schema *Schema) coerce(value string) any {
+	switch schema.Type {
+	case "integer", "number":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	case "array":
+		items := strings.Split(value, ",")
+		coerced := make([]any, len(items))
+		for i, item := range items {
+			coerced[i] = item
+			if schema.Items != nil {
+				coerced[i] = schema.Items.coerce(item)
+			}
+		}
+		return coerced
+	}
+	return value
+}
+
+func slicesContainsJSON(options []any, value any) bool {
+	for _, option := range options {
+		if reflect.DeepEqual(option, value) {
+			return true
+		}
+		// Enums of integers unmarshal as float64, as do the values they are compared with.
+		if number, isNumber := option.(int); isNumber && float64(number) == value {
+			return true
+		}
+	}
+	return false
+}
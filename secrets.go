@@ -0,0 +1,173 @@
+package gyr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrSecretNotFound = errors.New("secret not found")
+
+// Source of secrets such as API keys and database passwords. Implementations return an error wrapping
+// [ErrSecretNotFound] when they don't have the secret so the next provider can be tried.
+type SecretProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// Function implementing [SecretProvider], the simplest way to plug in Vault, SOPS or a cloud secret manager.
+type SecretProviderFunc func(ctx context.Context, name string) (string, error)
+
+func (fn SecretProviderFunc) Secret(ctx context.Context, name string) (string, error) {
+	return fn(ctx, name)
+}
+
+// Reads secrets from environment variables named Prefix followed by the secret name, so with the prefix
+// SECRET_ the secret db_password is read from SECRET_DB_PASSWORD. Combine with [LoadEnvironment] to read
+// them from a .env file.
+type EnvSecrets struct {
+	Prefix string
+}
+
+func (provider EnvSecrets) Secret(ctx context.Context, name string) (string, error) {
+	variable := provider.Prefix + strings.ToUpper(name)
+	value, isSet := os.LookupEnv(variable)
+	if !isSet {
+		return "", fmt.Errorf("%w: environment variable %s is not set", ErrSecretNotFound, variable)
+	}
+	return value, nil
+}
+
+// Reads secrets from one file per secret in Directory, as mounted by Docker and Kubernetes. Trailing newlines
+// are removed.
+type FileSecrets struct {
+	Directory string
+}
+
+func (provider FileSecrets) Secret(ctx context.Context, name string) (string, error) {
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	content, err := os.ReadFile(filepath.Join(provider.Directory, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, filepath.Join(provider.Directory, name))
+	} else if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+type SecretsSettings struct {
+	// Tried in order until one has the secret.
+	Providers []SecretProvider
+	// How long a fetched secret is used before it is fetched again. 0 caches secrets until [Secrets.Refresh].
+	TTL time.Duration
+}
+
+func DefaultSecretsSettings() SecretsSettings {
+	return SecretsSettings{
+		Providers: []SecretProvider{EnvSecrets{}},
+		TTL:       5 * time.Minute,
+	}
+}
+
+// Set the providers, replacing the default environment provider.
+func SecretProviders(providers ...SecretProvider) func(*SecretsSettings) {
+	return func(ss *SecretsSettings) {
+		ss.Providers = providers
+	}
+}
+
+func SecretsTTL(ttl time.Duration) func(*SecretsSettings) {
+	return func(ss *SecretsSettings) {
+		ss.TTL = ttl
+	}
+}
+
+// Cached access to secrets from a chain of providers. Callbacks registered with [Secrets.OnRotate] are called
+// when a secret is fetched again and its value has changed.
+type Secrets struct {
+	mx        sync.Mutex
+	cache     *Cache[string, string]
+	known     map[string]string
+	callbacks []func(name string, value string)
+	Settings  SecretsSettings
+}
+
+func NewSecrets(settings ...SettingsFunc[SecretsSettings]) *Secrets {
+	secretsSettings := DefaultSecretsSettings()
+	for _, setting := range settings {
+		setting(&secretsSettings)
+	}
+	return &Secrets{
+		cache:     NewCache[string, string](CacheTTL(secretsSettings.TTL)),
+		known:     make(map[string]string),
+		callbacks: make([]func(string, string), 0),
+		Settings:  secretsSettings,
+	}
+}
+
+// Call callback with the new value whenever a secret changes, for example to reconnect with a new password.
+func (secrets *Secrets) OnRotate(callback func(name string, value string)) {
+	secrets.mx.Lock()
+	defer secrets.mx.Unlock()
+	secrets.callbacks = append(secrets.callbacks, callback)
+}
+
+// Get the secret from the cache or the first provider that has it.
+func (secrets *Secrets) Get(ctx context.Context, name string) (string, error) {
+	return secrets.cache.GetOrCompute(name, func() (string, error) {
+		return secrets.fetch(ctx, name)
+	})
+}
+
+// Fetch every secret that has been read before again, calling the rotation callbacks for those that changed.
+func (secrets *Secrets) Refresh(ctx context.Context) error {
+	secrets.mx.Lock()
+	names := make([]string, 0, len(secrets.known))
+	for name := range secrets.known {
+		names = append(names, name)
+	}
+	secrets.mx.Unlock()
+
+	var errs []error
+	for _, name := range names {
+		value, err := secrets.fetch(ctx, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		secrets.cache.Set(name, value)
+	}
+	return errors.Join(errs...)
+}
+
+func (secrets *Secrets) fetch(ctx context.Context, name string) (string, error) {
+	var value string
+	err := fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	for _, provider := range secrets.Settings.Providers {
+		value, err = provider.Secret(ctx, name)
+		if !errors.Is(err, ErrSecretNotFound) {
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	secrets.mx.Lock()
+	previous, seen := secrets.known[name]
+	secrets.known[name] = value
+	callbacks := secrets.callbacks
+	secrets.mx.Unlock()
+	if seen && previous != value {
+		for _, callback := range callbacks {
+			callback(name, value)
+		}
+	}
+	return value, nil
+}
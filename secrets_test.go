@@ -0,0 +1,66 @@
+package gyr_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestSecretsProviderChain(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "db_password"), []byte("from-file\n"), 0o600)
+	t.Setenv("SECRET_API_KEY", "from-env")
+	vault := gyr.SecretProviderFunc(func(ctx context.Context, name string) (string, error) {
+		if name == "signing_key" {
+			return "from-vault", nil
+		}
+		return "", gyr.ErrSecretNotFound
+	})
+	secrets := gyr.NewSecrets(gyr.SecretProviders(gyr.EnvSecrets{Prefix: "SECRET_"}, gyr.FileSecrets{Directory: dir}, vault))
+
+	expected := map[string]string{"api_key": "from-env", "db_password": "from-file", "signing_key": "from-vault"}
+	for name, value := range expected {
+		if received, err := secrets.Get(context.Background(), name); err != nil || received != value {
+			t.Logf("%s: expected %q. Received %q (%v)\n", name, value, received, err)
+			t.FailNow()
+		}
+	}
+	if _, err := secrets.Get(context.Background(), "missing"); !errors.Is(err, gyr.ErrSecretNotFound) {
+		t.Logf("Expected ErrSecretNotFound. Received %v\n", err)
+		t.FailNow()
+	}
+	if _, err := secrets.Get(context.Background(), "../etc/passwd"); err == nil {
+		t.Log("Expected path traversal to be rejected")
+		t.FailNow()
+	}
+}
+
+func TestSecretsRotation(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "first")
+	secrets := gyr.NewSecrets(gyr.SecretsTTL(0))
+	rotated := make(map[string]string)
+	secrets.OnRotate(func(name string, value string) {
+		rotated[name] = value
+	})
+
+	secrets.Get(context.Background(), "db_password")
+	os.Setenv("DB_PASSWORD", "second")
+	if value, _ := secrets.Get(context.Background(), "db_password"); value != "first" {
+		t.Logf("Expected cached value. Received %q\n", value)
+		t.FailNow()
+	}
+
+	if err := secrets.Refresh(context.Background()); err != nil {
+		t.Logf("Refresh failed: %v\n", err)
+		t.FailNow()
+	}
+	value, _ := secrets.Get(context.Background(), "db_password")
+	if value != "second" || rotated["db_password"] != "second" {
+		t.Logf("Expected rotation to second. Received %q and callbacks %v\n", value, rotated)
+		t.FailNow()
+	}
+}
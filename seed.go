@@ -0,0 +1,104 @@
+package gyr
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Run the seed files for the configured environment. Should be called after [Migrator.Migrate].
+// Every seed file is only applied once per environment, so Seed can be run on every startup.
+func (mig *Migrator) Seed() error {
+	_, err := mig.connection.ExecContext(mig.Settings.Context, mig.Settings.Dialect.SeedTableDDL)
+	if err != nil {
+		return err
+	}
+	applied, err := mig.appliedSeeds()
+	if err != nil {
+		return err
+	}
+
+	seeds := getSeedFilenames(mig.Settings.SeedDirectory, mig.Settings.SeedEnvironment)
+	seeds = slices.DeleteFunc(seeds, func(path string) bool {
+		return slices.Contains(applied, seedName(mig.Settings.SeedDirectory, path))
+	})
+	mig.logger.Info("Running seeds", "environment", mig.Settings.SeedEnvironment, "seeds", len(seeds))
+	if len(seeds) == 0 {
+		return nil
+	}
+
+	transaction, err := mig.beginTransaction()
+	if err != nil {
+		return err
+	}
+	defer mig.rollbackTransaction(transaction)
+	for _, path := range seeds {
+		if err := mig.executeQueriesInFile(path, transaction); err != nil {
+			mig.logger.Error("Error in seed execution", "file", path, "error", err)
+			return err
+		}
+		const query = "insert into gyr_migrator_seed_history (name, environment, applied_at) values (?, ?, ?)"
+		_, err := transaction.ExecContext(mig.Settings.Context, mig.Settings.Dialect.Rebind(query), seedName(mig.Settings.SeedDirectory, path), mig.Settings.SeedEnvironment, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+	return mig.commitTransaction(transaction)
+}
+
+func (mig *Migrator) appliedSeeds() ([]string, error) {
+	const query = "select name from gyr_migrator_seed_history where environment = ?"
+	rows, err := mig.connection.QueryContext(mig.Settings.Context, mig.Settings.Dialect.Rebind(query), mig.Settings.SeedEnvironment)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		applied = append(applied, name)
+	}
+	return applied, rows.Err()
+}
+
+// Get the common seed files followed by the ones for the environment, each set sorted by name.
+func getSeedFilenames(directory string, environment string) []string {
+	seeds := sqlFilesInDirectory(directory)
+	if environment != "" {
+		seeds = append(seeds, sqlFilesInDirectory(filepath.Join(directory, environment))...)
+	}
+	return seeds
+}
+
+func sqlFilesInDirectory(directory string) []string {
+	files := make([]string, 0)
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return files
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
+			files = append(files, filepath.Join(directory, entry.Name()))
+		}
+	}
+	return files
+}
+
+// Seeds are tracked relative to the seed directory so moving the directory doesn't rerun them.
+func seedName(directory string, path string) string {
+	name, err := filepath.Rel(directory, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(name)
+}
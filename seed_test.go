@@ -0,0 +1,19 @@
+package gyr
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetSeedFilenames(t *testing.T) {
+	seeds := getSeedFilenames("test_files/seeds", "development")
+	expected := []string{"test_files/seeds/0.0.1_admin.sql", "test_files/seeds/development/0.0.1_developer.sql"}
+	if !slices.Equal(seeds, expected) {
+		t.Logf("Expected %+v. Received %+v\n", expected, seeds)
+		t.FailNow()
+	}
+	if name := seedName("test_files/seeds", seeds[1]); name != "development/0.0.1_developer.sql" {
+		t.Logf("Expected development/0.0.1_developer.sql. Received %s\n", name)
+		t.FailNow()
+	}
+}
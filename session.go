@@ -0,0 +1,257 @@
+package gyr
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SessionSettings struct {
+	CookieName string
+	// Key signing the session cookie. Required, and should be at least 32 random bytes.
+	Key []byte
+	// Lifetime of the cookie. 0 makes it last until the browser is closed.
+	MaxAge   time.Duration
+	Path     string
+	Secure   bool
+	SameSite http.SameSite
+	// Keeps the values on the server, with only the session id in the cookie. Nil keeps the values in the
+	// cookie.
+	Store SessionStore
+}
+
+// Keeps session values on the server, see [SessionStorage]. Load returns nil values for unknown or expired
+// sessions.
+type SessionStore interface {
+	Load(ctx context.Context, id string) (map[string]string, error)
+	Save(ctx context.Context, id string, values map[string]string, expiresAt time.Time) error
+	Delete(ctx context.Context, id string) error
+}
+
+// How long sessions in a [SessionStore] are kept, and signed cookies are accepted, when MaxAge is 0.
+var SessionStoreTTL = 24 * time.Hour
+
+func DefaultSessionSettings() SessionSettings {
+	return SessionSettings{
+		CookieName: "gyr_session",
+		Path:       "/",
+		Secure:     true,
+		SameSite:   http.SameSiteLaxMode,
+	}
+}
+
+func SessionKey(key []byte) func(*SessionSettings) {
+	return func(ss *SessionSettings) {
+		ss.Key = key
+	}
+}
+
+func SessionCookie(name string) func(*SessionSettings) {
+	return func(ss *SessionSettings) {
+		ss.CookieName = name
+	}
+}
+
+func SessionMaxAge(maxAge time.Duration) func(*SessionSettings) {
+	return func(ss *SessionSettings) {
+		ss.MaxAge = maxAge
+	}
+}
+
+// Keep session values in store instead of the cookie, for example a [SQLSessionStore].
+func SessionStorage(store SessionStore) func(*SessionSettings) {
+	return func(ss *SessionSettings) {
+		ss.Store = store
+	}
+}
+
+// Allow the cookie over plain HTTP, for local development.
+func SessionInsecure() func(*SessionSettings) {
+	return func(ss *SessionSettings) {
+		ss.Secure = false
+	}
+}
+
+// Values kept between requests from the same browser, in a signed cookie or a [SessionStore]. Values in the
+// cookie can be read but not changed by the client, so secrets belong in a store. A signed cookie carries its
+// expiry and is rejected after it, but a copy of it can't be revoked before then, which a store allows. Get it
+// with [Context.Session].
+type Session struct {
+	values   map[string]string
+	settings *SessionSettings
+	writer   http.ResponseWriter
+	// Set when the values are kept in a store.
+	id  string
+	ctx context.Context
+}
+
+// Load the session from its cookie for [Context.Session]. Cookies with an invalid signature are ignored.
+// Changes are written to the response as they are made, so the middleware can be registered anywhere.
+func Sessions(settings ...SettingsFunc[SessionSettings]) Handler {
+	sessionSettings := DefaultSessionSettings()
+	for _, setting := range settings {
+		setting(&sessionSettings)
+	}
+	if len(sessionSettings.Key) == 0 {
+		panic("gyr: Sessions requires a key")
+	}
+	return func(ctx *Context) *Response {
+		session := &Session{values: make(map[string]string), settings: &sessionSettings, writer: ctx.writer, ctx: ctx.Request.Context()}
+		if cookie, err := ctx.Request.Cookie(sessionSettings.CookieName); err == nil {
+			if err := session.decode(cookie.Value); err != nil {
+				ctx.Logger().Error("Failed to load session", "error", err)
+			}
+		}
+		ctx.session = session
+		return nil
+	}
+}
+
+// The session of the request. Without the [Sessions] middleware the session is empty and changes are lost.
+func (ctx *Context) Session() *Session {
+	if ctx.session == nil {
+		ctx.Logger().Warn("Session used without the Sessions middleware")
+		ctx.session = &Session{values: make(map[string]string)}
+	}
+	return ctx.session
+}
+
+func (session *Session) Get(key string) string {
+	return session.values[key]
+}
+
+func (session *Session) Set(key string, value string) {
+	session.values[key] = value
+	session.save()
+}
+
+func (session *Session) Delete(key string) {
+	if _, exists := session.values[key]; !exists {
+		return
+	}
+	delete(session.values, key)
+	session.save()
+}
+
+// Remove every value, such as when the user logs out.
+func (session *Session) Clear() {
+	session.values = make(map[string]string)
+	session.save()
+}
+
+// Move the values to a new session id and delete the old one from the store, so an id planted before a login
+// can't be used to take over the session. Call it when the user logs in or their privileges change. Signed
+// cookie sessions get a new expiry.
+func (session *Session) Regenerate() {
+	if session.settings != nil && session.settings.Store != nil && session.id != "" {
+		if err := session.settings.Store.Delete(session.ctx, session.id); err != nil {
+			LoggerFrom(session.ctx).Error("Failed to delete regenerated session", "error", err)
+		}
+	}
+	session.id = ""
+	session.save()
+}
+
+// Replace the Set-Cookie header of the session with the current values.
+func (session *Session) save() {
+	if session.writer == nil {
+		return
+	}
+	header := session.writer.Header()
+	cookies := header.Values("Set-Cookie")
+	header.Del("Set-Cookie")
+	for _, cookie := range cookies {
+		if !strings.HasPrefix(cookie, session.settings.CookieName+"=") {
+			header.Add("Set-Cookie", cookie)
+		}
+	}
+
+	value, err := session.persist()
+	if err != nil {
+		LoggerFrom(session.ctx).Error("Failed to save session", "error", err)
+		return
+	}
+	cookie := &http.Cookie{
+		Name:     session.settings.CookieName,
+		Value:    value,
+		Path:     session.settings.Path,
+		Secure:   session.settings.Secure,
+		HttpOnly: true,
+		SameSite: session.settings.SameSite,
+	}
+	if len(session.values) == 0 {
+		cookie.Value = ""
+		cookie.MaxAge = -1
+	} else if session.settings.MaxAge > 0 {
+		cookie.MaxAge = int(session.settings.MaxAge.Seconds())
+	}
+	header.Add("Set-Cookie", cookie.String())
+}
+
+// Save the values to the store, if there is one, and return the cookie value.
+func (session *Session) persist() (string, error) {
+	store := session.settings.Store
+	ttl := session.settings.MaxAge
+	if ttl == 0 {
+		ttl = SessionStoreTTL
+	}
+	if store == nil {
+		// The expiry is signed with the values so a copied cookie stops working even if it is kept.
+		payload, _ := json.Marshal(session.values)
+		signed := base64.RawURLEncoding.EncodeToString(payload) + "." + strconv.FormatInt(CurrentClock().Now().Add(ttl).Unix(), 10)
+		return signed + "." + session.sign(signed), nil
+	}
+	if len(session.values) == 0 {
+		if session.id == "" {
+			return "", nil
+		}
+		return "", store.Delete(session.ctx, session.id)
+	}
+	if session.id == "" {
+		session.id = NewUUID().String()
+	}
+	return session.id + "." + session.sign(session.id), store.Save(session.ctx, session.id, session.values, CurrentClock().Now().Add(ttl))
+}
+
+// Load the values from a cookie value. Cookies with an invalid signature or that have expired are ignored.
+func (session *Session) decode(value string) error {
+	separator := strings.LastIndex(value, ".")
+	if separator < 0 {
+		return nil
+	}
+	signed, signature := value[:separator], value[separator+1:]
+	if !hmac.Equal([]byte(signature), []byte(session.sign(signed))) {
+		return nil
+	}
+	if session.settings.Store != nil {
+		values, err := session.settings.Store.Load(session.ctx, signed)
+		if err != nil || values == nil {
+			return err
+		}
+		session.id, session.values = signed, values
+		return nil
+	}
+	encoded, expiresAt, found := strings.Cut(signed, ".")
+	expires, err := strconv.ParseInt(expiresAt, 10, 64)
+	if !found || err != nil || !CurrentClock().Now().Before(time.Unix(expires, 0)) {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil
+	}
+	json.Unmarshal(payload, &session.values)
+	return nil
+}
+
+func (session *Session) sign(encoded string) string {
+	mac := hmac.New(sha256.New, session.settings.Key)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
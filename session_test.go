@@ -0,0 +1,117 @@
+package gyr_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+var testSessionKey = gyr.SessionKey([]byte("0123456789abcdef0123456789abcdef"))
+
+func sessionRouter(settings ...gyr.SettingsFunc[gyr.SessionSettings]) *gyr.Router {
+	router := defaultTestRouter()
+	router.Middleware(gyr.Sessions(append([]gyr.SettingsFunc[gyr.SessionSettings]{testSessionKey, gyr.SessionInsecure()}, settings...)...))
+	router.Path("/login").Post(func(ctx *gyr.Context) *gyr.Response {
+		ctx.Session().Regenerate()
+		ctx.Session().Set("user", "alice")
+		return ctx.Response().NoContent()
+	})
+	router.Path("/me").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text(ctx.Session().Get("user"))
+	})
+	return router
+}
+
+func sessionCookie(t *testing.T, response *gyrtest.Response) string {
+	for _, cookie := range response.Result().Cookies() {
+		if cookie.Name == "gyr_session" {
+			return cookie.Value
+		}
+	}
+	t.Log("Expected a session cookie")
+	t.FailNow()
+	return ""
+}
+
+func TestSessionCookieExpiry(t *testing.T) {
+	clock := gyr.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	gyr.SetClock(clock)
+	defer gyr.SetClock(nil)
+	router := sessionRouter(gyr.SessionMaxAge(time.Hour))
+
+	cookie := sessionCookie(t, gyrtest.Post("/login").Send(router))
+	me := func(cookie string) string {
+		return gyrtest.Get("/me").Header("Cookie", "gyr_session="+cookie).Send(router).Body.String()
+	}
+	if user := me(cookie); user != "alice" {
+		t.Logf("Expected the session to be loaded. Received %q\n", user)
+		t.FailNow()
+	}
+
+	parts := strings.Split(cookie, ".")
+	parts[1] = "99999999999"
+	if user := me(strings.Join(parts, ".")); user != "" {
+		t.Logf("Expected a cookie with a changed expiry to be rejected. Received %q\n", user)
+		t.FailNow()
+	}
+
+	clock.Advance(time.Hour + time.Second)
+	if user := me(cookie); user != "" {
+		t.Logf("Expected the expired cookie to be rejected. Received %q\n", user)
+		t.FailNow()
+	}
+}
+
+type memorySessionStore struct {
+	mx       sync.Mutex
+	sessions map[string]map[string]string
+}
+
+func (store *memorySessionStore) Load(ctx context.Context, id string) (map[string]string, error) {
+	store.mx.Lock()
+	defer store.mx.Unlock()
+	return store.sessions[id], nil
+}
+
+func (store *memorySessionStore) Save(ctx context.Context, id string, values map[string]string, expiresAt time.Time) error {
+	store.mx.Lock()
+	defer store.mx.Unlock()
+	store.sessions[id] = values
+	return nil
+}
+
+func (store *memorySessionStore) Delete(ctx context.Context, id string) error {
+	store.mx.Lock()
+	defer store.mx.Unlock()
+	delete(store.sessions, id)
+	return nil
+}
+
+func TestSessionRegenerate(t *testing.T) {
+	store := &memorySessionStore{sessions: make(map[string]map[string]string)}
+	router := sessionRouter(gyr.SessionStorage(store))
+	router.Path("/visit").Get(func(ctx *gyr.Context) *gyr.Response {
+		ctx.Session().Set("visited", "true")
+		return ctx.Response().NoContent()
+	})
+
+	planted := sessionCookie(t, gyrtest.Get("/visit").Send(router))
+	loggedIn := sessionCookie(t, gyrtest.Post("/login").Header("Cookie", "gyr_session="+planted).Send(router))
+	if loggedIn == planted {
+		t.Log("Expected a new session id after login")
+		t.FailNow()
+	}
+	if user := gyrtest.Get("/me").Header("Cookie", "gyr_session="+planted).Send(router).Body.String(); user != "" {
+		t.Logf("Expected the session id from before the login to be invalid. Received %q\n", user)
+		t.FailNow()
+	}
+	if user := gyrtest.Get("/me").Header("Cookie", "gyr_session="+loggedIn).Send(router).Body.String(); user != "alice" {
+		t.Logf("Expected the new session to be logged in. Received %q\n", user)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,218 @@
+package gyr
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// A table used by one of the SQL stores, created by the [Migrator] when passed to [MigrateStores].
+type StoreTable struct {
+	Name string
+	ddl  func(Dialect) string
+}
+
+var (
+	// Table of [SQLJobStore].
+	StoreJobs = StoreTable{Name: "gyr_jobs", ddl: func(Dialect) string {
+		return "create table if not exists gyr_jobs (id varchar(36) primary key, type varchar(255), payload text, attempts int, max_attempts int, run_at timestamp null, unique_key varchar(255), status varchar(20), last_error text);"
+	}}
+	// Table of [SQLSessionStore].
+	StoreSessions = StoreTable{Name: "gyr_sessions", ddl: func(dialect Dialect) string {
+		return "create table if not exists gyr_sessions (id varchar(64) primary key, data text, expires_at " + timestampType(dialect) + ");"
+	}}
+	// Table of [SQLCacheStore].
+	StoreCache = StoreTable{Name: "gyr_cache", ddl: func(dialect Dialect) string {
+		return "create table if not exists gyr_cache (cache_key varchar(255) primary key, value text, expires_at " + timestampType(dialect) + ");"
+	}}
+	// Table of [SQLWebhookDeadLetterStore].
+	StoreWebhooks = StoreTable{Name: "gyr_webhook_dead_letters", ddl: func(dialect Dialect) string {
+		return "create table if not exists gyr_webhook_dead_letters (id varchar(36) primary key, endpoint_id varchar(255), url text, event varchar(255), payload text, attempts int, last_error text, failed_at " + timestampType(dialect) + ");"
+	}}
+)
+
+func timestampType(dialect Dialect) string {
+	switch dialect.Name {
+	case DialectPostgres.Name:
+		return "timestamptz"
+	case DialectSQLite.Name:
+		return "datetime"
+	default:
+		return "timestamp null"
+	}
+}
+
+// Create the tables of the SQL stores when migrating, if they don't exist. The tables are not versioned, so
+// enabling a store later doesn't conflict with the migrations already applied.
+//
+//	migrator := gyr.NewMigrator(db, gyr.MigrateStores(gyr.StoreJobs, gyr.StoreSessions))
+func MigrateStores(tables ...StoreTable) func(*MigratorSettings) {
+	return func(ms *MigratorSettings) {
+		ms.Stores = append(ms.Stores, tables...)
+	}
+}
+
+func (mig *Migrator) createStoreTables() error {
+	for _, table := range mig.Settings.Stores {
+		if _, err := mig.connection.ExecContext(mig.Settings.Context, table.ddl(*mig.Settings.Dialect)); err != nil {
+			return err
+		}
+		mig.logger.Debug("Ensured store table", "table", table.Name)
+	}
+	return nil
+}
+
+// Keeps session values in the gyr_sessions table, for [SessionStorage]. Create the table with
+// [MigrateStores] or Setup.
+type SQLSessionStore struct {
+	connection DBTX
+	dialect    Dialect
+}
+
+func NewSQLSessionStore(connection DBTX, dialect Dialect) *SQLSessionStore {
+	return &SQLSessionStore{connection: connection, dialect: dialect}
+}
+
+// Create the gyr_sessions table if it does not exist.
+func (store *SQLSessionStore) Setup(ctx context.Context) error {
+	_, err := store.connection.ExecContext(ctx, StoreSessions.ddl(store.dialect))
+	return err
+}
+
+func (store *SQLSessionStore) Load(ctx context.Context, id string) (map[string]string, error) {
+	const query = "select data from gyr_sessions where id = ? and expires_at > ?"
+	var data string
+	err := store.connection.QueryRowContext(ctx, store.dialect.Rebind(query), id, CurrentClock().Now()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	return values, json.Unmarshal([]byte(data), &values)
+}
+
+func (store *SQLSessionStore) Save(ctx context.Context, id string, values map[string]string, expiresAt time.Time) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	query := "insert into gyr_sessions (id, data, expires_at) values (?, ?, ?) " + store.dialect.Upsert([]string{"id"}, []string{"data", "expires_at"})
+	_, err = store.connection.ExecContext(ctx, store.dialect.Rebind(query), id, string(data), expiresAt)
+	return err
+}
+
+func (store *SQLSessionStore) Delete(ctx context.Context, id string) error {
+	const query = "delete from gyr_sessions where id = ?"
+	_, err := store.connection.ExecContext(ctx, store.dialect.Rebind(query), id)
+	return err
+}
+
+// Remove expired sessions, for example from a scheduled job.
+func (store *SQLSessionStore) DeleteExpired(ctx context.Context) error {
+	const query = "delete from gyr_sessions where expires_at <= ?"
+	_, err := store.connection.ExecContext(ctx, store.dialect.Rebind(query), CurrentClock().Now())
+	return err
+}
+
+// A cache shared between processes in the gyr_cache table. Create the table with [MigrateStores] or Setup.
+type SQLCacheStore struct {
+	connection DBTX
+	dialect    Dialect
+}
+
+func NewSQLCacheStore(connection DBTX, dialect Dialect) *SQLCacheStore {
+	return &SQLCacheStore{connection: connection, dialect: dialect}
+}
+
+// Create the gyr_cache table if it does not exist.
+func (store *SQLCacheStore) Setup(ctx context.Context) error {
+	_, err := store.connection.ExecContext(ctx, StoreCache.ddl(store.dialect))
+	return err
+}
+
+// The value stored for key, reporting false if it is missing or expired.
+func (store *SQLCacheStore) Get(ctx context.Context, key string) (string, bool, error) {
+	const query = "select value from gyr_cache where cache_key = ? and expires_at > ?"
+	var value string
+	err := store.connection.QueryRowContext(ctx, store.dialect.Rebind(query), key, CurrentClock().Now()).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	return value, err == nil, err
+}
+
+func (store *SQLCacheStore) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	query := "insert into gyr_cache (cache_key, value, expires_at) values (?, ?, ?) " + store.dialect.Upsert([]string{"cache_key"}, []string{"value", "expires_at"})
+	_, err := store.connection.ExecContext(ctx, store.dialect.Rebind(query), key, value, CurrentClock().Now().Add(ttl))
+	return err
+}
+
+func (store *SQLCacheStore) Delete(ctx context.Context, key string) error {
+	const query = "delete from gyr_cache where cache_key = ?"
+	_, err := store.connection.ExecContext(ctx, store.dialect.Rebind(query), key)
+	return err
+}
+
+// Remove expired entries, for example from a scheduled job.
+func (store *SQLCacheStore) DeleteExpired(ctx context.Context) error {
+	const query = "delete from gyr_cache where expires_at <= ?"
+	_, err := store.connection.ExecContext(ctx, store.dialect.Rebind(query), CurrentClock().Now())
+	return err
+}
+
+// Records failed webhook deliveries in the gyr_webhook_dead_letters table, for [WebhookDeadLetters]. Create the
+// table with [MigrateStores] or Setup.
+type SQLWebhookDeadLetterStore struct {
+	connection DBTX
+	dialect    Dialect
+}
+
+func NewSQLWebhookDeadLetterStore(connection DBTX, dialect Dialect) *SQLWebhookDeadLetterStore {
+	return &SQLWebhookDeadLetterStore{connection: connection, dialect: dialect}
+}
+
+// Create the gyr_webhook_dead_letters table if it does not exist.
+func (store *SQLWebhookDeadLetterStore) Setup(ctx context.Context) error {
+	_, err := store.connection.ExecContext(ctx, StoreWebhooks.ddl(store.dialect))
+	return err
+}
+
+func (store *SQLWebhookDeadLetterStore) Record(ctx context.Context, letter WebhookDeadLetter) error {
+	const query = "insert into gyr_webhook_dead_letters (id, endpoint_id, url, event, payload, attempts, last_error, failed_at) values (?, ?, ?, ?, ?, ?, ?, ?)"
+	_, err := store.connection.ExecContext(ctx, store.dialect.Rebind(query), letter.ID.String(), letter.EndpointID, letter.URL, letter.Event, string(letter.Payload), letter.Attempts, letter.LastError, letter.FailedAt)
+	return err
+}
+
+// The recorded dead letters, oldest first.
+func (store *SQLWebhookDeadLetterStore) List(ctx context.Context) ([]WebhookDeadLetter, error) {
+	const query = "select id, endpoint_id, url, event, payload, attempts, last_error, failed_at from gyr_webhook_dead_letters order by failed_at"
+	rows, err := store.connection.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	letters := make([]WebhookDeadLetter, 0)
+	for rows.Next() {
+		var letter WebhookDeadLetter
+		var id, payload string
+		if err := rows.Scan(&id, &letter.EndpointID, &letter.URL, &letter.Event, &payload, &letter.Attempts, &letter.LastError, &letter.FailedAt); err != nil {
+			return nil, err
+		}
+		if letter.ID, err = ParseUUID(id); err != nil {
+			return nil, err
+		}
+		letter.Payload = []byte(payload)
+		letters = append(letters, letter)
+	}
+	return letters, rows.Err()
+}
+
+// Remove a dead letter, for example after redelivering it.
+func (store *SQLWebhookDeadLetterStore) Delete(ctx context.Context, id UUID) error {
+	const query = "delete from gyr_webhook_dead_letters where id = ?"
+	_, err := store.connection.ExecContext(ctx, store.dialect.Rebind(query), id.String())
+	return err
+}
@@ -0,0 +1,71 @@
+package gyr
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMigrateStores(t *testing.T) {
+	migrator := NewMigrator(openFakeDB(),
+		MigrationDirectory(filepath.Join("test_files", "migrations")),
+		MigrationDialect(DialectSQLite),
+		MigrateStores(StoreJobs, StoreSessions),
+	)
+	if err := migrator.Migrate(); err != nil {
+		t.Logf("Migrate failed: %v\n", err)
+		t.FailNow()
+	}
+	executed := testDriver.executed()
+	for _, expected := range []string{StoreJobs.ddl(DialectSQLite), "create table if not exists gyr_sessions (id varchar(64) primary key, data text, expires_at datetime);"} {
+		if !slices.Contains(executed, expected) {
+			t.Logf("Expected %s to be executed. Received %v\n", expected, executed)
+			t.FailNow()
+		}
+	}
+}
+
+func TestSQLSessionStore(t *testing.T) {
+	db := openFakeDB()
+	store := NewSQLSessionStore(db, DialectPostgres)
+	router := DefaultRouter()
+	router.Middleware(Sessions(SessionKey([]byte("0123456789abcdef0123456789abcdef")), SessionStorage(store)))
+	router.Path("/login").Post(func(ctx *Context) *Response {
+		ctx.Session().Set("user", "kalle")
+		return ctx.Response().NoContent()
+	})
+	router.Path("/me").Get(func(ctx *Context) *Response {
+		return ctx.Response().Text(ctx.Session().Get("user"))
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/login", nil))
+	expected := []string{"insert into gyr_sessions (id, data, expires_at) values ($1, $2, $3) on conflict (id) do update set data = excluded.data, expires_at = excluded.expires_at"}
+	if executed := testDriver.executed(); !slices.Equal(executed, expected) {
+		t.Logf("Expected %v. Received %v\n", expected, executed)
+		t.FailNow()
+	}
+	cookie := recorder.Result().Cookies()[0]
+	if strings.Contains(cookie.Value, "kalle") {
+		t.Logf("Expected only the session id in the cookie. Received %s\n", cookie.Value)
+		t.FailNow()
+	}
+
+	testDriver.respond("select data from gyr_sessions", []driver.Value{`{"user":"kalle"}`})
+	request := httptest.NewRequest(http.MethodGet, "/me", nil)
+	request.AddCookie(cookie)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if recorder.Body.String() != "kalle" {
+		t.Logf("Expected session loaded from the store. Received %q\n", recorder.Body.String())
+		t.FailNow()
+	}
+	if err := store.DeleteExpired(context.Background()); err != nil {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,79 @@
+package gyr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidStorageKey = errors.New("invalid storage key")
+
+// Place to keep files such as uploads. Keys are slash separated paths relative to the root of the storage.
+type Storage interface {
+	// Write the content of r to key, replacing what was there.
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Stores files below Directory on the local filesystem.
+type DirStorage struct {
+	Directory string
+}
+
+func (storage DirStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidStorageKey, key)
+	}
+	return filepath.Join(storage.Directory, cleaned), nil
+}
+
+// Write r to a temporary file that is renamed into place, so readers never see a partial file.
+func (storage DirStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := storage.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (storage DirStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := storage.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (storage DirStorage) Delete(ctx context.Context, key string) error {
+	path, err := storage.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
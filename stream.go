@@ -0,0 +1,70 @@
+package gyr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+)
+
+// Returned by [ReadBodyStream] when the body isn't a JSON array or newline delimited JSON.
+var ErrNotJSONArray = errors.New("body is not a JSON array")
+
+// Decode a JSON array body one element at a time, so large bodies can be processed as they arrive. Bodies
+// with Content-Type application/x-ndjson are read as one JSON value per line instead. Every element is
+// validated like in [ReadBody] and elements failing validation are yielded with a [ValidationErrors] whose
+// fields are prefixed with the index of the element. Iteration stops after an error decoding the body.
+//
+//	for item, err := range gyr.ReadBodyStream[Item](ctx) {
+//		if err != nil {
+//			return ctx.Response().ValidationError(err)
+//		}
+//		importItem(item)
+//	}
+func ReadBodyStream[T any](ctx *Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		contentType := parseContentType(ctx.Request.Header.Get("Content-Type")).mimetype
+		if contentType != "application/json" && contentType != "application/x-ndjson" {
+			yield(zero, fmt.Errorf("%w: unsupported Content-Type %s", ErrNotJSONArray, contentType))
+			return
+		}
+
+		decoder := json.NewDecoder(ctx.Request.Body)
+		isArray := contentType == "application/json"
+		if isArray {
+			if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+				yield(zero, fmt.Errorf("%w: expected [", ErrNotJSONArray))
+				return
+			}
+		}
+
+		for index := 0; decoder.More(); index++ {
+			var element T
+			if err := decoder.Decode(&element); err != nil {
+				yield(zero, fmt.Errorf("element %d: %w", index, err))
+				return
+			}
+			err := Validate(element)
+			var errs ValidationErrors
+			if errors.As(err, &errs) {
+				prefixed := make(ValidationErrors, len(errs))
+				for i, validationError := range errs {
+					validationError.Field = strconv.Itoa(index) + "." + validationError.Field
+					prefixed[i] = validationError
+				}
+				err = prefixed
+			}
+			if !yield(element, err) {
+				return
+			}
+		}
+
+		if isArray {
+			if token, err := decoder.Token(); err != nil || token != json.Delim(']') {
+				yield(zero, fmt.Errorf("%w: expected ]", ErrNotJSONArray))
+			}
+		}
+	}
+}
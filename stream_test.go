@@ -0,0 +1,67 @@
+package gyr_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+type importedItem struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func TestReadBodyStream(t *testing.T) {
+	var names []string
+	var errs []error
+	router := defaultTestRouter()
+	router.Path("/import").Post(func(ctx *gyr.Context) *gyr.Response {
+		for item, err := range gyr.ReadBodyStream[importedItem](ctx) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			names = append(names, item.Name)
+		}
+		return ctx.Response().NoContent()
+	})
+	send := func(contentType string, body string) {
+		names, errs = nil, nil
+		gyrtest.Post("/import").Header("Content-Type", contentType).Body(strings.NewReader(body)).Send(router)
+	}
+
+	t.Run("array", func(t *testing.T) {
+		send("application/json", `[{"name":"a"},{"name":""},{"name":"c"}]`)
+		var validationErrors gyr.ValidationErrors
+		if strings.Join(names, ",") != "a,c" || len(errs) != 1 || !errors.As(errs[0], &validationErrors) || validationErrors[0].Field != "1.name" {
+			t.Logf("Unexpected items %v and errors %v\n", names, errs)
+			t.FailNow()
+		}
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		send("application/x-ndjson", "{\"name\":\"a\"}\n{\"name\":\"b\"}\n")
+		if strings.Join(names, ",") != "a,b" || len(errs) != 0 {
+			t.Logf("Unexpected items %v and errors %v\n", names, errs)
+			t.FailNow()
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		send("application/json", `[{"name":"a"},{"name":`)
+		if len(names) != 1 || len(errs) != 1 {
+			t.Logf("Expected iteration to stop at the decode error. Received %v %v\n", names, errs)
+			t.FailNow()
+		}
+		send("application/json", `{"name":"a"}`)
+		if len(errs) != 1 || !errors.Is(errs[0], gyr.ErrNotJSONArray) {
+			t.Logf("Expected ErrNotJSONArray. Received %v\n", errs)
+			t.FailNow()
+		}
+	})
+
+	gyrtest.Post("/import").Send(router).AssertStatus(t, http.StatusNoContent)
+}
@@ -0,0 +1,161 @@
+package gyr
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// A response written in parts while the handler runs, such as server-sent events or a connection upgraded to
+// a WebSocket, which is told to finish when the router shuts down. Get one with [Context.Stream].
+//
+//	stream := ctx.Stream()
+//	for {
+//		select {
+//		case <-stream.Closing():
+//			stream.Write([]byte("event: goaway\ndata: \n\n"))
+//			return stream.End()
+//		case <-stream.Context().Done():
+//			return stream.End()
+//		case message := <-messages:
+//			stream.Write(message)
+//		}
+//	}
+type Stream struct {
+	ctx       *Context
+	streamCtx context.Context
+	closing   <-chan struct{}
+	started   bool
+}
+
+// Start streaming the response. Headers set with [Response.Header] before the first write are sent with it.
+func (ctx *Context) Stream() *Stream {
+	stream := &Stream{ctx: ctx, streamCtx: ctx.Request.Context()}
+	if ctx.streams != nil {
+		stream.streamCtx, stream.closing = ctx.streams.add(ctx)
+	}
+	return stream
+}
+
+// Closed when the router starts shutting down. The handler should tell the client, for example with a goaway
+// event or a close frame, and return within the grace period of [Router.StreamGrace].
+func (stream *Stream) Closing() <-chan struct{} {
+	return stream.closing
+}
+
+// Done when the client disconnects or the grace period of a shutdown has passed.
+func (stream *Stream) Context() context.Context {
+	return stream.streamCtx
+}
+
+// Write content to the client immediately, sending the status and headers first on the first write.
+func (stream *Stream) Write(content []byte) error {
+	if err := stream.streamCtx.Err(); err != nil {
+		return err
+	}
+	if !stream.started {
+		stream.started = true
+		stream.ctx.writer.WriteHeader(http.StatusOK)
+	}
+	if _, err := stream.ctx.writer.Write(content); err != nil {
+		return err
+	}
+	return http.NewResponseController(stream.ctx.writer).Flush()
+}
+
+// The response to return from the handler once the stream is finished.
+func (stream *Stream) End() *Response {
+	response := stream.ctx.Response()
+	response.streamed = stream.started
+	return response
+}
+
+// Set how long streams get to finish after [Router.Shutdown] tells them to close before their contexts are
+// canceled. Defaults to 10 seconds.
+func (router *Router) StreamGrace(grace time.Duration) {
+	router.streams.grace = grace
+}
+
+// Tell the streams opened with [Context.Stream] to finish and wait for them for the grace period, then cancel
+// their contexts and wait until their handlers have returned or ctx is done. Call it before
+// [http.Server.Shutdown], which waits for the handlers of open streams, as [ServerComponent] does.
+func (router *Router) Shutdown(ctx context.Context) error {
+	finished := router.streams.close()
+	grace := CurrentClock().NewTimer(router.streams.grace)
+	defer grace.Stop()
+	select {
+	case <-finished:
+		return nil
+	case <-grace.C():
+	case <-ctx.Done():
+	}
+
+	router.logger.Warn("Canceling streams that didn't finish within the grace period")
+	router.streams.cancelAll()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// The open streams of a router.
+type streamRegistry struct {
+	mx      sync.Mutex
+	grace   time.Duration
+	active  sync.WaitGroup
+	closing chan struct{}
+	closed  bool
+	cancels map[*Context]context.CancelFunc
+}
+
+func newStreamRegistry() *streamRegistry {
+	return &streamRegistry{
+		grace:   10 * time.Second,
+		closing: make(chan struct{}),
+		cancels: make(map[*Context]context.CancelFunc),
+	}
+}
+
+// Track the stream of ctx until its response has been sent.
+func (registry *streamRegistry) add(ctx *Context) (context.Context, <-chan struct{}) {
+	streamCtx, cancel := context.WithCancel(ctx.Request.Context())
+	registry.mx.Lock()
+	registry.cancels[ctx] = cancel
+	registry.active.Add(1)
+	registry.mx.Unlock()
+	ctx.onDone(func() {
+		registry.mx.Lock()
+		delete(registry.cancels, ctx)
+		registry.mx.Unlock()
+		cancel()
+		registry.active.Done()
+	})
+	return streamCtx, registry.closing
+}
+
+// Signal the streams to close, returning a channel closed once every stream has finished.
+func (registry *streamRegistry) close() <-chan struct{} {
+	registry.mx.Lock()
+	if !registry.closed {
+		registry.closed = true
+		close(registry.closing)
+	}
+	registry.mx.Unlock()
+	finished := make(chan struct{})
+	go func() {
+		registry.active.Wait()
+		close(finished)
+	}()
+	return finished
+}
+
+func (registry *streamRegistry) cancelAll() {
+	registry.mx.Lock()
+	defer registry.mx.Unlock()
+	for _, cancel := range registry.cancels {
+		cancel()
+	}
+}
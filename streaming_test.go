@@ -0,0 +1,68 @@
+package gyr_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+func TestStreamShutdown(t *testing.T) {
+	router := defaultTestRouter()
+	router.StreamGrace(50 * time.Millisecond)
+	canceled := make(chan struct{})
+	router.Path("/events").Get(func(ctx *gyr.Context) *gyr.Response {
+		ctx.Response().Header("Content-Type", "text/event-stream")
+		stream := ctx.Stream()
+		stream.Write([]byte("data: hello\n\n"))
+		<-stream.Closing()
+		stream.Write([]byte("event: goaway\ndata: \n\n"))
+		return stream.End()
+	})
+	router.Path("/stubborn").Get(func(ctx *gyr.Context) *gyr.Response {
+		stream := ctx.Stream()
+		stream.Write([]byte("data: hello\n\n"))
+		<-stream.Context().Done()
+		close(canceled)
+		return stream.End()
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	readers := make([]*bufio.Reader, 0)
+	for _, path := range []string{"/events", "/stubborn"} {
+		response, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Logf("Request failed: %v\n", err)
+			t.FailNow()
+		}
+		defer response.Body.Close()
+		reader := bufio.NewReader(response.Body)
+		if line, _ := reader.ReadString('\n'); line != "data: hello\n" {
+			t.Logf("Expected the first event before the handler returned. Received %q\n", line)
+			t.FailNow()
+		}
+		reader.ReadString('\n')
+		readers = append(readers, reader)
+	}
+
+	if err := router.Shutdown(context.Background()); err != nil {
+		t.Logf("Shutdown failed: %v\n", err)
+		t.FailNow()
+	}
+	if line, _ := readers[0].ReadString('\n'); !strings.HasPrefix(line, "event: goaway") {
+		t.Logf("Expected a goaway event. Received %q\n", line)
+		t.FailNow()
+	}
+	select {
+	case <-canceled:
+	default:
+		t.Log("Expected the stream ignoring Closing to be canceled after the grace period")
+		t.FailNow()
+	}
+}
@@ -0,0 +1 @@
+create table users (name varchar(255));
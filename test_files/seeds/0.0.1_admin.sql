@@ -0,0 +1 @@
+insert into users (name) values ('admin');
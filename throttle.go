@@ -0,0 +1,60 @@
+package gyr
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// How long a request waits for a free slot or a token before [RouteGroup.Concurrency] and
+// [RouteGroup.Throttle] give up and respond with 503.
+var ThrottleQueueTimeout = time.Second
+
+// Allow at most max requests to routes in the group to be handled at the same time. Requests over the limit
+// wait up to [ThrottleQueueTimeout] for a slot and then get 503 Service Unavailable with Retry-After.
+func (group *RouteGroup) Concurrency(max int) *RouteGroup {
+	slots := make(chan struct{}, max)
+	return group.Middleware(func(ctx *Context) *Response {
+		timer := time.NewTimer(ThrottleQueueTimeout)
+		defer timer.Stop()
+		select {
+		case slots <- struct{}{}:
+			ctx.onDone(func() { <-slots })
+			return nil
+		case <-timer.C:
+		case <-ctx.Request.Context().Done():
+		}
+		return serviceUnavailable(ctx, ThrottleQueueTimeout)
+	})
+}
+
+// Limit requests to routes in the group to rps per second, allowing short bursts of up to rps requests.
+// Requests over the rate wait up to [ThrottleQueueTimeout] and then get 503 Service Unavailable with
+// Retry-After.
+func (group *RouteGroup) Throttle(rps float64) *RouteGroup {
+	limiter := NewLimiter(rps, max(int(math.Ceil(rps)), 1))
+	return group.Middleware(func(ctx *Context) *Response {
+		if limiter.Allow() {
+			return nil
+		}
+		if delay := limiter.Delay(); delay > ThrottleQueueTimeout {
+			return serviceUnavailable(ctx, delay)
+		}
+		waitCtx, cancel := context.WithTimeout(ctx.Request.Context(), ThrottleQueueTimeout)
+		defer cancel()
+		if err := limiter.Wait(waitCtx); err != nil {
+			return serviceUnavailable(ctx, limiter.Delay())
+		}
+		return nil
+	})
+}
+
+func serviceUnavailable(ctx *Context, retryAfter time.Duration) *Response {
+	seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+	return ctx.Response().
+		Status(http.StatusServiceUnavailable).
+		Header("Retry-After", strconv.Itoa(seconds)).
+		Text("503 - Service Unavailable")
+}
@@ -0,0 +1,74 @@
+package gyr_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+)
+
+func shortThrottleTimeout(t *testing.T) {
+	previous := gyr.ThrottleQueueTimeout
+	gyr.ThrottleQueueTimeout = 20 * time.Millisecond
+	t.Cleanup(func() { gyr.ThrottleQueueTimeout = previous })
+}
+
+func TestGroupConcurrency(t *testing.T) {
+	shortThrottleTimeout(t)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	router := gyr.DefaultRouter()
+	reports := router.Group("/reports").Concurrency(1)
+	reports.Path("/slow").Get(func(ctx *gyr.Context) *gyr.Response {
+		close(started)
+		<-release
+		return ctx.Response().Text("done")
+	})
+	reports.Path("/fast").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text("done")
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		request, _ := http.NewRequest(http.MethodGet, "/reports/slow", nil)
+		sendRequest(router, request)
+	}()
+	<-started
+
+	request, _ := http.NewRequest(http.MethodGet, "/reports/fast", nil)
+	response := sendRequest(router, request)
+	if response.Code != http.StatusServiceUnavailable || response.Header().Get("Retry-After") != "1" {
+		t.Logf("Expected 503 while the slot is taken. Received %d\n", response.Code)
+		t.FailNow()
+	}
+
+	close(release)
+	wg.Wait()
+	request, _ = http.NewRequest(http.MethodGet, "/reports/fast", nil)
+	if response := sendRequest(router, request); response.Code != http.StatusOK {
+		t.Logf("Expected slot to be released. Received %d\n", response.Code)
+		t.FailNow()
+	}
+}
+
+func TestGroupThrottle(t *testing.T) {
+	shortThrottleTimeout(t)
+	router := gyr.DefaultRouter()
+	router.Group("/api").Throttle(1).Path("/ping").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text("pong")
+	})
+
+	codes := make([]int, 0, 2)
+	for range 2 {
+		request, _ := http.NewRequest(http.MethodGet, "/api/ping", nil)
+		codes = append(codes, sendRequest(router, request).Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusServiceUnavailable {
+		t.Logf("Expected 200 then 503. Received %v\n", codes)
+		t.FailNow()
+	}
+}
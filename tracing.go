@@ -0,0 +1,50 @@
+package gyr
+
+import (
+	"context"
+	"strings"
+)
+
+// Header carrying the request ID between services. The router reads it from incoming requests, [RequestIDs]
+// echoes it in responses and [Client] sends it with outgoing ones.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// Attach a request ID to ctx. It is added to the logs written with [LoggerFrom], sent by [Client] and stored
+// with jobs enqueued with ctx, so one ID ties together everything done for a request.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return WithLogAttrs(context.WithValue(ctx, requestIDKey{}, id), "request_id", id)
+}
+
+// The request ID attached to ctx with [WithRequestID], or an empty string.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// The request ID of the request, see [RequestIDs].
+func (ctx *Context) RequestID() string {
+	return RequestID(ctx.Request.Context())
+}
+
+// Give every request an ID, keeping the one in the [RequestIDHeader] of the request if it has one and
+// generating a UUIDv7 otherwise. The ID is attached to the request context with [WithRequestID], so it is
+// included in the logs of the request, and sent back in the [RequestIDHeader] of the response.
+func RequestIDs() Handler {
+	return func(ctx *Context) *Response {
+		id := RequestID(ctx.Request.Context())
+		if id == "" {
+			id = NewUUID().String()
+			ctx.Request = ctx.Request.WithContext(WithRequestID(ctx.Request.Context(), id))
+		}
+		ctx.writer.Header().Set(RequestIDHeader, id)
+		return nil
+	}
+}
+
+// Reports whether id, as received in the [RequestIDHeader], is safe to log and send on: at most 128
+// characters of letters, digits and -_.:
+func isValidRequestID(id string) bool {
+	return id != "" && len(id) <= 128 && strings.Trim(id, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.:") == ""
+}
@@ -0,0 +1,84 @@
+package gyr_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+type auditJob struct {
+	Action string `json:"action"`
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	upstreamID := make(chan string, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamID <- r.Header.Get(gyr.RequestIDHeader)
+	}))
+	defer upstream.Close()
+	client := gyr.NewClient(gyr.ClientBaseURL(upstream.URL))
+
+	queue := newTestQueue()
+	jobID := make(chan string, 1)
+	gyr.RegisterJob(queue, func(ctx context.Context, job auditJob) error {
+		jobID <- gyr.RequestID(ctx)
+		return nil
+	})
+	queue.Start()
+	defer queue.Shutdown(context.Background())
+
+	router := defaultTestRouter()
+	router.Path("/orders").Post(func(ctx *gyr.Context) *gyr.Response {
+		gyr.Enqueue(ctx.Request.Context(), queue, auditJob{Action: "order"})
+		response, err := client.Do(ctx.Request.Context(), http.MethodGet, "/", nil, nil)
+		if err == nil {
+			response.Body.Close()
+		}
+		return ctx.Response().NoContent()
+	})
+
+	gyrtest.Post("/orders").Header(gyr.RequestIDHeader, "req-1").Send(router).AssertStatus(t, http.StatusNoContent)
+	if received := <-upstreamID; received != "req-1" {
+		t.Logf("Expected request ID to be sent upstream. Received %q\n", received)
+		t.FailNow()
+	}
+	select {
+	case received := <-jobID:
+		if received != "req-1" {
+			t.Logf("Expected request ID in job context. Received %q\n", received)
+			t.FailNow()
+		}
+	case <-time.After(time.Second):
+		t.Log("Job was not run")
+		t.FailNow()
+	}
+}
+
+func TestRequestIDs(t *testing.T) {
+	router := defaultTestRouter()
+	router.Middleware(gyr.RequestIDs())
+	router.Path("/id").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text(ctx.RequestID())
+	})
+
+	gyrtest.Get("/id").Header(gyr.RequestIDHeader, "req-1").Send(router).
+		AssertHeader(t, gyr.RequestIDHeader, "req-1")
+
+	response := gyrtest.Get("/id").Send(router)
+	id, err := gyr.ParseUUID(response.Header().Get(gyr.RequestIDHeader))
+	if err != nil || id[6]>>4 != 7 || response.Body.String() != id.String() {
+		t.Logf("Expected a generated UUIDv7 in the header and context. Received %q and %q\n", response.Header().Get(gyr.RequestIDHeader), response.Body.String())
+		t.FailNow()
+	}
+
+	response = gyrtest.Get("/id").Header(gyr.RequestIDHeader, "bad id\n").Send(router)
+	if response.Header().Get(gyr.RequestIDHeader) == "bad id\n" {
+		t.Logf("Expected an invalid request ID to be replaced\n")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,141 @@
+package gyr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+var ErrUploadTooLarge = errors.New("upload exceeds the maximum size")
+
+type UploadSettings struct {
+	// Maximum size in bytes of each file. 0 means no limit.
+	MaxSize int64
+	// Called with the number of bytes written so far for the file with the key.
+	OnProgress func(key string, written int64)
+}
+
+func DefaultUploadSettings() UploadSettings {
+	return UploadSettings{MaxSize: 32 << 20}
+}
+
+func UploadMaxSize(size int64) func(*UploadSettings) {
+	return func(us *UploadSettings) {
+		us.MaxSize = size
+	}
+}
+
+func UploadProgress(callback func(key string, written int64)) func(*UploadSettings) {
+	return func(us *UploadSettings) {
+		us.OnProgress = callback
+	}
+}
+
+type UploadedFile struct {
+	Key      string
+	Filename string
+	// Form field of a multipart upload. Empty for raw uploads.
+	Field       string
+	ContentType string
+	Size        int64
+	// Hex encoded SHA-256 of the content.
+	Checksum string
+}
+
+// Stream the files of a multipart request, or the body of any other request, into storage without buffering
+// them in memory. keyFn gets the filename sent by the client, which may be empty, and returns the key to store
+// the file under. A file exceeding the maximum size is removed from storage and [ErrUploadTooLarge] returned.
+func (ctx *Context) StreamUpload(storage Storage, keyFn func(filename string) string, settings ...SettingsFunc[UploadSettings]) ([]UploadedFile, error) {
+	uploadSettings := DefaultUploadSettings()
+	for _, setting := range settings {
+		setting(&uploadSettings)
+	}
+
+	mediaType := parseContentType(ctx.Request.Header.Get("Content-Type")).mimetype
+	if mediaType != "multipart/form-data" {
+		filename := ""
+		if _, params, err := mime.ParseMediaType(ctx.Request.Header.Get("Content-Disposition")); err == nil {
+			filename = params["filename"]
+		}
+		file, err := ctx.storeUpload(storage, keyFn(filename), ctx.Request.Body, uploadSettings)
+		if err != nil {
+			return nil, err
+		}
+		file.Filename = filename
+		file.ContentType = mediaType
+		return []UploadedFile{file}, nil
+	}
+
+	reader, err := ctx.Request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	files := make([]UploadedFile, 0)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		} else if err != nil {
+			return files, err
+		}
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		file, err := ctx.storeUpload(storage, keyFn(part.FileName()), part, uploadSettings)
+		part.Close()
+		if err != nil {
+			return files, err
+		}
+		file.Filename = part.FileName()
+		file.Field = part.FormName()
+		file.ContentType = part.Header.Get("Content-Type")
+		files = append(files, file)
+	}
+}
+
+func (ctx *Context) storeUpload(storage Storage, key string, r io.Reader, settings UploadSettings) (UploadedFile, error) {
+	hash := sha256.New()
+	counter := &uploadCounter{reader: r, limit: settings.MaxSize, key: key, onProgress: settings.OnProgress}
+	if err := storage.Put(ctx.Context(), key, io.TeeReader(counter, hash)); err != nil {
+		if errors.Is(err, ErrUploadTooLarge) {
+			storage.Delete(ctx.Context(), key)
+		}
+		return UploadedFile{}, fmt.Errorf("storing %s: %w", key, err)
+	}
+	return UploadedFile{Key: key, Size: counter.written, Checksum: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// Counts the bytes read, reporting progress and failing once the limit is passed.
+type uploadCounter struct {
+	reader     io.Reader
+	written    int64
+	limit      int64
+	key        string
+	onProgress func(string, int64)
+}
+
+func (counter *uploadCounter) Read(p []byte) (int, error) {
+	n, err := counter.reader.Read(p)
+	counter.written += int64(n)
+	if counter.limit > 0 && counter.written > counter.limit {
+		return n, ErrUploadTooLarge
+	}
+	if n > 0 && counter.onProgress != nil {
+		counter.onProgress(counter.key, counter.written)
+	}
+	return n, err
+}
+
+// Respond with 413 Request Entity Too Large when err is [ErrUploadTooLarge] and 400 otherwise.
+func (r *Response) UploadError(err error) *Response {
+	if errors.Is(err, ErrUploadTooLarge) {
+		return r.Status(http.StatusRequestEntityTooLarge).Text(err.Error())
+	}
+	return r.Status(http.StatusBadRequest).Text(err.Error())
+}
@@ -0,0 +1,106 @@
+package gyr_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+func uploadRouter(storage gyr.Storage, files *[]gyr.UploadedFile, progress *int64) *gyr.Router {
+	router := gyr.DefaultRouter()
+	router.Path("/upload").Post(func(ctx *gyr.Context) *gyr.Response {
+		uploaded, err := ctx.StreamUpload(storage, func(filename string) string {
+			return "uploads/" + filename
+		}, gyr.UploadMaxSize(16), gyr.UploadProgress(func(key string, written int64) {
+			*progress = written
+		}))
+		*files = uploaded
+		if err != nil {
+			return ctx.Response().UploadError(err)
+		}
+		return ctx.Response().NoContent()
+	})
+	return router
+}
+
+func TestStreamUploadMultipart(t *testing.T) {
+	dir := t.TempDir()
+	var files []gyr.UploadedFile
+	var progress int64
+	router := uploadRouter(gyr.DirStorage{Directory: dir}, &files, &progress)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("title", "ignored")
+	part, _ := writer.CreateFormFile("document", "notes.txt")
+	part.Write([]byte("hello upload"))
+	writer.Close()
+
+	request, _ := http.NewRequest(http.MethodPost, "/upload", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	response := sendRequest(router, request)
+
+	checksum := sha256.Sum256([]byte("hello upload"))
+	expected := gyr.UploadedFile{Key: "uploads/notes.txt", Filename: "notes.txt", Field: "document", ContentType: "application/octet-stream", Size: 12, Checksum: hex.EncodeToString(checksum[:])}
+	if response.Code != http.StatusNoContent || len(files) != 1 || files[0] != expected || progress != 12 {
+		t.Logf("Received %d %+v progress %d\n", response.Code, files, progress)
+		t.FailNow()
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "uploads", "notes.txt")); string(content) != "hello upload" {
+		t.Logf("Unexpected stored content %q\n", content)
+		t.FailNow()
+	}
+}
+
+func TestStreamUploadRawTooLarge(t *testing.T) {
+	dir := t.TempDir()
+	var files []gyr.UploadedFile
+	var progress int64
+	router := uploadRouter(gyr.DirStorage{Directory: dir}, &files, &progress)
+
+	request, _ := http.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("x", 17)))
+	request.Header.Set("Content-Type", "text/plain")
+	request.Header.Set("Content-Disposition", `attachment; filename="big.txt"`)
+	response := sendRequest(router, request)
+	if response.Code != http.StatusRequestEntityTooLarge {
+		t.Logf("Expected 413. Received %d\n", response.Code)
+		t.FailNow()
+	}
+	if _, err := os.Stat(filepath.Join(dir, "uploads", "big.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Logf("Expected oversized upload to be removed. Stat returned %v\n", err)
+		t.FailNow()
+	}
+}
+
+func TestDirStorageRejectsEscapingKeys(t *testing.T) {
+	storage := gyr.DirStorage{Directory: t.TempDir()}
+	for _, key := range []string{"../outside", "/etc/passwd", ""} {
+		if err := storage.Put(context.Background(), key, strings.NewReader("x")); !errors.Is(err, gyr.ErrInvalidStorageKey) {
+			t.Logf("Expected %q to be rejected. Received %v\n", key, err)
+			t.FailNow()
+		}
+	}
+
+	storage.Put(context.Background(), "a/b.txt", strings.NewReader("content"))
+	file, err := storage.Open(context.Background(), "a/b.txt")
+	if err != nil {
+		t.Logf("Open failed: %v\n", err)
+		t.FailNow()
+	}
+	defer file.Close()
+	if content, _ := io.ReadAll(file); string(content) != "content" {
+		t.Logf("Unexpected content %q\n", content)
+		t.FailNow()
+	}
+}
@@ -1,25 +1,54 @@
 package gyr
 
 import (
+	"bytes"
 	"crypto/rand"
+	"database/sql/driver"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
 	"sync"
-	"time"
 )
 
 type UUID [16]byte
 
+// The UUID with all bits set to zero. Also the zero value of [UUID].
+var NilUUID UUID
+
+type NilUUIDMode int
+
+const (
+	// Serialize NilUUID as JSON null and SQL NULL.
+	NilUUIDAsNull NilUUIDMode = iota
+	// Serialize NilUUID as an empty string in both JSON and SQL.
+	NilUUIDAsEmptyString
+)
+
+// How NilUUID is serialized to JSON and SQL. Parsing accepts null, empty strings and the all zero UUID regardless.
+var NilUUIDSerialization = NilUUIDAsNull
+
+// Largest value of the 12 bit sequence counter stored in rand_a.
+const maxUUIDSequence = 0xfff
+
 var (
-	mxUUID sync.Mutex
-	seq    = 0
+	mxUUID   sync.Mutex
+	seq      = 0
+	lastMs   int64
+	uuidTime = func() int64 { return CurrentClock().Now().UnixMilli() }
 )
 
 // Generate a UUIDv7. Heavy inspiration taken from https://github.com/google/uuid for the implementation.
+//
+// UUIDs generated by the process are strictly increasing. The 12 bit sequence counter restarts at a random
+// value in the lower half of its range every millisecond, and when it is exhausted the timestamp is advanced
+// by a millisecond ahead of the clock, as described in RFC 9562 section 6.2. A clock moving backwards is
+// handled the same way.
 func NewUUID() UUID {
 	mxUUID.Lock()
-	defer mxUUID.Unlock()
-	now := time.Now().UnixMilli()
-	seq += 1
+	now, sequence := nextUUIDSequence()
+	mxUUID.Unlock()
 
 	var uuid UUID
 	// 6 byte = 48 bit = timestamp in ms
@@ -31,8 +60,8 @@ func NewUUID() UUID {
 	uuid[5] = byte(now)
 
 	// 112 = 0b01110000, guarantees that first 4 bits (the version) are 0b0111 (7)
-	uuid[6] = 112 | (15 & byte(seq>>8))
-	uuid[7] = byte(seq)
+	uuid[6] = 112 | (15 & byte(sequence>>8))
+	uuid[7] = byte(sequence)
 
 	rand.Read(uuid[8:])
 	uuid[8] = (uuid[8] & 63) | 128
@@ -40,6 +69,31 @@ func NewUUID() UUID {
 	return uuid
 }
 
+// Must be called with mxUUID held.
+func nextUUIDSequence() (int64, int) {
+	now := uuidTime()
+	if now > lastMs {
+		lastMs = now
+		seq = randomSequenceStart()
+		return lastMs, seq
+	}
+
+	// Same millisecond, or the clock went backwards. Keep counting from the last timestamp.
+	seq++
+	if seq > maxUUIDSequence {
+		lastMs++
+		seq = randomSequenceStart()
+	}
+	return lastMs, seq
+}
+
+// A random start in the lower half of the sequence range leaves room for at least 2048 UUIDs per millisecond.
+func randomSequenceStart() int {
+	var b [2]byte
+	rand.Read(b[:])
+	return int(b[0]&0x07)<<8 | int(b[1])
+}
+
 func (uuid UUID) String() string {
 	var out [36]byte
 
@@ -55,3 +109,165 @@ func (uuid UUID) String() string {
 
 	return string(out[:])
 }
+
+// Create a UUID from its 16 byte binary representation.
+func UUIDFromBytes(b []byte) (UUID, error) {
+	var uuid UUID
+	if len(b) != len(uuid) {
+		return uuid, fmt.Errorf("invalid UUID length %d, expected 16 bytes", len(b))
+	}
+	copy(uuid[:], b)
+	return uuid, nil
+}
+
+// Get the 16 byte binary representation of the UUID, e.g. for BINARY(16) columns.
+func (uuid UUID) Bytes() []byte {
+	b := make([]byte, len(uuid))
+	copy(b, uuid[:])
+	return b
+}
+
+func (uuid UUID) MarshalBinary() ([]byte, error) {
+	return uuid.Bytes(), nil
+}
+
+func (uuid *UUID) UnmarshalBinary(data []byte) error {
+	parsed, err := UUIDFromBytes(data)
+	if err != nil {
+		return err
+	}
+	*uuid = parsed
+	return nil
+}
+
+func (uuid UUID) IsNil() bool {
+	return uuid == NilUUID
+}
+
+// Parse a UUID in the canonical 36 character format.
+func ParseUUID(str string) (UUID, error) {
+	var uuid UUID
+	if len(str) != 36 || str[8] != '-' || str[13] != '-' || str[18] != '-' || str[23] != '-' {
+		return uuid, fmt.Errorf("invalid UUID %q", str)
+	}
+	parts := [][2]int{{0, 8}, {9, 13}, {14, 18}, {19, 23}, {24, 36}}
+	offset := 0
+	for _, part := range parts {
+		n, err := hex.Decode(uuid[offset:], []byte(str[part[0]:part[1]]))
+		if err != nil {
+			return NilUUID, fmt.Errorf("invalid UUID %q: %w", str, err)
+		}
+		offset += n
+	}
+	return uuid, nil
+}
+
+func (uuid UUID) MarshalText() ([]byte, error) {
+	if uuid.IsNil() && NilUUIDSerialization == NilUUIDAsEmptyString {
+		return []byte{}, nil
+	}
+	return []byte(uuid.String()), nil
+}
+
+func (uuid *UUID) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*uuid = NilUUID
+		return nil
+	}
+	parsed, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*uuid = parsed
+	return nil
+}
+
+func (uuid UUID) MarshalJSON() ([]byte, error) {
+	if uuid.IsNil() && NilUUIDSerialization == NilUUIDAsNull {
+		return []byte("null"), nil
+	}
+	text, _ := uuid.MarshalText()
+	return append(append([]byte{'"'}, text...), '"'), nil
+}
+
+func (uuid *UUID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*uuid = NilUUID
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return errors.New("UUID must be a JSON string")
+	}
+	return uuid.UnmarshalText(data[1 : len(data)-1])
+}
+
+// Implements [driver.Valuer]. UUIDs are stored in their string format.
+func (uuid UUID) Value() (driver.Value, error) {
+	if uuid.IsNil() {
+		if NilUUIDSerialization == NilUUIDAsNull {
+			return nil, nil
+		}
+		return "", nil
+	}
+	return uuid.String(), nil
+}
+
+// Implements [sql.Scanner]. Accepts the string format as well as 16 byte binary values.
+func (uuid *UUID) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*uuid = NilUUID
+		return nil
+	case string:
+		return uuid.UnmarshalText([]byte(src))
+	case []byte:
+		if len(src) == len(uuid) {
+			return uuid.UnmarshalBinary(src)
+		}
+		return uuid.UnmarshalText(src)
+	default:
+		return fmt.Errorf("can not scan %T into UUID", src)
+	}
+}
+
+// Digits in ASCII order, so short UUIDs sort the same way as the UUIDs they encode.
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Length of a short UUID. 62^22 is the smallest power of 62 above 2^128.
+const shortUUIDLength = 22
+
+// Get a URL friendly 22 character base62 representation of the UUID. Parse it with [ParseShortUUID].
+func (uuid UUID) Short() string {
+	number := new(big.Int).SetBytes(uuid[:])
+	base := big.NewInt(62)
+	remainder := new(big.Int)
+	var out [shortUUIDLength]byte
+	for i := shortUUIDLength - 1; i >= 0; i-- {
+		number.DivMod(number, base, remainder)
+		out[i] = base62Alphabet[remainder.Int64()]
+	}
+	return string(out[:])
+}
+
+// Parse a UUID from the format produced by [UUID.Short].
+func ParseShortUUID(str string) (UUID, error) {
+	if len(str) != shortUUIDLength {
+		return NilUUID, fmt.Errorf("invalid short UUID %q", str)
+	}
+	number := new(big.Int)
+	base := big.NewInt(62)
+	for _, ch := range str {
+		digit := strings.IndexRune(base62Alphabet, ch)
+		if digit == -1 {
+			return NilUUID, fmt.Errorf("invalid short UUID %q", str)
+		}
+		number.Mul(number, base).Add(number, big.NewInt(int64(digit)))
+	}
+	if number.BitLen() > 128 {
+		return NilUUID, fmt.Errorf("invalid short UUID %q", str)
+	}
+
+	var uuid UUID
+	number.FillBytes(uuid[:])
+	return uuid, nil
+}
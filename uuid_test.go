@@ -0,0 +1,178 @@
+package gyr
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func withUUIDTime(t *testing.T, clock func() int64) {
+	mxUUID.Lock()
+	previousTime, previousMs, previousSeq := uuidTime, lastMs, seq
+	uuidTime, lastMs, seq = clock, 0, 0
+	mxUUID.Unlock()
+	t.Cleanup(func() {
+		mxUUID.Lock()
+		uuidTime, lastMs, seq = previousTime, previousMs, previousSeq
+		mxUUID.Unlock()
+	})
+}
+
+func TestUUIDVersionAndVariant(t *testing.T) {
+	uuid := NewUUID()
+	if uuid[6]>>4 != 7 {
+		t.Logf("Expected version 7. Received %d\n", uuid[6]>>4)
+		t.FailNow()
+	}
+	if uuid[8]>>6 != 2 {
+		t.Logf("Expected variant 0b10. Received %b\n", uuid[8]>>6)
+		t.FailNow()
+	}
+}
+
+func TestUUIDMonotonicWithinMillisecond(t *testing.T) {
+	withUUIDTime(t, func() int64 { return 1_700_000_000_000 })
+	previous := NewUUID()
+	// More than the 4096 values the sequence can hold, forcing the timestamp to be borrowed.
+	for i := 0; i < 10_000; i++ {
+		next := NewUUID()
+		if bytes.Compare(previous[:8], next[:8]) >= 0 {
+			t.Logf("UUIDs not increasing at %d: %s >= %s\n", i, previous, next)
+			t.FailNow()
+		}
+		previous = next
+	}
+}
+
+func TestUUIDSequenceResetsEveryMillisecond(t *testing.T) {
+	now := int64(1_700_000_000_000)
+	withUUIDTime(t, func() int64 { return now })
+	for i := 0; i < 100; i++ {
+		NewUUID()
+	}
+	now++
+	uuid := NewUUID()
+	sequence := int(uuid[6]&15)<<8 | int(uuid[7])
+	if sequence > 0x7ff {
+		t.Logf("Expected sequence to restart in the lower half. Received %d\n", sequence)
+		t.FailNow()
+	}
+}
+
+func TestUUIDClockGoingBackwards(t *testing.T) {
+	now := int64(1_700_000_000_000)
+	withUUIDTime(t, func() int64 { return now })
+	first := NewUUID()
+	now -= 1000
+	second := NewUUID()
+	if bytes.Compare(first[:8], second[:8]) >= 0 {
+		t.Logf("Expected %s < %s\n", first, second)
+		t.FailNow()
+	}
+}
+
+func TestUUIDBinaryRoundTrip(t *testing.T) {
+	uuid := NewUUID()
+	data, err := uuid.MarshalBinary()
+	if err != nil || len(data) != 16 {
+		t.Logf("Received %v, %v\n", data, err)
+		t.FailNow()
+	}
+	var decoded UUID
+	if err := decoded.UnmarshalBinary(data); err != nil || decoded != uuid {
+		t.Logf("Expected %s. Received %s (%v)\n", uuid, decoded, err)
+		t.FailNow()
+	}
+	if _, err := UUIDFromBytes(data[:10]); err == nil {
+		t.Log("Expected error for short input")
+		t.FailNow()
+	}
+}
+
+func TestParseUUID(t *testing.T) {
+	uuid := NewUUID()
+	parsed, err := ParseUUID(uuid.String())
+	if err != nil || parsed != uuid {
+		t.Logf("Expected %s. Received %s (%v)\n", uuid, parsed, err)
+		t.FailNow()
+	}
+	if _, err := ParseUUID("not-a-uuid"); err == nil {
+		t.FailNow()
+	}
+}
+
+func TestNilUUIDSerialization(t *testing.T) {
+	type optional struct {
+		ID UUID `json:"id"`
+	}
+	t.Run("null", func(t *testing.T) {
+		data, _ := json.Marshal(optional{})
+		if string(data) != `{"id":null}` {
+			t.Logf("Received %s\n", data)
+			t.FailNow()
+		}
+		if value, _ := NilUUID.Value(); value != nil {
+			t.Logf("Expected nil SQL value. Received %v\n", value)
+			t.FailNow()
+		}
+	})
+	t.Run("empty string", func(t *testing.T) {
+		NilUUIDSerialization = NilUUIDAsEmptyString
+		defer func() { NilUUIDSerialization = NilUUIDAsNull }()
+		data, _ := json.Marshal(optional{})
+		if string(data) != `{"id":""}` {
+			t.Logf("Received %s\n", data)
+			t.FailNow()
+		}
+	})
+	t.Run("parsing", func(t *testing.T) {
+		for _, input := range []string{`{"id":null}`, `{"id":""}`} {
+			decoded := optional{ID: NewUUID()}
+			if err := json.Unmarshal([]byte(input), &decoded); err != nil || !decoded.ID.IsNil() {
+				t.Logf("Expected nil UUID from %s. Received %s (%v)\n", input, decoded.ID, err)
+				t.FailNow()
+			}
+		}
+	})
+}
+
+func TestUUIDScan(t *testing.T) {
+	uuid := NewUUID()
+	for _, src := range []any{uuid.String(), []byte(uuid.String()), uuid.Bytes()} {
+		var scanned UUID
+		if err := scanned.Scan(src); err != nil || scanned != uuid {
+			t.Logf("Expected %s from %T. Received %s (%v)\n", uuid, src, scanned, err)
+			t.FailNow()
+		}
+	}
+	var scanned UUID = NewUUID()
+	if err := scanned.Scan(nil); err != nil || !scanned.IsNil() {
+		t.FailNow()
+	}
+}
+
+func TestShortUUID(t *testing.T) {
+	uuid := NewUUID()
+	short := uuid.Short()
+	if len(short) != 22 {
+		t.Logf("Expected 22 characters. Received %s\n", short)
+		t.FailNow()
+	}
+	parsed, err := ParseShortUUID(short)
+	if err != nil || parsed != uuid {
+		t.Logf("Expected %s. Received %s (%v)\n", uuid, parsed, err)
+		t.FailNow()
+	}
+	if NilUUID.Short() != "0000000000000000000000" {
+		t.Logf("Received %s\n", NilUUID.Short())
+		t.FailNow()
+	}
+	max := UUID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	if parsed, _ := ParseShortUUID(max.Short()); parsed != max {
+		t.FailNow()
+	}
+	if _, err := ParseShortUUID("zzzzzzzzzzzzzzzzzzzzzz"); err == nil {
+		t.Log("Expected overflow error")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,273 @@
+package gyr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	validate_tag = "validate"
+)
+
+// Custom validation rule registered with [RegisterValidation]. param is the text after = in the tag, if any.
+type ValidationFunc func(value any, param string) bool
+
+var validationRegistry = make(map[string]ValidationFunc)
+
+// A single rule that a field failed.
+type ValidationError struct {
+	// Name of the field, using its json name when it has one. Nested fields are separated by dots.
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+	// Stable machine readable code for the failure, see [ValidationCodes].
+	Code string `json:"code"`
+}
+
+// Codes reported for the built in rules. Custom rules use their name as code.
+var ValidationCodes = map[string]string{
+	"required":   "required",
+	"min":        "too_small",
+	"max":        "too_large",
+	"len":        "wrong_length",
+	"email":      "invalid_email",
+	"uuid":       "invalid_uuid",
+	"oneof":      "not_allowed",
+	"filterable": "not_filterable",
+	"sortable":   "not_sortable",
+	"type":       "wrong_type",
+	"pattern":    "invalid_format",
+	"format":     "invalid_format",
+	"unknown":    "unknown_field",
+}
+
+func validationCode(rule string) string {
+	if code, exists := ValidationCodes[rule]; exists {
+		return code
+	}
+	return rule
+}
+
+func (err ValidationError) Error() string {
+	return err.Field + " " + err.Message
+}
+
+// Every rule that failed when validating a struct, in field order.
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// Register a custom rule usable in validate tags as name or name=param. Registering an existing name replaces it.
+func RegisterValidation(name string, fn ValidationFunc) {
+	validationRegistry[name] = fn
+}
+
+// Check the validate tags on the fields of target, which must be a struct or a pointer to one. Supported rules
+// are required, min, max, len, email, uuid, oneof and those added with [RegisterValidation], for example
+// `validate:"required,min=3,oneof=a b c"`. Fields without required that hold their zero value are not checked.
+// The returned error is a [ValidationErrors] listing every failed rule.
+func Validate(target any) error {
+	value := reflect.ValueOf(target)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	errs := make(ValidationErrors, 0)
+	validateStruct("", value, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateStruct(prefix string, value reflect.Value, errs *ValidationErrors) {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := prefix + validationFieldName(field)
+		fieldValue := value.Field(i)
+
+		if tag, hasTag := field.Tag.Lookup(validate_tag); hasTag && tag != "-" {
+			validateField(name, fieldValue, tag, errs)
+		}
+
+		nested := fieldValue
+		if nested.Kind() == reflect.Pointer && !nested.IsNil() {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && nested.Type() != reflect.TypeFor[time.Time]() {
+			validateStruct(name+".", nested, errs)
+		}
+	}
+}
+
+func validationFieldName(field reflect.StructField) string {
+	if tag, hasTag := field.Tag.Lookup("json"); hasTag {
+		name, _, _ := strings.Cut(tag, ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func validateField(name string, value reflect.Value, tag string, errs *ValidationErrors) {
+	rules := strings.Split(tag, ",")
+	if value.IsZero() {
+		if slices.Contains(rules, "required") {
+			*errs = append(*errs, ValidationError{Field: name, Rule: "required", Message: "is required", Code: validationCode("required")})
+		}
+		return
+	}
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	for _, rule := range rules {
+		rule, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if rule == "" || rule == "required" {
+			continue
+		}
+		if message := checkRule(value, rule, param); message != "" {
+			*errs = append(*errs, ValidationError{Field: name, Rule: rule, Param: param, Message: message, Code: validationCode(rule)})
+		}
+	}
+}
+
+// Returns a description of why value doesn't satisfy rule, or an empty string if it does.
+func checkRule(value reflect.Value, rule string, param string) string {
+	switch rule {
+	case "min", "max", "len":
+		return checkBound(value, rule, param)
+	case "email":
+		address, err := mail.ParseAddress(fmt.Sprint(value.Interface()))
+		if err != nil || address.Address != fmt.Sprint(value.Interface()) {
+			return "must be a valid email address"
+		}
+	case "uuid":
+		if _, isUUID := value.Interface().(UUID); isUUID {
+			return ""
+		}
+		if _, err := ParseUUID(fmt.Sprint(value.Interface())); err != nil {
+			return "must be a valid UUID"
+		}
+	case "oneof":
+		if !slices.Contains(strings.Fields(param), fmt.Sprint(value.Interface())) {
+			return "must be one of " + strings.Join(strings.Fields(param), ", ")
+		}
+	default:
+		fn, exists := validationRegistry[rule]
+		if !exists {
+			return "has unknown validation rule " + rule
+		}
+		if !fn(value.Interface(), param) {
+			return "failed validation rule " + rule
+		}
+	}
+	return ""
+}
+
+func checkBound(value reflect.Value, rule string, param string) string {
+	var actual, limit float64
+	var err error
+	unit := ""
+	switch value.Kind() {
+	case reflect.String:
+		actual = float64(len([]rune(value.String())))
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		actual = float64(value.Len())
+		unit = " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	default:
+		return "can not be checked with " + rule
+	}
+	if limit, err = strconv.ParseFloat(param, 64); err != nil {
+		return "has invalid parameter for " + rule
+	}
+
+	switch {
+	case rule == "min" && actual < limit:
+		return "must be at least " + param + unit
+	case rule == "max" && actual > limit:
+		return "must be at most " + param + unit
+	case rule == "len" && actual != limit:
+		return "must be exactly " + param + unit
+	}
+	return ""
+}
+
+// Body of the 400 response written by [Response.ValidationError].
+type ValidationErrorBody struct {
+	Error   string            `json:"error"`
+	Message string            `json:"message"`
+	Errors  []ValidationError `json:"errors"`
+}
+
+// Respond with 400 Bad Request and a [ValidationErrorBody]. A [ValidationErrors] is reported field by field,
+// any other error, such as a body that couldn't be decoded, as a single error for the field body.
+func (r *Response) ValidationError(err error) *Response {
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		errs = ValidationErrors{{Field: "body", Rule: "decode", Message: err.Error(), Code: "invalid_body"}}
+	}
+	return r.Status(http.StatusBadRequest).Json(ValidationErrorBody{
+		Error:   "validation_failed",
+		Message: "The request contains invalid input",
+		Errors:  errs,
+	})
+}
+
+// OpenAPI 3 schema of [ValidationErrorBody], for documenting the 400 responses of an API.
+func ValidationErrorSchema() map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"error", "message", "errors"},
+		"properties": map[string]any{
+			"error":   map[string]any{"type": "string", "example": "validation_failed"},
+			"message": map[string]any{"type": "string"},
+			"errors": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":     "object",
+					"required": []string{"field", "rule", "message", "code"},
+					"properties": map[string]any{
+						"field":   map[string]any{"type": "string", "example": "email"},
+						"rule":    map[string]any{"type": "string", "example": "email"},
+						"param":   map[string]any{"type": "string"},
+						"message": map[string]any{"type": "string", "example": "must be a valid email address"},
+						"code":    map[string]any{"type": "string", "example": "invalid_email"},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package gyr_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aigr20/gyr"
+)
+
+type signup struct {
+	Name    string   `json:"name" validate:"required,min=2,max=10"`
+	Email   string   `json:"email" validate:"required,email"`
+	Plan    string   `json:"plan" validate:"oneof=free pro"`
+	Tags    []string `validate:"max=2"`
+	Account struct {
+		ID string `json:"id" validate:"uuid"`
+	} `json:"account"`
+	Even int `json:"even" validate:"even"`
+}
+
+func TestValidate(t *testing.T) {
+	gyr.RegisterValidation("even", func(value any, param string) bool {
+		return value.(int)%2 == 0
+	})
+
+	valid := signup{Name: "Jo", Email: "jo@example.com", Plan: "pro", Even: 2}
+	valid.Account.ID = gyr.NewUUID().String()
+	if err := gyr.Validate(valid); err != nil {
+		t.Logf("Expected no error. Received %v\n", err)
+		t.FailNow()
+	}
+
+	invalid := signup{Name: "J", Plan: "enterprise", Tags: []string{"a", "b", "c"}, Even: 3}
+	invalid.Account.ID = "not-a-uuid"
+	var errs gyr.ValidationErrors
+	if !errors.As(gyr.Validate(&invalid), &errs) {
+		t.Log("Expected ValidationErrors")
+		t.FailNow()
+	}
+	expected := []string{"name:min", "email:required", "plan:oneof", "Tags:max", "account.id:uuid", "even:even"}
+	received := make([]string, len(errs))
+	for i, err := range errs {
+		received[i] = err.Field + ":" + err.Rule
+	}
+	if strings.Join(received, " ") != strings.Join(expected, " ") {
+		t.Logf("Expected %v. Received %v\n", expected, received)
+		t.FailNow()
+	}
+}
+
+func TestReadBodyValidates(t *testing.T) {
+	router := gyr.DefaultRouter()
+	router.Path("/signup").Post(func(ctx *gyr.Context) *gyr.Response {
+		if _, err := gyr.ReadBody[signup](ctx); err != nil {
+			return ctx.Response().ValidationError(err)
+		}
+		return ctx.Response().NoContent()
+	})
+
+	request, _ := http.NewRequest(http.MethodPost, "/signup", createPayload(map[string]string{"name": "Jo"}))
+	request.Header.Set("Content-Type", "application/json")
+	response := sendRequest(router, request)
+	body := `{"error":"validation_failed","message":"The request contains invalid input","errors":[{"field":"email","rule":"required","message":"is required","code":"required"}]}`
+	if response.Code != http.StatusBadRequest || response.Body.String() != body {
+		t.Logf("Received %d %s\n", response.Code, response.Body.String())
+		t.FailNow()
+	}
+
+	request, _ = http.NewRequest(http.MethodPost, "/signup", strings.NewReader("{"))
+	request.Header.Set("Content-Type", "application/json")
+	response = sendRequest(router, request)
+	if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), `"code":"invalid_body"`) {
+		t.Logf("Received %d %s\n", response.Code, response.Body.String())
+		t.FailNow()
+	}
+}
@@ -0,0 +1,212 @@
+package gyr
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type VersionSettings struct {
+	// When the version was deprecated. Deprecated versions send a Deprecation header with every response.
+	Deprecated   bool
+	DeprecatedAt time.Time
+	// When the version will be removed, sent in the Sunset header. SunsetLink can point to migration docs.
+	Sunset     time.Time
+	SunsetLink string
+	// Documentation of the deprecation, sent as a Link with rel="deprecation".
+	DeprecationLink string
+}
+
+// Mark the version as deprecated since at. A zero at sends Deprecation: true.
+func VersionDeprecated(at time.Time) func(*VersionSettings) {
+	return func(vs *VersionSettings) {
+		vs.Deprecated = true
+		vs.DeprecatedAt = at
+	}
+}
+
+func VersionSunset(at time.Time, link string) func(*VersionSettings) {
+	return func(vs *VersionSettings) {
+		vs.Sunset = at
+		vs.SunsetLink = link
+	}
+}
+
+// Create a group for the API version, served under /version, for example /v1/users for version v1.
+func (router *Router) Version(version string, settings ...SettingsFunc[VersionSettings]) *RouteGroup {
+	var versionSettings VersionSettings
+	for _, setting := range settings {
+		setting(&versionSettings)
+	}
+
+	group := router.Group(version)
+	if !slices.Contains(router.versions, group.Prefix) {
+		router.versions = append(router.versions, group.Prefix)
+	}
+	if versionSettings.Deprecated || !versionSettings.Sunset.IsZero() {
+		group.Middleware(deprecationHeaders(versionSettings))
+	}
+	return group
+}
+
+func deprecationHeaders(settings VersionSettings) Handler {
+	return func(ctx *Context) *Response {
+		header := ctx.writer.Header()
+		if settings.Deprecated {
+			if settings.DeprecatedAt.IsZero() {
+				header.Set("Deprecation", "true")
+			} else {
+				header.Set("Deprecation", "@"+strconv.FormatInt(settings.DeprecatedAt.Unix(), 10))
+			}
+			if settings.DeprecationLink != "" {
+				header.Add("Link", "<"+settings.DeprecationLink+`>; rel="deprecation"`)
+			}
+		}
+		if !settings.Sunset.IsZero() {
+			header.Set("Sunset", settings.Sunset.UTC().Format(http.TimeFormat))
+			if settings.SunsetLink != "" {
+				header.Add("Link", "<"+settings.SunsetLink+`>; rel="sunset"`)
+			}
+		}
+		return nil
+	}
+}
+
+// Mark every route of the group as deprecated since since and to be removed at sunset, with link pointing to
+// documentation of the deprecation. Zero times and an empty link are left out of the headers. Each use of a
+// route is logged with the number of uses so far, and the counts are available from
+// [RouteGroup.DeprecatedUsage].
+func (group *RouteGroup) Deprecated(since time.Time, sunset time.Time, link string) *RouteGroup {
+	headers := deprecationHeaders(VersionSettings{Deprecated: true, DeprecatedAt: since, Sunset: sunset, DeprecationLink: link})
+	group.usage = &deprecationUsage{counts: make(map[string]int64)}
+	return group.Middleware(func(ctx *Context) *Response {
+		endpoint := ctx.Request.Method + " " + ctx.Route().Path
+		ctx.Logger().Warn("Deprecated endpoint used", "group", group.Prefix, "endpoint", endpoint, "count", group.usage.add(endpoint))
+		return headers(ctx)
+	})
+}
+
+// Number of requests to each deprecated route of the group, by method and path, since the process started.
+func (group *RouteGroup) DeprecatedUsage() map[string]int64 {
+	if group.usage == nil {
+		return map[string]int64{}
+	}
+	group.usage.mx.Lock()
+	defer group.usage.mx.Unlock()
+	return maps.Clone(group.usage.counts)
+}
+
+type deprecationUsage struct {
+	mx     sync.Mutex
+	counts map[string]int64
+}
+
+func (usage *deprecationUsage) add(endpoint string) int64 {
+	usage.mx.Lock()
+	defer usage.mx.Unlock()
+	usage.counts[endpoint]++
+	return usage.counts[endpoint]
+}
+
+type VersionNegotiationSettings struct {
+	// Header naming the version, e.g. API-Version: v2.
+	Header string
+	// Also accept the version from the Accept header, as application/vnd.name.v2+json or a version parameter.
+	Accept bool
+	// Version used for unversioned paths when the request doesn't name one. Empty leaves those paths alone.
+	Default string
+}
+
+func DefaultVersionNegotiationSettings() VersionNegotiationSettings {
+	return VersionNegotiationSettings{
+		Header: "API-Version",
+		Accept: true,
+	}
+}
+
+func VersionHeader(name string) func(*VersionNegotiationSettings) {
+	return func(vns *VersionNegotiationSettings) {
+		vns.Header = name
+	}
+}
+
+func VersionDefault(version string) func(*VersionNegotiationSettings) {
+	return func(vns *VersionNegotiationSettings) {
+		vns.Default = version
+	}
+}
+
+// Route requests for unversioned paths to the version named by the request headers, so /users with
+// API-Version: v2 is handled by the /v2/users route of [Router.Version]. Paths that already start with a
+// version are left alone.
+func (router *Router) VersionNegotiation(settings ...SettingsFunc[VersionNegotiationSettings]) {
+	negotiationSettings := DefaultVersionNegotiationSettings()
+	for _, setting := range settings {
+		setting(&negotiationSettings)
+	}
+	router.versionNegotiation = &negotiationSettings
+}
+
+// Rewrite the path of req to the negotiated version, returning req unchanged if there is nothing to rewrite.
+func (router *Router) negotiateVersion(req *http.Request) *http.Request {
+	if router.versionNegotiation == nil {
+		return req
+	}
+	for _, prefix := range router.versions {
+		if req.URL.Path == prefix || strings.HasPrefix(req.URL.Path, prefix+"/") {
+			return req
+		}
+	}
+
+	version := router.requestedVersion(req)
+	if version == "" {
+		return req
+	}
+	prefix := "/" + strings.TrimPrefix(version, "/")
+	if !slices.Contains(router.versions, prefix) {
+		return req
+	}
+	versioned := req.Clone(req.Context())
+	versioned.URL.Path = prefix + req.URL.Path
+	versioned.URL.RawPath = ""
+	return versioned
+}
+
+func (router *Router) requestedVersion(req *http.Request) string {
+	settings := router.versionNegotiation
+	if version := req.Header.Get(settings.Header); settings.Header != "" && version != "" {
+		return version
+	}
+	if settings.Accept {
+		for _, mediaType := range strings.Split(req.Header.Get("Accept"), ",") {
+			if version := versionFromMediaType(strings.TrimSpace(mediaType)); version != "" {
+				return version
+			}
+		}
+	}
+	return settings.Default
+}
+
+// Find the version in application/vnd.name.v2+json or application/json; version=v2.
+func versionFromMediaType(mediaType string) string {
+	mimetype, params, _ := strings.Cut(mediaType, ";")
+	for _, param := range strings.Split(params, ";") {
+		if version, isVersion := strings.CutPrefix(strings.TrimSpace(param), "version="); isVersion {
+			return version
+		}
+	}
+	vendor, isVendor := strings.CutPrefix(mimetype, "application/vnd.")
+	if !isVendor {
+		return ""
+	}
+	vendor, _, _ = strings.Cut(vendor, "+")
+	parts := strings.Split(vendor, ".")
+	if last := parts[len(parts)-1]; len(parts) > 1 && strings.HasPrefix(last, "v") {
+		return last
+	}
+	return ""
+}
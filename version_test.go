@@ -0,0 +1,95 @@
+package gyr_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+func versionedRouter() *gyr.Router {
+	router := gyr.DefaultRouter()
+	sunset := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	v1 := router.Version("v1", gyr.VersionDeprecated(time.Unix(1700000000, 0)), gyr.VersionSunset(sunset, "https://example.com/migrate"))
+	v1.Path("/users/:id").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text("v1 " + ctx.Variable("id").(string))
+	})
+	v2 := router.Version("v2")
+	v2.Path("/users/:id").Get(func(ctx *gyr.Context) *gyr.Response {
+		return ctx.Response().Text("v2 " + ctx.Variable("id").(string))
+	})
+	return router
+}
+
+func TestVersionGroups(t *testing.T) {
+	router := versionedRouter()
+	request, _ := http.NewRequest(http.MethodGet, "/v1/users/kalle", nil)
+	response := sendRequest(router, request)
+	if response.Body.String() != "v1 kalle" {
+		t.Logf("Expected v1 kalle. Received %s\n", response.Body.String())
+		t.FailNow()
+	}
+
+	headers := response.Result().Header
+	if headers.Get("Deprecation") != "@1700000000" || headers.Get("Sunset") != "Tue, 01 Jan 2030 00:00:00 GMT" || headers.Get("Link") != `<https://example.com/migrate>; rel="sunset"` {
+		t.Logf("Unexpected deprecation headers %v\n", headers)
+		t.FailNow()
+	}
+
+	request, _ = http.NewRequest(http.MethodGet, "/v2/users/kalle", nil)
+	response = sendRequest(router, request)
+	if response.Body.String() != "v2 kalle" || response.Result().Header.Get("Deprecation") != "" {
+		t.Logf("Expected undeprecated v2. Received %s %v\n", response.Body.String(), response.Result().Header)
+		t.FailNow()
+	}
+}
+
+func TestVersionNegotiation(t *testing.T) {
+	router := versionedRouter()
+	router.VersionNegotiation(gyr.VersionDefault("v2"))
+
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		expected string
+	}{
+		{"Default", "", "", "v2 kalle"},
+		{"Header", "API-Version", "v1", "v1 kalle"},
+		{"Accept vendor", "Accept", "application/vnd.gyr.v1+json", "v1 kalle"},
+		{"Accept parameter", "Accept", "application/json; version=v1", "v1 kalle"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodGet, "/users/kalle", nil)
+			if test.header != "" {
+				request.Header.Set(test.header, test.value)
+			}
+			if received := sendRequest(router, request).Body.String(); received != test.expected {
+				t.Logf("Expected %s. Received %s\n", test.expected, received)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestGroupDeprecated(t *testing.T) {
+	router := defaultTestRouter()
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	legacy := router.Group("/legacy").Deprecated(since, sunset, "https://example.com/migrate")
+	legacy.Path("/users").Get(func(ctx *gyr.Context) *gyr.Response { return ctx.Response().Text("ok") })
+
+	for range 3 {
+		gyrtest.Get("/legacy/users").Send(router).
+			AssertHeader(t, "Deprecation", "@1704067200").
+			AssertHeader(t, "Sunset", "Wed, 01 Jan 2025 00:00:00 GMT").
+			AssertHeader(t, "Link", `<https://example.com/migrate>; rel="deprecation"`)
+	}
+	if usage := legacy.DeprecatedUsage(); usage["GET /users"] != 3 {
+		t.Logf("Expected 3 uses of GET /users. Received %v\n", usage)
+		t.FailNow()
+	}
+}
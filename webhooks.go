@@ -0,0 +1,253 @@
+package gyr
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Header with the signature of a webhook delivery, formatted as t=<unix time>,v1=<hex HMAC-SHA256>.
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	// Header with the id of a delivery, the same for every attempt so receivers can ignore duplicates.
+	WebhookIDHeader = "X-Webhook-ID"
+)
+
+// How old a webhook signature may be before [VerifyWebhook] rejects it as a replay.
+var WebhookTolerance = 5 * time.Minute
+
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// A receiver of webhooks.
+type WebhookEndpoint struct {
+	ID     string
+	URL    string
+	Secret []byte
+	// Events sent to the endpoint. Empty means every event.
+	Events []string
+}
+
+// A delivery that failed every attempt, kept by a [WebhookDeadLetterStore] for inspection or redelivery.
+type WebhookDeadLetter struct {
+	ID         UUID
+	EndpointID string
+	URL        string
+	Event      string
+	Payload    []byte
+	Attempts   int
+	LastError  string
+	FailedAt   time.Time
+}
+
+// Records deliveries that failed every attempt.
+type WebhookDeadLetterStore interface {
+	Record(ctx context.Context, letter WebhookDeadLetter) error
+}
+
+type WebhookSettings struct {
+	// Client sending the deliveries. Its own retries are not used, deliveries are retried with Retry.
+	Client *Client
+	Retry  RetryPolicy
+	// Where failed deliveries are recorded. Nil only logs them.
+	DeadLetters WebhookDeadLetterStore
+}
+
+func DefaultWebhookSettings() WebhookSettings {
+	return WebhookSettings{
+		Client: NewClient(ClientTimeout(10 * time.Second)),
+		Retry: RetryPolicy{
+			MaxAttempts: 5,
+			Backoff:     time.Second,
+			MaxBackoff:  time.Minute,
+			Jitter:      0.2,
+		},
+	}
+}
+
+func WebhookClient(client *Client) func(*WebhookSettings) {
+	return func(ws *WebhookSettings) {
+		ws.Client = client
+	}
+}
+
+func WebhookRetries(policy RetryPolicy) func(*WebhookSettings) {
+	return func(ws *WebhookSettings) {
+		ws.Retry = policy
+	}
+}
+
+// Record deliveries that failed every attempt in store, for example a [SQLWebhookDeadLetterStore].
+func WebhookDeadLetters(store WebhookDeadLetterStore) func(*WebhookSettings) {
+	return func(ws *WebhookSettings) {
+		ws.DeadLetters = store
+	}
+}
+
+// Sends signed events to registered endpoints.
+type Webhooks struct {
+	Settings  WebhookSettings
+	mx        sync.RWMutex
+	endpoints []WebhookEndpoint
+}
+
+func NewWebhooks(settings ...SettingsFunc[WebhookSettings]) *Webhooks {
+	webhookSettings := DefaultWebhookSettings()
+	for _, setting := range settings {
+		setting(&webhookSettings)
+	}
+	return &Webhooks{Settings: webhookSettings}
+}
+
+// Add endpoint, replacing an endpoint with the same ID.
+func (webhooks *Webhooks) Register(endpoint WebhookEndpoint) {
+	webhooks.mx.Lock()
+	defer webhooks.mx.Unlock()
+	webhooks.endpoints = slices.DeleteFunc(webhooks.endpoints, func(registered WebhookEndpoint) bool {
+		return registered.ID == endpoint.ID
+	})
+	webhooks.endpoints = append(webhooks.endpoints, endpoint)
+}
+
+func (webhooks *Webhooks) Unregister(id string) {
+	webhooks.mx.Lock()
+	defer webhooks.mx.Unlock()
+	webhooks.endpoints = slices.DeleteFunc(webhooks.endpoints, func(registered WebhookEndpoint) bool {
+		return registered.ID == id
+	})
+}
+
+// Deliver payload encoded as JSON to every endpoint subscribed to event, retrying failed deliveries. Blocks
+// until every delivery has succeeded or been recorded as a dead letter, so call it from [Context.Defer] or a
+// job rather than in a handler. Returns the errors of the failed deliveries.
+func (webhooks *Webhooks) Send(ctx context.Context, event string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	webhooks.mx.RLock()
+	endpoints := slices.Clone(webhooks.endpoints)
+	webhooks.mx.RUnlock()
+
+	var errs []error
+	for _, endpoint := range endpoints {
+		if len(endpoint.Events) > 0 && !slices.Contains(endpoint.Events, event) {
+			continue
+		}
+		if err := webhooks.deliver(ctx, endpoint, event, body); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", endpoint.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (webhooks *Webhooks) deliver(ctx context.Context, endpoint WebhookEndpoint, event string, body []byte) error {
+	id := NewUUID()
+	attempts := 0
+	err := Retry(ctx, webhooks.Settings.Retry, func() error {
+		attempts++
+		header := make(http.Header)
+		header.Set("Content-Type", "application/json")
+		header.Set(WebhookEventHeader, event)
+		header.Set(WebhookIDHeader, id.String())
+		header.Set(WebhookSignatureHeader, SignWebhook(endpoint.Secret, time.Now(), body))
+		response, err := webhooks.Settings.Client.Do(ctx, http.MethodPost, endpoint.URL, body, header)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		io.Copy(io.Discard, io.LimitReader(response.Body, 4096))
+		if response.StatusCode >= 200 && response.StatusCode <= 299 {
+			return nil
+		}
+		err = fmt.Errorf("received status %d", response.StatusCode)
+		if response.StatusCode < 500 && response.StatusCode != http.StatusRequestTimeout && response.StatusCode != http.StatusTooManyRequests {
+			return Permanent(err)
+		}
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+
+	LoggerFrom(ctx).Error("Webhook delivery failed", "endpoint", endpoint.ID, "event", event, "attempts", attempts, "error", err)
+	if webhooks.Settings.DeadLetters != nil {
+		letter := WebhookDeadLetter{ID: id, EndpointID: endpoint.ID, URL: endpoint.URL, Event: event, Payload: body, Attempts: attempts, LastError: err.Error(), FailedAt: time.Now()}
+		if recordErr := webhooks.Settings.DeadLetters.Record(context.WithoutCancel(ctx), letter); recordErr != nil {
+			return errors.Join(err, recordErr)
+		}
+	}
+	return err
+}
+
+// The value of the [WebhookSignatureHeader] for body sent at timestamp.
+func SignWebhook(secret []byte, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + webhookMAC(secret, unix, body)
+}
+
+func webhookMAC(secret []byte, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unix + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Check that signature, as sent in the [WebhookSignatureHeader], was made for body with secret within
+// [WebhookTolerance] of now.
+func VerifyWebhookSignature(secret []byte, signature string, body []byte, now time.Time) error {
+	var unix string
+	var signatures []string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	timestamp, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing timestamp", ErrInvalidWebhookSignature)
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > WebhookTolerance || age < -WebhookTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidWebhookSignature)
+	}
+	expected := webhookMAC(secret, unix, body)
+	for _, candidate := range signatures {
+		if hmac.Equal([]byte(candidate), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidWebhookSignature
+}
+
+// Reject requests without a valid [WebhookSignatureHeader] for secret with 401 Unauthorized, for routes
+// receiving webhooks sent by [Webhooks] or a service signing the same way.
+func VerifyWebhook(secret []byte) Handler {
+	return func(ctx *Context) *Response {
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			return ctx.Response().Status(http.StatusBadRequest).Text("400 - Bad Request")
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if err := VerifyWebhookSignature(secret, ctx.Request.Header.Get(WebhookSignatureHeader), body, time.Now()); err != nil {
+			ctx.Logger().Warn("Rejected webhook", "error", err)
+			return ctx.Response().Status(http.StatusUnauthorized).Text("401 - Unauthorized")
+		}
+		return nil
+	}
+}
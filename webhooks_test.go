@@ -0,0 +1,84 @@
+package gyr_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aigr20/gyr"
+	"github.com/aigr20/gyr/gyrtest"
+)
+
+type deadLetters struct {
+	letters []gyr.WebhookDeadLetter
+}
+
+func (store *deadLetters) Record(ctx context.Context, letter gyr.WebhookDeadLetter) error {
+	store.letters = append(store.letters, letter)
+	return nil
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	secret := []byte("secret")
+	received := make(chan string, 1)
+	receiver := defaultTestRouter()
+	receiver.Path("/hooks").Middleware(gyr.VerifyWebhook(secret)).Post(func(ctx *gyr.Context) *gyr.Response {
+		body, _ := gyr.ReadBody[map[string]string](ctx)
+		received <- ctx.Request.Header.Get(gyr.WebhookEventHeader) + " " + body["id"]
+		return ctx.Response().NoContent()
+	})
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhooks := gyr.NewWebhooks()
+	webhooks.Register(gyr.WebhookEndpoint{ID: "orders", URL: server.URL + "/hooks", Secret: secret, Events: []string{"order.created"}})
+	webhooks.Register(gyr.WebhookEndpoint{ID: "other", URL: server.URL + "/missing", Secret: secret, Events: []string{"user.created"}})
+	if err := webhooks.Send(context.Background(), "order.created", map[string]string{"id": "42"}); err != nil {
+		t.Logf("Send failed: %v\n", err)
+		t.FailNow()
+	}
+	if event := <-received; event != "order.created 42" {
+		t.Logf("Expected order.created 42. Received %s\n", event)
+		t.FailNow()
+	}
+
+	gyrtest.Post("/hooks").JSON(map[string]string{"id": "42"}).
+		Header(gyr.WebhookSignatureHeader, gyr.SignWebhook([]byte("wrong"), time.Now(), []byte(`{"id":"42"}`))).
+		Send(receiver).AssertStatus(t, http.StatusUnauthorized)
+	gyrtest.Post("/hooks").JSON(map[string]string{"id": "42"}).
+		Header(gyr.WebhookSignatureHeader, gyr.SignWebhook(secret, time.Now().Add(-time.Hour), []byte(`{"id":"42"}`))).
+		Send(receiver).AssertStatus(t, http.StatusUnauthorized)
+}
+
+func TestWebhookDeadLetter(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	store := &deadLetters{}
+	webhooks := gyr.NewWebhooks(
+		gyr.WebhookRetries(gyr.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}),
+		gyr.WebhookDeadLetters(store),
+	)
+	webhooks.Register(gyr.WebhookEndpoint{ID: "down", URL: server.URL, Secret: []byte("secret")})
+	err := webhooks.Send(context.Background(), "order.created", map[string]string{"id": "42"})
+	if err == nil || attempts.Load() != 3 {
+		t.Logf("Expected 3 failed attempts. Received %d attempts and %v\n", attempts.Load(), err)
+		t.FailNow()
+	}
+	if len(store.letters) != 1 || store.letters[0].EndpointID != "down" || store.letters[0].Attempts != 3 {
+		t.Logf("Expected a dead letter for the endpoint. Received %+v\n", store.letters)
+		t.FailNow()
+	}
+	if err := gyr.VerifyWebhookSignature([]byte("secret"), "v1=abc", nil, time.Now()); !errors.Is(err, gyr.ErrInvalidWebhookSignature) {
+		t.Logf("Expected ErrInvalidWebhookSignature. Received %v\n", err)
+		t.FailNow()
+	}
+}